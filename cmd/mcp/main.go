@@ -5,65 +5,332 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	nethttp "net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/li-clement/Nemesis/internal/scanner"
+	"github.com/li-clement/Nemesis/internal/version"
 )
 
 func main() {
 	// Parse command line arguments
-	zipFile := flag.String("zip", "", "Path to the zip file to analyze")
-	outputFile := flag.String("output", "copyright_analysis.txt", "Path to the output file")
-	endpoint := flag.String("endpoint", "", "MCP endpoint URL")
-	apiKey := flag.String("api-key", "", "MCP API key")
+	showVersion := flag.Bool("version", false, "Print the version, commit and build date and exit")
+	archiveURL := flag.String("url", "", "HTTP(S) URL of an archive to download and analyze instead of -zip")
+	var headers stringList
+	flag.Var(&headers, "header", "HTTP header sent with the -url download, as 'Name: value' (repeatable)")
+	maxDownload := flag.Int64("max-download-bytes", scanner.DefaultMaxDownloadBytes, "Maximum size of the archive downloaded from -url or read from stdin with -zip -")
+	downloadTimeout := flag.Duration("download-timeout", scanner.DefaultDownloadTimeout, "Time limit of the -url download")
+	zipFile := flag.String("zip", "", "Path to the archive (.zip, .tar, .tar.gz, .tgz) to analyze, a directory of archives, or '-' to read the archive from stdin")
+	outputFile := flag.String("output", "copyright_analysis.txt", "Path to the output file ({name} is replaced with the archive name for directories)")
+	provider := flag.String("provider", "mcp", "Analysis backend: mcp, openai (any OpenAI-compatible API) or ollama")
+	endpoint := flag.String("endpoint", "", "MCP endpoint URL, or the API base URL of the openai and ollama providers")
+	apiKey := flag.String("api-key", "", "MCP or API key (not needed for ollama)")
 	model := flag.String("model", "gpt-4", "Model to use for analysis")
+	compress := flag.Bool("gzip", false, "Write gzip-compressed output files (implied by a .gz output name)")
+	parallelArchives := flag.Int("parallel-archives", 1, "Number of archives analyzed concurrently when -zip is a directory")
+	maxBytes := flag.Int64("max-uncompressed-bytes", 0, "Maximum total size of the files extracted from an archive (0 for 2 GiB)")
+	maxFileBytes := flag.Int64("max-file-bytes", 0, "Maximum size of a single file extracted from an archive (0 for 1 GiB)")
+	maxEntries := flag.Int("max-entries", 0, "Maximum number of entries in an archive (0 for 100000)")
+	maxDepth := flag.Int("max-archive-depth", 0, "Number of nested archive levels to extract (0 for 1, archives inside the archive are not unpacked)")
+	maxChunkChars := flag.Int("max-chunk-chars", scanner.DefaultMaxChunkChars, "Size of the copyright text analyzed in one request, larger texts are analyzed in chunks")
+	timeout := flag.Duration("timeout", scanner.DefaultMCPTimeout, "Time limit of each MCP request")
+	maxRetries := flag.Int("max-retries", 0, "Number of times an MCP call failing with a network error, 429 or 5xx is retried")
+	retryDelay := flag.Duration("retry-delay", scanner.DefaultRetryBaseDelay, "Backoff before the first retry, doubled for each further one")
+	cacheDir := flag.String("cache-dir", defaultCacheDir(), "Directory caching analyses of identical copyright information")
+	structured := flag.Bool("structured", false, "Ask the model for a JSON analysis of holders, years, conflicts and recommendations, shown parsed in the report")
+	scanOnly := flag.Bool("scan-only", false, "Write the scanned copyright information without analyzing it, no endpoint or API key needed")
+	summaryJSON := flag.String("summary-json", "", "Also write the holders, years and statement counts computed from the scan to this JSON file")
+	noCache := flag.Bool("no-cache", false, "Always call the model instead of reusing cached analyses")
+	verbose := flag.Bool("v", false, "Log debug messages, such as the reuse of cached analyses")
 	flag.Parse()
 
-	if *zipFile == "" {
-		fmt.Println("Error: zip file path is required")
-		flag.Usage()
-		os.Exit(1)
+	if *showVersion {
+		fmt.Println(version.Get().Describe("mcp"))
+		return
 	}
 
-	if *endpoint == "" {
-		fmt.Println("Error: MCP endpoint is required")
+	if (*zipFile == "") == (*archiveURL == "") {
+		fmt.Println("Error: either an archive path or an archive URL is required")
 		flag.Usage()
 		os.Exit(1)
 	}
+	downloadHeaders := make(nethttp.Header)
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			fmt.Printf("Error: invalid header %q, expected 'Name: value'\n", header)
+			os.Exit(1)
+		}
+		downloadHeaders.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
 
-	if *apiKey == "" {
-		fmt.Println("Error: MCP API key is required")
-		flag.Usage()
+	var analyzer scanner.Analyzer
+	switch {
+	case *scanOnly:
+		// Nothing is analyzed, so no provider needs to be configured
+	case *provider == "mcp":
+		if *endpoint == "" {
+			fmt.Println("Error: MCP endpoint is required")
+			flag.Usage()
+			os.Exit(1)
+		}
+		if *apiKey == "" {
+			fmt.Println("Error: MCP API key is required")
+			flag.Usage()
+			os.Exit(1)
+		}
+	case *provider == "openai":
+		if *apiKey == "" {
+			fmt.Println("Error: API key is required for the openai provider")
+			flag.Usage()
+			os.Exit(1)
+		}
+		analyzer = scanner.NewOpenAIAnalyzer(scanner.OpenAIConfig{Endpoint: *endpoint, APIKey: *apiKey, Model: *model, Timeout: *timeout})
+	case *provider == "ollama":
+		if *endpoint == "" {
+			*endpoint = scanner.DefaultOllamaEndpoint
+		}
+		analyzer = scanner.NewOpenAIAnalyzer(scanner.OpenAIConfig{Endpoint: *endpoint, APIKey: *apiKey, Model: *model, Timeout: *timeout})
+	default:
+		fmt.Printf("Error: unknown provider %q, expected mcp, openai or ollama\n", *provider)
 		os.Exit(1)
 	}
 
+	if *noCache {
+		*cacheDir = ""
+	}
+
 	// Create scanner and MCP service
 	s := scanner.NewScanner()
 	mcpService, err := scanner.NewMCPService(s, scanner.MCPConfig{
 		Model:    *model,
 		Endpoint: *endpoint,
 		APIKey:   *apiKey,
+		Analyzer: analyzer,
+		Limits: scanner.ArchiveLimits{
+			MaxUncompressedBytes: *maxBytes,
+			MaxFileBytes:         *maxFileBytes,
+			MaxEntries:           *maxEntries,
+			MaxDepth:             *maxDepth,
+		},
+		Timeout:            *timeout,
+		MaxChunkChars:      *maxChunkChars,
+		MaxRetries:         *maxRetries,
+		RetryBaseDelay:     *retryDelay,
+		CacheDir:           *cacheDir,
+		Logger:             newLogger(*verbose),
+		ScanOnly:           *scanOnly,
+		StructuredAnalysis: *structured,
 	})
 	if err != nil {
 		fmt.Printf("Error creating MCP service: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Analyze the zip file
-	result, err := mcpService.AnalyzeZipFile(context.Background(), *zipFile)
-	if err != nil {
-		fmt.Printf("Error analyzing zip file: %v\n", err)
-		os.Exit(1)
+	// A remote archive is downloaded to a temp file first
+	if *archiveURL != "" {
+		zipPath, err := scanner.DownloadArchive(context.Background(), *archiveURL, scanner.DownloadOptions{
+			Headers:  downloadHeaders,
+			MaxBytes: *maxDownload,
+			Timeout:  *downloadTimeout,
+		})
+		if err != nil {
+			fmt.Printf("Error downloading archive: %v\n", err)
+			os.Exit(1)
+		}
+		err = analyzeArchive(mcpService, zipPath, *outputFile, *summaryJSON, *compress)
+		os.Remove(zipPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Write result to file
-	if err := os.WriteFile(*outputFile, []byte(result), 0644); err != nil {
-		fmt.Printf("Error writing output file: %v\n", err)
+	// An archive streamed to stdin is buffered in memory, never written to disk
+	if *zipFile == "-" {
+		if *summaryJSON != "" {
+			fmt.Println("Error: -summary-json is not supported for an archive read from stdin")
+			os.Exit(1)
+		}
+		if err := analyzeStdin(mcpService, *outputFile, *maxDownload, *compress); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// A directory is analyzed archive by archive
+	if info, err := os.Stat(*zipFile); err == nil && info.IsDir() {
+		if *summaryJSON != "" {
+			fmt.Println("Error: -summary-json is only supported for a single archive")
+			os.Exit(1)
+		}
+		if err := analyzeDirectory(mcpService, *zipFile, *outputFile, *parallelArchives, *compress); err != nil {
+			fmt.Printf("Error analyzing archives: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := analyzeArchive(mcpService, *zipFile, *outputFile, *summaryJSON, *compress); err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
+}
+
+// analyzeArchive analyzes a single archive and writes the result to
+// outputFile and, if summaryFile is set, the computed summary as JSON
+func analyzeArchive(mcpService *scanner.MCPService, zipPath, outputFile, summaryFile string, compress bool) error {
+	// Analyze the zip file, summarizing the same scan
+	result, summary, err := mcpService.AnalyzeZipFileSummary(context.Background(), zipPath)
+	if err != nil {
+		return fmt.Errorf("Error analyzing archive: %v", err)
+	}
+
+	if err := writeAnalysis(outputFile, result, compress); err != nil {
+		return err
+	}
+
+	if summaryFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error encoding summary: %v", err)
+	}
+	written, err := scanner.WriteOutputFile(summaryFile, append(data, '\n'), false)
+	if err != nil {
+		return fmt.Errorf("Error writing summary file: %v", err)
+	}
+	fmt.Printf("Summary saved to: %s\n", written)
+	return nil
+}
+
+// analyzeStdin analyzes the archive piped to stdin, read into memory up to
+// maxBytes, and writes the result to outputFile
+func analyzeStdin(mcpService *scanner.MCPService, outputFile string, maxBytes int64, compress bool) error {
+	if maxBytes <= 0 {
+		maxBytes = scanner.DefaultMaxDownloadBytes
+	}
+
+	// Read one byte past the limit to tell a complete archive from a truncated one
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxBytes+1))
+	if err != nil {
+		return fmt.Errorf("Error reading archive from stdin: %v", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return fmt.Errorf("Error reading archive from stdin: size exceeds the limit of %d bytes", maxBytes)
+	}
 
-	fmt.Printf("Analysis complete. Results saved to: %s\n", *outputFile)
+	result, err := mcpService.AnalyzeZipReader(context.Background(), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("Error analyzing archive: %v", err)
+	}
+	return writeAnalysis(outputFile, result, compress)
+}
+
+// writeAnalysis writes the analysis of a single archive to outputFile
+func writeAnalysis(outputFile, result string, compress bool) error {
+	written, err := scanner.WriteOutputFile(outputFile, []byte(result), compress)
+	if err != nil {
+		return fmt.Errorf("Error writing output file: %v", err)
+	}
+	fmt.Printf("Analysis complete. Results saved to: %s\n", written)
+	return nil
+}
+
+// stringList is a flag that can be given several times
+type stringList []string
+
+// String returns the values joined by commas
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set appends a value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// analyzeDirectory analyzes every supported archive in dir, writing one output
+// file per archive. Failing archives are reported without stopping the others
+func analyzeDirectory(mcpService *scanner.MCPService, dir, outputPattern string, parallel int, compress bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	var zipPaths []string
+	for _, entry := range entries {
+		if _, ok := scanner.ArchiveName(entry.Name()); ok && !entry.IsDir() {
+			zipPaths = append(zipPaths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(zipPaths) == 0 {
+		return fmt.Errorf("no archives found in %s", dir)
+	}
+	sort.Strings(zipPaths)
+
+	failed := 0
+	for _, result := range mcpService.AnalyzeZipFiles(context.Background(), zipPaths, parallel) {
+		if result.Err != nil {
+			fmt.Printf("Error analyzing %s: %v\n", result.Path, result.Err)
+			failed++
+			continue
+		}
+
+		name, _ := scanner.ArchiveName(result.Path)
+		outputFile, err := scanner.WriteOutputFile(scanner.OutputFileName(outputPattern, name), []byte(result.Analysis), compress)
+		if err != nil {
+			fmt.Printf("Error writing output file: %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("Analysis of %s complete. Results saved to: %s\n", result.Path, outputFile)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d archives failed", failed, len(zipPaths))
+	}
+	return nil
+}
+
+// defaultCacheDir returns the analysis cache under the user's cache
+// directory, or "" if there is none
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "nemesis", "analyses")
+}
+
+// newLogger returns the logger of the scanner messages, writing Info and
+// higher records, or Debug ones too if verbose, to stderr without timestamps
+func newLogger(verbose bool) *slog.Logger {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
 }
@@ -5,30 +5,861 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/li-clement/Nemesis/internal/scanner"
+	"github.com/li-clement/Nemesis/internal/version"
+)
+
+// Exit codes of the scanner, for scripts and CI jobs to rely on
+const (
+	// exitOK is returned when the scan succeeded
+	exitOK = 0
+	// exitError is returned when the scan failed, the arguments are wrong or
+	// -check-compat found incompatible licenses
+	exitError = 1
+	// exitUsage is returned by the flag package for unknown or invalid flags
+	exitUsage = 2
+	// exitNoCopyrights is returned with -fail-on-empty when no copyright
+	// statement was found
+	exitNoCopyrights = 3
+	// exitDeniedHolder is returned when a holder given with -deny was found
+	exitDeniedHolder = 4
+	// exitPolicyViolation is returned when the scanned files break the
+	// policy given with -policy
+	exitPolicyViolation = 5
 )
 
 func main() {
+	// Parse command line arguments
+	showVersion := flag.Bool("version", false, "Print the version, commit and build date and exit")
+	filesFrom := flag.String("files-from", "", "Read newline-separated file paths to scan from this file ('-' for stdin)")
+	anonymize := flag.Bool("anonymize", false, "Replace holders that look like individuals with a placeholder")
+	splitHolders := flag.Bool("split-holders", false, "Report each holder of a statement such as 'Copyright 2024 Alice, Bob and Carol' as its own structured entry")
+	hashNames := flag.Bool("hash-names", false, "Append a keyed hash of the name to anonymized holders")
+	hashKey := flag.String("hash-key", "", "Key for -hash-names so hashes stay comparable across runs (random per run if empty)")
+	validateYears := flag.Bool("validate-years", false, "List statements with future or pre-1970 years in a warnings section")
+	missingYears := flag.Bool("missing-years", false, "List copyright statements without any year in a warnings section")
+	mergeYears := flag.Bool("merge-years", false, "Merge the years of statements differing only in their years into ranges")
+	sortOutput := flag.Bool("sort", false, "List the copyrights of text reports sorted case-insensitively instead of in file order")
+	groupByYear := flag.Bool("group-by-year", false, "List copyrights in one section per year, under the earliest year of each holder")
+	checkCompat := flag.Bool("check-compat", false, "Report license compatibility and fail if a scanned project combines incompatible licenses")
+	scanImages := flag.Bool("images", false, "Read copyright notices from image metadata (EXIF, PNG text, XMP)")
+	preserveOriginal := flag.Bool("preserve-original", false, "Report statements as written in the source, only without their comment markers")
+	minConfidence := flag.Float64("min-confidence", 0, "Drop copyright statements scoring below this confidence, from 0 to 1")
+	requireRights := flag.Bool("require-rights", false, "Only accept copyright statements with a rights phrase, a year or a legal suffix")
+	compress := flag.Bool("gzip", false, "Write gzip-compressed output files (implied by a .gz output name)")
+	lineEnding := flag.String("line-ending", scanner.LineEndingLF, "Line ending of the output files: lf or crlf")
+	writeBOM := flag.Bool("bom", false, "Start the output files with a UTF-8 byte order mark")
+	inlineLicenses := flag.Bool("inline-licenses", false, "List the license texts found in comment blocks of source files")
+	mergeLicenseBlocks := flag.Bool("merge-license-blocks", false, "Report identical inline license blocks of one file once, with a count")
+	holderMap := flag.String("holder-map", "", "Map holder name variants to canonical names from a .csv (variant,canonical) or JSON file")
+	extensions := flag.String("ext", "", "Comma-separated file extensions (e.g. .go,.c,.h) to limit scanning to, all text files when empty")
+	thirdParty := flag.String("third-party", "", "Comma-separated directories (e.g. vendor,third_party) whose copyrights are reported in a separate third-party section")
+	postHook := flag.String("post-hook", "", "Command run after each output file is written, with the output path and project name as arguments")
+	postHookTimeout := flag.Duration("post-hook-timeout", time.Minute, "Maximum run time of the post hook per output file")
+	postHookAbort := flag.Bool("post-hook-abort", false, "Fail the run when the post hook fails instead of only reporting it")
+	showProgress := flag.Bool("progress", false, "Print the number of files scanned so far to stderr")
+	parallelDirs := flag.Int("parallel-dirs", 1, "Number of subdirectories scanned concurrently")
+	respectGitignore := flag.Bool("gitignore", false, "Skip paths ignored by .gitignore files and the .git directory")
+	strictErrors := flag.Bool("strict-errors", false, "Abort at the first unreadable file or directory instead of skipping and reporting it")
+	followSymlinks := flag.Bool("follow-symlinks", false, "Traverse symlinked directories, entering each directory once")
+	concurrency := flag.Int("concurrency", 0, "Number of files scanned concurrently within a directory (0 for one per CPU)")
+	maxScanBytes := flag.Int64("max-scan-bytes", scanner.DefaultMaxScanBytes, "Number of bytes read from each file (-1 for no limit)")
+	headerLines := flag.Int("header-lines", 0, "Only read the first N lines of each file (0 for the whole file)")
+	skipLarger := flag.Int64("skip-larger-than", 0, "Skip files larger than this many bytes when scanning a directory (0 for no limit)")
+	defaultEncoding := flag.String("encoding", scanner.DefaultEncoding, "Encoding of files that are neither UTF-8 nor start with a byte order mark: windows-1252, iso-8859-1 or iso-8859-15")
+	format := flag.String("format", scanner.FormatText, "Output format: text, json, spdx or cyclonedx")
+	compact := flag.Bool("compact", false, "Write JSON output without indentation")
+	perFile := flag.Bool("per-file", false, "Add a files object to JSON output, listing the copyrights found in each file")
+	var excludePatterns, excludeDirs stringList
+	flag.Var(&excludePatterns, "exclude", "Skip paths matching this glob pattern, relative to the scanned directory (repeatable)")
+	flag.Var(&excludeDirs, "exclude-dir", "Skip directories with this name at any depth (repeatable)")
+	template := flag.String("template", "", "Prefix template written before each text report (default template/prefix.txt)")
+	noTemplate := flag.Bool("no-template", false, "Write text reports without a prefix template")
+	singleTree := flag.Bool("single", false, "Scan the whole directory tree as one project into a single output file instead of one file per subdirectory")
+	manifest := flag.String("manifest", "", "Write every scanned file with all its copyright statements to this file (.csv for CSV, JSON otherwise)")
+	verbose := flag.Bool("v", false, "Log debug messages, such as each subdirectory as it is scanned")
+	failOnEmpty := flag.Bool("fail-on-empty", false, fmt.Sprintf("Exit with code %d if no copyright statement was found", exitNoCopyrights))
+	policyFile := flag.String("policy", "", fmt.Sprintf("Exit with code %d if the scanned files break the allowed and denied holders and licenses of this .json or .yaml policy", exitPolicyViolation))
+	var deniedHolders stringList
+	flag.Var(&deniedHolders, "deny", fmt.Sprintf("Exit with code %d if a copyright statement names this holder (repeatable)", exitDeniedHolder))
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version.Get().Describe("scanner"))
+		return
+	}
+
+	if *format != scanner.FormatText && !scanner.IsStructuredFormat(*format) {
+		fmt.Printf("Error: unknown output format %q, expected text, json, spdx or cyclonedx\n", *format)
+		os.Exit(exitError)
+	}
+
+	if *lineEnding != scanner.LineEndingLF && *lineEnding != scanner.LineEndingCRLF {
+		fmt.Printf("Error: unknown line ending %q, expected lf or crlf\n", *lineEnding)
+		os.Exit(exitError)
+	}
+
+	if !scanner.IsSupportedEncoding(*defaultEncoding) {
+		fmt.Printf("Error: unknown encoding %q, expected windows-1252, iso-8859-1 or iso-8859-15\n", *defaultEncoding)
+		os.Exit(exitError)
+	}
+
+	// Create scanner with the requested options
+	s := scanner.NewScanner()
+	s.AnonymizePersonalNames = *anonymize || *hashNames || *hashKey != ""
+	s.HashPersonalNames = *hashNames || *hashKey != ""
+	s.PseudonymKey = []byte(*hashKey)
+	s.SplitHolders = *splitHolders
+	s.ValidateYears = *validateYears
+	s.FlagMissingYears = *missingYears
+	s.MergeYears = *mergeYears
+	s.GroupByYear = *groupByYear
+	s.SortOutput = *sortOutput
+	s.CheckCompatibility = *checkCompat
+	s.ScanImageMetadata = *scanImages
+	s.RequireRightsPhrase = *requireRights
+	s.MinConfidence = *minConfidence
+	s.PreserveOriginal = *preserveOriginal
+	s.CompressOutput = *compress
+	s.OutputLineEnding = *lineEnding
+	s.OutputBOM = *writeBOM
+	s.ParallelSubDirectories = *parallelDirs
+	s.Concurrency = *concurrency
+	s.RespectGitignore = *respectGitignore
+	s.FollowSymlinks = *followSymlinks
+	s.StrictErrors = *strictErrors
+	s.Logger = newLogger(*verbose)
+	s.MaxScanBytes = *maxScanBytes
+	s.DefaultEncoding = *defaultEncoding
+	s.SkipFilesLargerThan = *skipLarger
+	s.HeaderLinesOnly = *headerLines
+	s.ExcludePatterns = excludePatterns
+	s.ExcludeDirs = excludeDirs
+	s.OutputFormat = *format
+	s.CompactJSON = *compact
+	s.JSONFiles = *perFile
+	s.TemplatePath = *template
+	s.NoTemplate = *noTemplate
+	s.InlineLicenses = *inlineLicenses || *mergeLicenseBlocks
+	s.MergeRepeatedLicenseBlocks = *mergeLicenseBlocks
+	for _, ext := range strings.Split(*extensions, ",") {
+		if ext = strings.TrimSpace(ext); ext != "" {
+			s.IncludeExtensions = append(s.IncludeExtensions, ext)
+		}
+	}
+	for _, dir := range strings.Split(*thirdParty, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			s.ThirdPartyDirs = append(s.ThirdPartyDirs, dir)
+		}
+	}
+
+	// Collapse holder name variants to their canonical names
+	if *holderMap != "" {
+		mapping, err := scanner.LoadHolderMap(*holderMap)
+		if err != nil {
+			fmt.Printf("Holder map error: %v\n", err)
+			os.Exit(exitError)
+		}
+		s.HolderMap = mapping
+	}
+
+	// Load the policy up front, so a broken one fails before the scan
+	var policy *scanner.Policy
+	if *policyFile != "" {
+		var err error
+		if policy, err = scanner.LoadPolicy(*policyFile); err != nil {
+			fmt.Printf("Policy error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
+	// Run the post hook for every written output file
+	if *postHook != "" {
+		s.OutputWritten = func(outputFile, name string) error {
+			err := runPostHook(*postHook, *postHookTimeout, outputFile, name)
+			if err != nil && !*postHookAbort {
+				fmt.Printf("Post hook error for %s: %v\n", outputFile, err)
+				return nil
+			}
+			return err
+		}
+	}
+
+	// Report the files scanned on one stderr line, overwritten as it grows
+	if *showProgress {
+		s.ProgressFunc = func(scanned, total int, currentPath string) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d files", scanned, total)
+			if scanned == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+
+	// Record per-file statements for the manifest
+	var manifestEntries []scanner.ManifestEntry
+	var manifestMu sync.Mutex
+	if *manifest != "" {
+		s.FileScanned = func(path string, copyrights []string) {
+			manifestMu.Lock()
+			defer manifestMu.Unlock()
+			manifestEntries = append(manifestEntries, scanner.ManifestEntry{File: path, Copyrights: copyrights, ThirdParty: s.IsThirdParty(path)})
+		}
+	}
+
+	// Tally the statements of every scanned file for the exit code
+	findings := &scanFindings{deniedHolders: deniedHolders, policy: policy}
+
+	// Check the policy on the entries of each report as it is scanned
+	if policy != nil {
+		s.EntriesScanned = findings.evaluatePolicy
+	}
+	if *failOnEmpty || len(deniedHolders) > 0 {
+		recordManifest := s.FileScanned
+		s.FileScanned = func(path string, copyrights []string) {
+			findings.add(path, copyrights)
+			if recordManifest != nil {
+				recordManifest(path, copyrights)
+			}
+		}
+	}
+
+	// Roll up statistics across all projects instead of writing per-project files
+	if flag.Arg(0) == "stats" {
+		if flag.NArg() != 2 {
+			fmt.Println("Usage: scanner stats <root directory>")
+			os.Exit(exitError)
+		}
+		if err := printStats(s, flag.Arg(1)); err != nil {
+			fmt.Printf("Stats error: %v\n", err)
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	// Classify the license files of a tree in the licenseclassifier result shape
+	if flag.Arg(0) == "classify" {
+		if flag.NArg() != 2 {
+			fmt.Println("Usage: scanner classify <directory>")
+			os.Exit(exitError)
+		}
+		results, err := s.ClassifyLicenseFiles(flag.Arg(1))
+		if err == nil {
+			err = scanner.WriteLicenseClassifications(os.Stdout, results)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Classify error: %v\n", err)
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	// Merge the statements of earlier reports into one deduplicated list
+	if flag.Arg(0) == "merge" {
+		if flag.NArg() < 2 {
+			fmt.Println("Usage: scanner merge <report>...")
+			os.Exit(exitError)
+		}
+		if err := scanner.MergeOutputs(flag.Args()[1:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Merge error: %v\n", err)
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	// Scan an explicit file list instead of walking directories
+	if *filesFrom != "" {
+		if flag.NArg() != 1 {
+			fmt.Println("Usage: scanner -files-from <file list> <output file>")
+			fmt.Println("Example: git diff --name-only | scanner -files-from - copyright.txt")
+			os.Exit(exitError)
+		}
+		messages := messageWriter(flag.Arg(0))
+		paths, err := readFileList(*filesFrom)
+		if err == nil {
+			err = scanFileList(s, findings, paths, flag.Arg(0))
+		}
+		if err != nil {
+			fmt.Fprintf(messages, "Scan error: %v\n", err)
+			os.Exit(exitError)
+		}
+		if flag.Arg(0) != stdio {
+			fmt.Printf("File list scanned successfully, result saved to: %s\n", flag.Arg(0))
+		}
+		writeManifestOrExit(*manifest, manifestEntries)
+		os.Exit(findings.exitCode(messages, *failOnEmpty))
+	}
+
+	// A single file or stdin without an output file is reported on stdout
+	args := flag.Args()
+	if len(args) == 1 && (args[0] == stdio || isRegularFile(args[0])) {
+		args = append(args, stdio)
+	}
+
 	// Check command line arguments
-	if len(os.Args) != 3 {
-		fmt.Println("Usage: scanner <scan directory> <output file pattern>")
+	if len(args) != 2 {
+		fmt.Println("Usage: scanner <scan directory or file> <output file pattern>")
 		fmt.Println("Example: scanner test_files 'copyright_{name}.txt'")
 		fmt.Println("Note: {name} will be replaced with subdirectory name")
-		os.Exit(1)
+		fmt.Println("With -single, the whole directory is scanned into one output file")
+		fmt.Println("'-' as the input reads a file from stdin, '-' as the output writes the report to stdout")
+		fmt.Println("Without an output file pattern, the report of a single file is printed")
+		os.Exit(exitError)
 	}
+	input, output := args[0], args[1]
 
-	// Create scanner and scan directories
-	s := scanner.NewScanner()
-	err := s.ScanSubDirectories(os.Args[1], os.Args[2])
+	// A file piped to stdin is scanned as a project named stdin
+	if input == stdio {
+		if policy != nil {
+			fmt.Fprintln(os.Stderr, "Error: -policy needs files to scan, not stdin")
+			os.Exit(exitError)
+		}
+		if *checkCompat {
+			fmt.Fprintln(os.Stderr, "Error: -check-compat needs license files to check, not stdin")
+			os.Exit(exitError)
+		}
+		if err := scanStdin(s, output); err != nil {
+			fmt.Fprintf(messageWriter(output), "Scan error: %v\n", err)
+			os.Exit(exitError)
+		}
+		writeManifestOrExit(*manifest, manifestEntries)
+		os.Exit(findings.exitCode(messageWriter(output), *failOnEmpty))
+	}
+
+	// A single file, a directory without subdirectories or, with -single, a
+	// whole tree is scanned as one project
+	single, err := isSingleTarget(input)
+	if err != nil {
+		fmt.Printf("Scan error: %v\n", err)
+		os.Exit(exitError)
+	}
+	if single || *singleTree {
+		if err := scanSingleTarget(s, findings, input, output, !single); err != nil {
+			fmt.Fprintf(messageWriter(output), "Scan error: %v\n", err)
+			os.Exit(exitError)
+		}
+		writeManifestOrExit(*manifest, manifestEntries)
+		os.Exit(findings.exitCode(messageWriter(output), *failOnEmpty))
+	}
+	if output == stdio {
+		fmt.Println("Error: only a single file or, with -single, a whole tree can be reported on stdout")
+		os.Exit(exitError)
+	}
+
+	// Scan directories, stopping at the next subdirectory on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = s.ScanSubDirectoriesContext(ctx, input, output, func(done, total int) {
+		fmt.Printf("Progress: %d/%d subdirectories\n", done, total)
+	})
 
 	// Handle errors
 	if err != nil {
 		fmt.Printf("Scan error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
 	fmt.Println("All directories scanned successfully!")
+	writeManifestOrExit(*manifest, manifestEntries)
+
+	// Fail the build if any subdirectory combines incompatible licenses
+	if *checkCompat {
+		if err := checkCompatibility(s, findings, input); err != nil {
+			fmt.Printf("Compatibility check error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+	os.Exit(findings.exitCode(os.Stdout, *failOnEmpty))
+}
+
+// scanFindings tallies the copyright statements of the scanned files for
+// -fail-on-empty and -deny, the violations of -policy and the projects
+// -check-compat found incompatible. Its methods are safe for concurrent use
+type scanFindings struct {
+	deniedHolders []string
+	policy        *scanner.Policy
+
+	mu         sync.Mutex
+	statements int
+	// denied lists the statements naming a denied holder, with their files
+	denied       []string
+	violations   []scanner.Violation
+	incompatible []string
+}
+
+// add records the copyright statements found in the file at path
+func (f *scanFindings) add(path string, copyrights []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.statements += len(copyrights)
+	for _, copyright := range copyrights {
+		for _, holder := range f.deniedHolders {
+			if scanner.HolderMatches(copyright, holder) {
+				f.denied = append(f.denied, fmt.Sprintf("%s: %s (denied holder %s)", path, copyright, holder))
+				break
+			}
+		}
+	}
+}
+
+// checkLicenses prints the license compatibility verdict of the named
+// project to w, records it if it is incompatible and returns it
+func (f *scanFindings) checkLicenses(w io.Writer, name string, licenses []string) *scanner.LicenseCompatibility {
+	compatibility := scanner.CheckLicenseCompatibility(licenses)
+	fmt.Fprintf(w, "%s: %s\n", name, compatibility.Explanation)
+	if !compatibility.Compatible {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.incompatible = append(f.incompatible, name)
+	}
+	return &compatibility
+}
+
+// evaluatePolicy records the policy violations of the entries of a scanned
+// report, if a policy was given
+func (f *scanFindings) evaluatePolicy(entries []scanner.CopyrightEntry) {
+	if f.policy == nil {
+		return
+	}
+	violations := f.policy.Evaluate(entries)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.violations = append(f.violations, violations...)
+}
+
+// exitCode prints the policy violations and denied holders found to w and
+// returns the exit code of a successful scan: exitError if a project has
+// incompatible licenses, exitPolicyViolation or, after it, exitDeniedHolder
+// if any were found, exitNoCopyrights if nothing was found and failOnEmpty
+// is set, exitOK otherwise
+func (f *scanFindings) exitCode(w io.Writer, failOnEmpty bool) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Subdirectories scanned in parallel record their violations in any order
+	sort.SliceStable(f.violations, func(i, j int) bool {
+		return f.violations[i].File < f.violations[j].File
+	})
+	for _, violation := range f.violations {
+		fmt.Fprintf(w, "Policy violation in %s\n", violation)
+	}
+	for _, denied := range f.denied {
+		fmt.Fprintf(w, "Denied holder found in %s\n", denied)
+	}
+
+	switch {
+	case len(f.incompatible) > 0:
+		fmt.Fprintf(w, "License compatibility check failed for %s\n", strings.Join(f.incompatible, ", "))
+		return exitError
+	case len(f.violations) > 0:
+		return exitPolicyViolation
+	case len(f.denied) > 0:
+		return exitDeniedHolder
+	case failOnEmpty && f.statements == 0:
+		fmt.Fprintln(w, "No copyright statements found")
+		return exitNoCopyrights
+	default:
+		return exitOK
+	}
+}
+
+// stringList is a flag that can be given several times
+type stringList []string
+
+// String returns the values joined by commas
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set appends a value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// isSingleTarget reports whether path is a regular file or a directory
+// without subdirectories, either of which is scanned as a single project
+func isSingleTarget(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %v", path, err)
+	}
+	if !info.IsDir() {
+		return true, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// scanSingleTarget scans a file or a whole directory tree into one output
+// file, {name} in the output pattern is replaced with its base name. tree
+// is set if the directory has subdirectories. With CheckCompatibility, the
+// verdict on its license files is recorded in findings
+func scanSingleTarget(s *scanner.Scanner, findings *scanFindings, path, outputPattern string, tree bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %v", path, err)
+	}
+
+	messages := messageWriter(outputPattern)
+	name := filepath.Base(path)
+	switch {
+	case tree:
+		fmt.Fprintf(messages, "Scanning the tree of %s as a single project\n", path)
+	case info.IsDir():
+		fmt.Fprintf(messages, "%s has no subdirectories, scanning it as a single project\n", path)
+	default:
+		fmt.Fprintf(messages, "%s is a file, scanning just that file\n", path)
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	outputFile := strings.ReplaceAll(outputPattern, "{name}", name)
+
+	var compatibility *scanner.LicenseCompatibility
+	if s.CheckCompatibility {
+		var licenses []string
+		if info.IsDir() {
+			licenses, err = s.DetectLicenses(path)
+		} else {
+			licenses, err = scanner.DetectFileLicenses([]string{path})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to detect licenses: %v", err)
+		}
+		compatibility = findings.checkLicenses(messages, name, licenses)
+	}
+
+	if scanner.IsStructuredFormat(s.OutputFormat) {
+		var entries []scanner.CopyrightEntry
+		if info.IsDir() {
+			entries, err = s.ScanDirectoryStructured(path)
+		} else {
+			entries, err = s.ScanFileStructured(path)
+		}
+		if err = printFileErrors(messages, err); err != nil {
+			return err
+		}
+		return writeEntriesReport(s, outputFile, path, name, entries, compatibility)
+	}
+
+	var copyrightText string
+	if info.IsDir() {
+		copyrightText, err = s.ScanDirectory(path)
+	} else {
+		copyrightText, err = s.ScanFile(path)
+	}
+	if err = printFileErrors(messages, err); err != nil {
+		return err
+	}
+	return writeTextReport(s, outputFile, path, name, copyrightText)
+}
+
+// stdio is the path that stands for stdin as the input and stdout as the output
+const stdio = "-"
+
+// isRegularFile checks if path exists and is not a directory
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// messageWriter returns where to print progress and errors, stderr when the
+// report itself goes to stdout
+func messageWriter(outputFile string) io.Writer {
+	if outputFile == stdio {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// scanStdin scans the text piped to stdin as a project named stdin, whose
+// statements are reported to FileScanned as a file named stdin. Binary input
+// is rejected
+func scanStdin(s *scanner.Scanner, outputPattern string) error {
+	const name = "stdin"
+	outputFile := strings.ReplaceAll(outputPattern, "{name}", name)
+
+	if scanner.IsStructuredFormat(s.OutputFormat) {
+		entries, err := s.ScanReaderStructured(os.Stdin, name)
+		if err != nil {
+			return err
+		}
+		if s.FileScanned != nil {
+			copyrights := make([]string, len(entries))
+			for i, entry := range entries {
+				copyrights[i] = entry.RawText
+			}
+			s.FileScanned(name, copyrights)
+		}
+		return writeEntriesReport(s, outputFile, name, name, entries, nil)
+	}
+
+	copyrightText, err := s.ScanReader(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if s.FileScanned != nil {
+		s.FileScanned(name, strings.FieldsFunc(copyrightText, func(r rune) bool { return r == '\n' }))
+	}
+	return writeTextReport(s, outputFile, name, name, copyrightText)
+}
+
+// writeTextReport writes the text report of the scanned path to outputFile,
+// or prints it without the prefix template if outputFile is stdio
+func writeTextReport(s *scanner.Scanner, outputFile, path, name, copyrightText string) error {
+	if outputFile == stdio {
+		fmt.Print(copyrightText)
+		return nil
+	}
+
+	outputFile, err := s.WriteReport(outputFile, name, copyrightText)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Completed scanning %s, result saved to: %s\n", path, outputFile)
+	return nil
+}
+
+// writeEntriesReport writes the structured report of the scanned path, with
+// its license compatibility verdict if not nil, to outputFile, or prints it
+// if outputFile is stdio
+func writeEntriesReport(s *scanner.Scanner, outputFile, path, name string, entries []scanner.CopyrightEntry, compatibility *scanner.LicenseCompatibility) error {
+	if outputFile == stdio {
+		report, err := s.CheckedEntriesReport(name, entries, compatibility)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(report)
+		return err
+	}
+
+	outputFile, err := s.WriteCheckedEntriesReport(outputFile, name, entries, compatibility)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Completed scanning %s, result saved to: %s\n", path, outputFile)
+	return nil
+}
+
+// printFileErrors prints the files of a directory scan that couldn't be read
+// to w, which don't fail the scan, and returns any other error
+func printFileErrors(w io.Writer, err error) error {
+	var fileErrors scanner.FileErrors
+	if !errors.As(err, &fileErrors) {
+		return err
+	}
+	for _, fileError := range fileErrors {
+		fmt.Fprintf(w, "Error processing file %s: %v\n", fileError.Path, fileError.Err)
+	}
+	fmt.Fprintf(w, "Skipped %d unreadable files or directories\n", len(fileErrors))
+	return nil
+}
+
+// printStats prints the portfolio statistics of rootDir as JSON
+func printStats(s *scanner.Scanner, rootDir string) error {
+	stats, err := s.PortfolioStats(rootDir)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stats)
+}
+
+// checkCompatibility records the license compatibility verdict of every
+// subdirectory in findings, printing each
+func checkCompatibility(s *scanner.Scanner, findings *scanFindings, rootDir string) error {
+	entries, err := os.ReadDir(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		licenses, err := s.DetectLicenses(filepath.Join(rootDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		findings.checkLicenses(os.Stdout, entry.Name(), licenses)
+	}
+	return nil
+}
+
+// readFileList reads the paths listed in listPath, one per line, ignoring
+// blank lines. A listPath of "-" reads the list from stdin
+func readFileList(listPath string) ([]string, error) {
+	var input io.Reader = os.Stdin
+	if listPath != "-" {
+		file, err := os.Open(listPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file list: %v", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var paths []string
+	lines := bufio.NewScanner(input)
+	for lines.Scan() {
+		if path := strings.TrimSpace(lines.Text()); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file list: %v", err)
+	}
+	return paths, nil
+}
+
+// scanFileList scans exactly the given files and writes the result to
+// outputFile. With CheckCompatibility, the verdict on the listed license
+// files is recorded in findings
+func scanFileList(s *scanner.Scanner, findings *scanFindings, paths []string, outputFile string) error {
+	var compatibility *scanner.LicenseCompatibility
+	if s.CheckCompatibility {
+		licenses, err := scanner.DetectFileLicenses(paths)
+		if err != nil {
+			return fmt.Errorf("failed to detect licenses: %v", err)
+		}
+		compatibility = findings.checkLicenses(messageWriter(outputFile), "file list", licenses)
+	}
+
+	// A file list has no project name
+	if scanner.IsStructuredFormat(s.OutputFormat) {
+		entries, err := s.ScanFilesStructured(paths)
+		if err != nil {
+			return err
+		}
+		if outputFile == stdio {
+			report, err := s.CheckedEntriesReport("", entries, compatibility)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(report)
+			return err
+		}
+		_, err = s.WriteCheckedEntriesReport(outputFile, "", entries, compatibility)
+		return err
+	}
+
+	copyrightText, err := s.ScanFiles(paths)
+	if err != nil {
+		return err
+	}
+	if outputFile == stdio {
+		fmt.Print(copyrightText)
+		return nil
+	}
+
+	report, err := s.EncodeOutput([]byte(copyrightText))
+	if err != nil {
+		return err
+	}
+	outputFile, err = scanner.WriteOutputFile(outputFile, report, s.CompressOutput)
+	if err != nil {
+		return err
+	}
+
+	if s.OutputWritten != nil {
+		if err := s.OutputWritten(outputFile, ""); err != nil {
+			return fmt.Errorf("post-processing of %s failed: %v", outputFile, err)
+		}
+	}
+	return nil
+}
+
+// runPostHook runs the post hook command with the output path and project name
+// as arguments and in NEMESIS_OUTPUT and NEMESIS_NAME, killing it after timeout
+func runPostHook(command string, timeout time.Duration, outputFile, name string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty post hook command")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, fields[0], append(fields[1:], outputFile, name)...)
+	cmd.Env = append(os.Environ(), "NEMESIS_OUTPUT="+outputFile, "NEMESIS_NAME="+name)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("post hook timed out after %v", timeout)
+		}
+		return fmt.Errorf("post hook failed: %v", err)
+	}
+	return nil
+}
+
+// writeManifestOrExit writes the manifest if one was requested, exiting on failure
+func writeManifestOrExit(path string, entries []scanner.ManifestEntry) {
+	if path == "" {
+		return
+	}
+
+	format := "json"
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		format = "csv"
+	}
+
+	var manifest bytes.Buffer
+	if err := scanner.WriteManifest(&manifest, entries, format); err != nil {
+		fmt.Printf("Manifest error: %v\n", err)
+		os.Exit(exitError)
+	}
+	if _, err := scanner.WriteOutputFile(path, manifest.Bytes(), false); err != nil {
+		fmt.Printf("Manifest error: %v\n", err)
+		os.Exit(exitError)
+	}
+	fmt.Printf("Manifest saved to: %s\n", path)
+}
+
+// newLogger returns the logger of the scanner messages, writing Info and
+// higher records, or Debug ones too if verbose, to stderr without timestamps
+func newLogger(verbose bool) *slog.Logger {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
 }
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cachedAnalysis is an analysis stored in the cache directory
+type cachedAnalysis struct {
+	Model    string `json:"model"`
+	Chunks   int    `json:"chunks"`
+	Analysis string `json:"analysis"`
+}
+
+// analysisCacheVersion is the format version of the cache keys, bumped
+// whenever the key inputs change so entries of older formats are never hit
+const analysisCacheVersion = "2"
+
+// analysisCacheKey hashes the key format version, the analysis backend (the
+// provider and its base URL), the model name, the kind of analysis and the
+// normalized copyright information, so line ending and trailing whitespace
+// changes still hit the cache while switching endpoints, models or between
+// free-text and structured analyses doesn't
+func analysisCacheKey(provider, baseURL, model, copyrightInfo string, structured bool) string {
+	lines := strings.Split(strings.ReplaceAll(copyrightInfo, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	normalized := strings.TrimSpace(strings.Join(lines, "\n"))
+
+	kind := "text"
+	if structured {
+		kind = "json"
+	}
+	fields := []string{analysisCacheVersion, provider, strings.TrimSuffix(baseURL, "/"), model, kind, normalized}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// analysisBackend returns the provider and base URL the analyses are sent
+// to, which are part of the cache key
+func (m *MCPService) analysisBackend() (provider, baseURL string) {
+	switch analyzer := m.analyzer.(type) {
+	case nil:
+		return "mcp", m.endpoint
+	case *OpenAIAnalyzer:
+		return "openai", analyzer.endpoint
+	default:
+		return fmt.Sprintf("%T", analyzer), ""
+	}
+}
+
+// cachePath returns the file caching the analysis of copyrightInfo, or "" if
+// the cache is disabled
+func (m *MCPService) cachePath(copyrightInfo string) string {
+	if m.cacheDir == "" {
+		return ""
+	}
+	provider, baseURL := m.analysisBackend()
+	return filepath.Join(m.cacheDir, analysisCacheKey(provider, baseURL, m.model, copyrightInfo, m.structured)+".json")
+}
+
+// cachedAnalysis returns the cached analysis of copyrightInfo, if any
+func (m *MCPService) cachedAnalysis(copyrightInfo string) (cachedAnalysis, bool) {
+	path := m.cachePath(copyrightInfo)
+	if path == "" {
+		return cachedAnalysis{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cachedAnalysis{}, false
+	}
+
+	// A damaged entry is treated as missing and overwritten
+	var cached cachedAnalysis
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Model != m.model || cached.Analysis == "" {
+		return cachedAnalysis{}, false
+	}
+	return cached, true
+}
+
+// cacheAnalysis stores the analysis of copyrightInfo. A cache that can't be
+// written only costs a repeated analysis, so errors are ignored
+func (m *MCPService) cacheAnalysis(copyrightInfo, analysis string, chunks int) {
+	path := m.cachePath(copyrightInfo)
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(cachedAnalysis{Model: m.model, Chunks: chunks, Analysis: analysis})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(m.cacheDir, 0755); err != nil {
+		return
+	}
+
+	// Write to a temporary file first so concurrent runs never read a partial entry
+	tmp, err := os.CreateTemp(m.cacheDir, ".analysis_*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// structuredAnalysisInstructions ask for an analysis as a JSON object that
+// ParseAnalysisResult reads
+const structuredAnalysisInstructions = `You are a copyright analysis expert. Analyze the provided copyright information and respond with a single JSON object only, without any text or code fences around it, in this form:
+{
+  "holders": [{"name": "Acme Corp.", "first_year": 2019, "last_year": 2024}],
+  "year_range": {"first": 2019, "last": 2024},
+  "conflicts": ["a potential conflict or overlapping claim"],
+  "recommendations": ["a recommendation for compliance"]
+}
+Leave out the years you can't determine and use empty arrays where there is nothing to report.`
+
+// AnalysisHolder is a copyright holder named by a structured analysis
+type AnalysisHolder struct {
+	Name string `json:"name"`
+	// FirstYear and LastYear are zero if the model gave no years
+	FirstYear int `json:"first_year,omitempty"`
+	LastYear  int `json:"last_year,omitempty"`
+}
+
+// AnalysisYearRange is the range of years covered by all copyrights
+type AnalysisYearRange struct {
+	First int `json:"first,omitempty"`
+	Last  int `json:"last,omitempty"`
+}
+
+// AnalysisResult is an analysis requested with MCPConfig.StructuredAnalysis.
+// Raw always holds the response of the model, the other fields are only set
+// if Structured is, i.e. if the response was a valid JSON analysis
+type AnalysisResult struct {
+	Holders         []AnalysisHolder  `json:"holders"`
+	YearRange       AnalysisYearRange `json:"year_range"`
+	Conflicts       []string          `json:"conflicts"`
+	Recommendations []string          `json:"recommendations"`
+
+	Raw        string `json:"-"`
+	Structured bool   `json:"-"`
+}
+
+// ErrInvalidAnalysisJSON is returned by ParseAnalysisResult for responses
+// that aren't a valid JSON analysis
+var ErrInvalidAnalysisJSON = errors.New("analysis is not valid JSON")
+
+// ParseAnalysisResult reads a JSON analysis as requested by
+// StructuredAnalysis. Code fences and text around the JSON object are
+// ignored. If the response isn't a valid analysis, it returns an error
+// wrapping ErrInvalidAnalysisJSON along with a result holding only Raw
+func ParseAnalysisResult(raw string) (AnalysisResult, error) {
+	fallback := AnalysisResult{Raw: raw}
+
+	start, end := strings.Index(raw, "{"), strings.LastIndex(raw, "}")
+	if start < 0 || end < start {
+		return fallback, fmt.Errorf("%w: no JSON object found", ErrInvalidAnalysisJSON)
+	}
+	var result AnalysisResult
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &result); err != nil {
+		return fallback, fmt.Errorf("%w: %v", ErrInvalidAnalysisJSON, err)
+	}
+
+	if len(result.Holders) == 0 && result.YearRange == (AnalysisYearRange{}) && len(result.Conflicts) == 0 && len(result.Recommendations) == 0 {
+		return fallback, fmt.Errorf("%w: no analysis fields", ErrInvalidAnalysisJSON)
+	}
+	for _, holder := range result.Holders {
+		if strings.TrimSpace(holder.Name) == "" {
+			return fallback, fmt.Errorf("%w: holder without a name", ErrInvalidAnalysisJSON)
+		}
+		if holder.FirstYear > 0 && holder.LastYear > 0 && holder.FirstYear > holder.LastYear {
+			return fallback, fmt.Errorf("%w: holder %s ends before it starts", ErrInvalidAnalysisJSON, holder.Name)
+		}
+	}
+	if result.YearRange.First > 0 && result.YearRange.Last > 0 && result.YearRange.First > result.YearRange.Last {
+		return fallback, fmt.Errorf("%w: year range ends before it starts", ErrInvalidAnalysisJSON)
+	}
+
+	result.Raw = raw
+	result.Structured = true
+	return result, nil
+}
+
+// String formats a structured analysis for the text report, or returns Raw
+// if the analysis isn't structured
+func (r AnalysisResult) String() string {
+	if !r.Structured {
+		return r.Raw
+	}
+
+	var result strings.Builder
+	result.WriteString("Copyright holders:\n")
+	for _, holder := range r.Holders {
+		fmt.Fprintf(&result, "- %s%s\n", holder.Name, yearSpan(" (%s)", holder.FirstYear, holder.LastYear))
+	}
+	if years := yearSpan("%s", r.YearRange.First, r.YearRange.Last); years != "" {
+		fmt.Fprintf(&result, "\nYears covered: %s\n", years)
+	}
+	writeList := func(title string, items []string) {
+		fmt.Fprintf(&result, "\n%s:\n", title)
+		if len(items) == 0 {
+			result.WriteString("- None\n")
+		}
+		for _, item := range items {
+			fmt.Fprintf(&result, "- %s\n", item)
+		}
+	}
+	writeList("Potential conflicts", r.Conflicts)
+	writeList("Recommendations", r.Recommendations)
+	return result.String()
+}
+
+// yearSpan formats the years from first to last into format, or returns ""
+// if neither is known
+func yearSpan(format string, first, last int) string {
+	switch {
+	case first == 0 && last == 0:
+		return ""
+	case first == 0 || last == 0 || first == last:
+		return fmt.Sprintf(format, fmt.Sprint(max(first, last)))
+	default:
+		return fmt.Sprintf(format, fmt.Sprintf("%d-%d", first, last))
+	}
+}
+
+// instructionsKey is the context key of the instructions an analyzer sends
+type instructionsKey struct{}
+
+// withAnalysisInstructions returns a context asking the built-in analyzers to
+// send instructions instead of the free-text analysisInstructions
+func withAnalysisInstructions(ctx context.Context, instructions string) context.Context {
+	return context.WithValue(ctx, instructionsKey{}, instructions)
+}
+
+// instructionsFrom returns the analysis instructions to send within ctx
+func instructionsFrom(ctx context.Context) string {
+	if instructions, ok := ctx.Value(instructionsKey{}).(string); ok {
+		return instructions
+	}
+	return analysisInstructions
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseAnalysisResult(t *testing.T) {
+	analysis := `{"holders": [{"name": "Acme Corp.", "first_year": 2019, "last_year": 2024}, {"name": "Jane Doe"}],
+ "year_range": {"first": 2019, "last": 2024},
+ "conflicts": [],
+ "recommendations": ["Keep the NOTICE file up to date"]}`
+	want := AnalysisResult{
+		Holders:         []AnalysisHolder{{Name: "Acme Corp.", FirstYear: 2019, LastYear: 2024}, {Name: "Jane Doe"}},
+		YearRange:       AnalysisYearRange{First: 2019, Last: 2024},
+		Conflicts:       []string{},
+		Recommendations: []string{"Keep the NOTICE file up to date"},
+		Structured:      true,
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{"plain", analysis, false},
+		{"code fence", "```json\n" + analysis + "\n```", false},
+		{"text around", "Here is the analysis:\n" + analysis + "\nLet me know if you need more.", false},
+		{"free text", "1. Holders: Acme Corp. (2019-2024)", true},
+		{"broken json", `{"holders": [{"name": "Acme Corp."}`, true},
+		{"wrong types", `{"holders": "Acme Corp."}`, true},
+		{"no fields", `{"analysis": "Acme Corp."}`, true},
+		{"holder without name", `{"holders": [{"first_year": 2019}]}`, true},
+		{"reversed years", `{"holders": [{"name": "Acme Corp.", "first_year": 2024, "last_year": 2019}]}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAnalysisResult(tt.raw)
+			if got.Raw != tt.raw {
+				t.Errorf("Raw = %q, want the response", got.Raw)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAnalysisJSON) {
+					t.Errorf("expected ErrInvalidAnalysisJSON, got %v", err)
+				}
+				if got.Structured || got.Holders != nil {
+					t.Errorf("expected only the raw text, got %+v", got)
+				}
+				if got.String() != tt.raw {
+					t.Errorf("String() = %q, want the raw text", got.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAnalysisResult failed: %v", err)
+			}
+			got.Raw = ""
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseAnalysisResult() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestAnalysisResultString(t *testing.T) {
+	result := AnalysisResult{
+		Holders:         []AnalysisHolder{{Name: "Acme Corp.", FirstYear: 2019, LastYear: 2024}, {Name: "Jane Doe", LastYear: 2021}, {Name: "Example Inc."}},
+		YearRange:       AnalysisYearRange{First: 2019, Last: 2024},
+		Recommendations: []string{"Keep the NOTICE file up to date"},
+		Structured:      true,
+	}
+	want := "Copyright holders:\n- Acme Corp. (2019-2024)\n- Jane Doe (2021)\n- Example Inc.\n" +
+		"\nYears covered: 2019-2024\n" +
+		"\nPotential conflicts:\n- None\n" +
+		"\nRecommendations:\n- Keep the NOTICE file up to date\n"
+	if got := result.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,466 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Archive formats recognized by detectArchiveFormat
+const (
+	archiveZip     = "zip"
+	archiveTar     = "tar"
+	archiveTarGz   = "tar.gz"
+	archiveBzip2   = "bzip2"
+	archiveXz      = "xz"
+	archive7z      = "7z"
+	archiveRar     = "rar"
+	archiveUnknown = "unknown"
+)
+
+// archiveExtensions maps the supported archive extensions to their format
+var archiveExtensions = []struct {
+	extension, format string
+}{
+	{".tar.gz", archiveTarGz},
+	{".tgz", archiveTarGz},
+	{".tar", archiveTar},
+	{".zip", archiveZip},
+}
+
+// archiveMagic maps the leading bytes of an archive to its format
+var archiveMagic = []struct {
+	magic  []byte
+	format string
+}{
+	{[]byte("PK\x03\x04"), archiveZip},
+	{[]byte("PK\x05\x06"), archiveZip},
+	{[]byte{0x1f, 0x8b}, archiveTarGz},
+	{[]byte("BZh"), archiveBzip2},
+	{[]byte("\xfd7zXZ\x00"), archiveXz},
+	{[]byte("7z\xbc\xaf\x27\x1c"), archive7z},
+	{[]byte("Rar!"), archiveRar},
+}
+
+// Default extraction limits, generous for real releases but finite
+const (
+	defaultMaxUncompressedBytes = 2 << 30
+	defaultMaxFileBytes         = 1 << 30
+	defaultMaxArchiveEntries    = 100000
+	defaultMaxArchiveDepth      = 1
+)
+
+// nestedArchiveExtensions are the Java archive formats unpacked as nested
+// archives in addition to archiveExtensions; they are zip files
+var nestedArchiveExtensions = []string{".jar", ".war", ".ear"}
+
+// ArchiveLimits caps what extracting a single archive may write, so a small
+// crafted archive can't exhaust disk or memory. Zero fields use the defaults
+type ArchiveLimits struct {
+	// MaxUncompressedBytes caps the total size of all extracted files, 2 GiB by default
+	MaxUncompressedBytes int64
+	// MaxFileBytes caps the size of each extracted file, 1 GiB by default
+	MaxFileBytes int64
+	// MaxEntries caps the number of entries in the archive, 100000 by default
+	MaxEntries int
+	// MaxDepth is the number of archive levels extracted. With 1, the default,
+	// archives inside the archive are left packed; with more, they are
+	// unpacked in place, sharing the MaxUncompressedBytes of the outer archive
+	MaxDepth int
+}
+
+// extractionBudget tracks the bytes still available while extracting an archive
+type extractionBudget struct {
+	limits    ArchiveLimits
+	remaining int64
+}
+
+// budget returns a fresh extraction budget with the defaults applied
+func (l ArchiveLimits) budget() *extractionBudget {
+	if l.MaxUncompressedBytes <= 0 {
+		l.MaxUncompressedBytes = defaultMaxUncompressedBytes
+	}
+	if l.MaxFileBytes <= 0 {
+		l.MaxFileBytes = defaultMaxFileBytes
+	}
+	if l.MaxEntries <= 0 {
+		l.MaxEntries = defaultMaxArchiveEntries
+	}
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = defaultMaxArchiveDepth
+	}
+	return &extractionBudget{limits: l, remaining: l.MaxUncompressedBytes}
+}
+
+// checkEntries fails if an archive has more than the allowed number of entries
+func (b *extractionBudget) checkEntries(entries int) error {
+	if entries > b.limits.MaxEntries {
+		return fmt.Errorf("archive has more than %d entries", b.limits.MaxEntries)
+	}
+	return nil
+}
+
+// copy copies the contents of the entry name, failing as soon as it exceeds
+// the per-file limit or the remaining total
+func (b *extractionBudget) copy(dst io.Writer, src io.Reader, name string) error {
+	limit := b.limits.MaxFileBytes
+	if b.remaining < limit {
+		limit = b.remaining
+	}
+
+	// Read one byte past the limit to tell a full entry from an oversized one
+	n, err := io.Copy(dst, io.LimitReader(src, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		if limit == b.limits.MaxFileBytes {
+			return fmt.Errorf("archive entry %s exceeds the limit of %d bytes", name, b.limits.MaxFileBytes)
+		}
+		return fmt.Errorf("archive exceeds the limit of %d uncompressed bytes", b.limits.MaxUncompressedBytes)
+	}
+	b.remaining -= n
+	return nil
+}
+
+// tarMagicOffset is the position of the "ustar" magic in a tar header
+const tarMagicOffset = 257
+
+// ArchiveName returns the name of an archive path without its archive
+// extension, and whether the extension is a supported archive format
+func ArchiveName(path string) (string, bool) {
+	base := filepath.Base(path)
+	for _, ext := range archiveExtensions {
+		if len(base) > len(ext.extension) && strings.EqualFold(base[len(base)-len(ext.extension):], ext.extension) {
+			return base[:len(base)-len(ext.extension)], true
+		}
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base)), false
+}
+
+// detectArchiveFormat sniffs the format of an archive from its leading bytes,
+// falling back to the extension for formats without a reliable magic number
+func detectArchiveFormat(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header, err := readArchiveHeader(file)
+	if err != nil {
+		return "", err
+	}
+	return sniffArchiveFormat(header, path), nil
+}
+
+// readArchiveHeader reads the leading bytes sniffArchiveFormat looks at
+func readArchiveHeader(r io.Reader) ([]byte, error) {
+	header := make([]byte, 512)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return header[:n], nil
+}
+
+// sniffArchiveFormat returns the format of an archive starting with header.
+// name is only consulted for old tar archives, which carry no magic number,
+// and may be empty
+func sniffArchiveFormat(header []byte, name string) string {
+	for _, magic := range archiveMagic {
+		if bytes.HasPrefix(header, magic.magic) {
+			return magic.format
+		}
+	}
+	if len(header) >= tarMagicOffset+5 && string(header[tarMagicOffset:tarMagicOffset+5]) == "ustar" {
+		return archiveTar
+	}
+
+	// Old tar archives carry no magic, so trust their extension
+	for _, ext := range archiveExtensions {
+		if ext.format == archiveTar && strings.HasSuffix(strings.ToLower(name), ext.extension) {
+			return archiveTar
+		}
+	}
+	return archiveUnknown
+}
+
+// extractArchive extracts a zip, tar or gzip-compressed tar archive to destDir,
+// along with the archives nested in it up to the configured depth
+func (m *MCPService) extractArchive(path, destDir string) error {
+	budget := m.limits.budget()
+	if err := extractArchiveFile(path, destDir, budget); err != nil {
+		return err
+	}
+	return extractNestedArchives(destDir, budget, 1)
+}
+
+// extractArchiveReader extracts the archive of size bytes read from r like
+// extractArchive, detecting its format from its content alone
+func (m *MCPService) extractArchiveReader(r io.ReaderAt, size int64, destDir string) error {
+	header, err := readArchiveHeader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return err
+	}
+
+	budget := m.limits.budget()
+	if err := extractArchiveFrom(r, size, sniffArchiveFormat(header, ""), destDir, budget); err != nil {
+		return err
+	}
+	return extractNestedArchives(destDir, budget, 1)
+}
+
+// extractArchiveFile extracts a single archive to destDir within budget
+func extractArchiveFile(path, destDir string, budget *extractionBudget) error {
+	format, err := detectArchiveFormat(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return extractArchiveFrom(file, info.Size(), format, destDir, budget)
+}
+
+// extractArchiveFrom extracts the archive of size bytes read from r, in the
+// given format, to destDir within budget
+func extractArchiveFrom(r io.ReaderAt, size int64, format, destDir string, budget *extractionBudget) error {
+	switch format {
+	case archiveZip:
+		reader, err := zip.NewReader(r, size)
+		if err != nil {
+			return err
+		}
+		return extractZipReader(reader, destDir, budget)
+	case archiveTar, archiveTarGz:
+		var reader io.Reader = io.NewSectionReader(r, 0, size)
+		if format == archiveTarGz {
+			gzipReader, err := gzip.NewReader(reader)
+			if err != nil {
+				return fmt.Errorf("failed to read gzip stream: %v", err)
+			}
+			defer gzipReader.Close()
+			reader = gzipReader
+		}
+		return extractTar(reader, destDir, budget)
+	}
+	return fmt.Errorf("unsupported archive format: %s", format)
+}
+
+// isNestedArchive reports whether an extracted file is unpacked as a nested archive
+func isNestedArchive(name string) bool {
+	if _, ok := ArchiveName(name); ok {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, nested := range nestedArchiveExtensions {
+		if ext == nested {
+			return true
+		}
+	}
+	return false
+}
+
+// extractNestedArchives replaces the archives found in dir, which was
+// extracted at the given depth, with a directory of the same name holding
+// their contents, so that "lib/dep.jar/META-INF/NOTICE" is scanned like any
+// other file. Files that aren't a supported archive despite their name are
+// left alone
+func extractNestedArchives(dir string, budget *extractionBudget, depth int) error {
+	if depth >= budget.limits.MaxDepth {
+		return nil
+	}
+
+	var archives []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && isNestedArchive(d.Name()) {
+			archives = append(archives, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, archive := range archives {
+		format, err := detectArchiveFormat(archive)
+		if err != nil {
+			return err
+		}
+		if format != archiveZip && format != archiveTar && format != archiveTarGz {
+			continue
+		}
+
+		// Extract next to the archive first, then take its name
+		tempDir, err := os.MkdirTemp(filepath.Dir(archive), ".nemesis_nested_*")
+		if err != nil {
+			return err
+		}
+		if err := extractArchiveFile(archive, tempDir, budget); err != nil {
+			os.RemoveAll(tempDir)
+			rel, _ := filepath.Rel(dir, archive)
+			return fmt.Errorf("failed to extract nested archive %s: %v", filepath.ToSlash(rel), err)
+		}
+		if err := os.Remove(archive); err != nil {
+			return err
+		}
+		if err := os.Rename(tempDir, archive); err != nil {
+			return err
+		}
+		if err := extractNestedArchives(archive, budget, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractTar extracts the entries of a tar stream to destDir within budget
+func extractTar(r io.Reader, destDir string, budget *extractionBudget) error {
+	reader := tar.NewReader(r)
+	for entries := 1; ; entries++ {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %v", err)
+		}
+		// The entry count of a stream is only known while reading it
+		if err := budget.checkEntries(entries); err != nil {
+			return err
+		}
+
+		path, err := archiveEntryPath(destDir, header.Name, header.Typeflag == tar.TypeDir)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if _, err := makeArchiveDir(destDir, path, header.Name); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(destDir, path, header.Name, reader, header.FileInfo().Mode().Perm(), budget); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// Symlinks must stay inside destDir, or later entries could be written through them
+			if err := createArchiveSymlink(header.Name, header.Linkname, path, destDir); err != nil {
+				return err
+			}
+		default:
+			// Hard links, devices and FIFOs carry no copyright text of their own
+		}
+	}
+}
+
+// archiveEntryPath resolves the path of an archive entry below destDir,
+// rejecting entries such as "../../etc/cron.d/evil" that would escape it. A
+// directory entry may name destDir itself, such as the "./" entry of a tar
+// created with "tar -C dir ."
+func archiveEntryPath(destDir, name string, dir bool) (string, error) {
+	path := filepath.Join(destDir, name)
+	if !isWithinDir(destDir, path) || (!dir && path == filepath.Clean(destDir)) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	return path, nil
+}
+
+// makeArchiveDir creates dir, a directory below destDir, for the archive entry
+// name and returns it with its symlinks resolved. A dir reached through a
+// symlink extracted earlier must still resolve inside destDir, or chained
+// links such as "a -> ." and "a/b -> .." would let later entries escape it
+func makeArchiveDir(destDir, dir, name string) (string, error) {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return "", err
+	}
+
+	// Check the deepest existing ancestor before creating anything below it
+	existing := dir
+	for {
+		if _, err := os.Lstat(existing); err == nil || existing == filepath.Dir(existing) {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil || !isWithinDir(root, resolved) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	resolved, err = filepath.EvalSymlinks(dir)
+	if err != nil || !isWithinDir(root, resolved) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	return resolved, nil
+}
+
+// writeArchiveFile writes the contents of the archive entry name to path,
+// failing once the entry or the whole archive exceeds its budget. The file
+// must not exist yet, so an earlier entry's symlink is never written through
+func writeArchiveFile(destDir, path, name string, contents io.Reader, mode os.FileMode, budget *extractionBudget) error {
+	dir, err := makeArchiveDir(destDir, filepath.Dir(path), name)
+	if err != nil {
+		return err
+	}
+	outFile, err := os.OpenFile(filepath.Join(dir, filepath.Base(path)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+
+	err = budget.copy(outFile, contents, name)
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// createArchiveSymlink creates a symlink entry, refusing targets outside
+// destDir. The target is cleaned and resolved against the link's directory
+// with its symlinks resolved, so only leading ".." elements climb, and only
+// from a real directory
+func createArchiveSymlink(name, target, path, destDir string) error {
+	dir, err := makeArchiveDir(destDir, filepath.Dir(path), name)
+	if err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return err
+	}
+
+	target = filepath.Clean(target)
+	if filepath.IsAbs(target) || !isWithinDir(root, filepath.Join(dir, target)) {
+		return fmt.Errorf("illegal symlink in archive: %s -> %s", name, target)
+	}
+	return os.Symlink(target, filepath.Join(dir, filepath.Base(path)))
+}
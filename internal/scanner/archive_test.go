@@ -0,0 +1,335 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTar writes a tar archive with the given regular files, gzip-compressed if compress is set
+func writeTar(t *testing.T, path string, files map[string]string, compress bool) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var out io.Writer = file
+	if compress {
+		gzipWriter := gzip.NewWriter(file)
+		defer gzipWriter.Close()
+		out = gzipWriter
+	}
+
+	writer := tar.NewWriter(out)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTarEntries writes a gzip-compressed tar archive holding the entries in
+// order, a directory for each name ending in a slash
+func writeTarEntries(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipWriter := gzip.NewWriter(file)
+	defer gzipWriter.Close()
+
+	writer := tar.NewWriter(gzipWriter)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		switch {
+		case e.link:
+			header = &tar.Header{Name: e.name, Mode: 0777, Linkname: e.content, Typeflag: tar.TypeSymlink}
+		case strings.HasSuffix(e.name, "/"):
+			header = &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := writer.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	files := map[string]string{"src/main.go": "// Copyright 2024 Acme Corp.\n"}
+
+	tests := []struct {
+		name  string
+		write func(t *testing.T, path string)
+	}{
+		{"release.zip", func(t *testing.T, path string) { writeZip(t, path, files) }},
+		{"release.tar", func(t *testing.T, path string) { writeTar(t, path, files, false) }},
+		{"release.tar.gz", func(t *testing.T, path string) { writeTar(t, path, files, true) }},
+		{"release.tgz", func(t *testing.T, path string) { writeTar(t, path, files, true) }},
+		// The format is sniffed from the contents, not the name
+		{"release.bin", func(t *testing.T, path string) { writeTar(t, path, files, true) }},
+	}
+
+	service := &MCPService{scanner: NewScanner()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archive := filepath.Join(dir, tt.name)
+			tt.write(t, archive)
+
+			destDir := filepath.Join(dir, "dest")
+			if err := service.extractArchive(archive, destDir); err != nil {
+				t.Fatalf("extractArchive failed: %v", err)
+			}
+			content, err := os.ReadFile(filepath.Join(destDir, "src", "main.go"))
+			if err != nil || string(content) != files["src/main.go"] {
+				t.Errorf("extracted file = %q, %v, want %q", content, err, files["src/main.go"])
+			}
+		})
+	}
+}
+
+func TestExtractArchiveErrors(t *testing.T) {
+	dir := t.TempDir()
+	service := &MCPService{scanner: NewScanner()}
+
+	traversal := filepath.Join(dir, "evil.tar.gz")
+	writeTar(t, traversal, map[string]string{"../evil.go": "package evil\n"}, true)
+	if err := service.extractArchive(traversal, filepath.Join(dir, "dest")); err == nil || !strings.Contains(err.Error(), "illegal file path in archive") {
+		t.Errorf("expected illegal file path error, got %v", err)
+	}
+
+	bzip2 := filepath.Join(dir, "release.tar.bz2")
+	if err := os.WriteFile(bzip2, []byte("BZh91AY&SY"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.extractArchive(bzip2, filepath.Join(dir, "dest")); err == nil || err.Error() != "unsupported archive format: bzip2" {
+		t.Errorf("expected unsupported bzip2 error, got %v", err)
+	}
+}
+
+func TestWriteArchiveFileThroughSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	destDir := filepath.Join(dir, "dest")
+	outside := filepath.Join(dir, "outside.txt")
+	if err := os.WriteFile(outside, []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(destDir, "planted")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(dir, filepath.Join(destDir, "up")); err != nil {
+		t.Fatal(err)
+	}
+
+	budget := ArchiveLimits{}.budget()
+	if err := writeArchiveFile(destDir, filepath.Join(destDir, "planted"), "planted", strings.NewReader("evil\n"), 0644, budget); err == nil {
+		t.Error("expected an error writing through an existing symlink")
+	}
+	if err := writeArchiveFile(destDir, filepath.Join(destDir, "up", "evil.txt"), "up/evil.txt", strings.NewReader("evil\n"), 0644, budget); err == nil || !strings.Contains(err.Error(), "illegal file path in archive") {
+		t.Errorf("expected illegal file path error, got %v", err)
+	}
+	if err := createArchiveSymlink("up/link", "outside.txt", filepath.Join(destDir, "up", "link"), destDir); err == nil {
+		t.Error("expected an error creating a symlink through an escaping symlink")
+	}
+
+	if content, _ := os.ReadFile(outside); string(content) != "original\n" {
+		t.Errorf("file outside the destination was overwritten: %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(err) {
+		t.Error("entry was written outside the destination")
+	}
+}
+
+func TestExtractTarChainedSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.tar.gz")
+	writeTarEntries(t, archive, chainedSymlinkEntries)
+
+	service := &MCPService{scanner: NewScanner()}
+	if err := service.extractArchive(archive, filepath.Join(dir, "dest")); err == nil {
+		t.Error("expected an error for chained escaping symlinks")
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(err) {
+		t.Error("entry was written outside the destination")
+	}
+}
+
+func TestExtractTarRootEntry(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "release.tar.gz")
+	// As written by "tar -C dir -czf release.tar.gz ."
+	writeTarEntries(t, archive, []archiveEntry{
+		{name: "./"},
+		{name: "./src/"},
+		{name: "./src/main.go", content: "// Copyright 2024 Acme Corp.\n"},
+	})
+
+	destDir := filepath.Join(dir, "dest")
+	service := &MCPService{scanner: NewScanner()}
+	if err := service.extractArchive(archive, destDir); err != nil {
+		t.Fatalf("extractArchive failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "src", "main.go")); err != nil {
+		t.Errorf("entry is missing: %v", err)
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	tests := []struct {
+		path      string
+		name      string
+		supported bool
+	}{
+		{"dist/release-1.0.zip", "release-1.0", true},
+		{"release-1.0.tar.gz", "release-1.0", true},
+		{"release-1.0.TGZ", "release-1.0", true},
+		{"release-1.0.tar", "release-1.0", true},
+		{"release-1.0.tar.bz2", "release-1.0.tar", false},
+		{"notes.txt", "notes", false},
+	}
+
+	for _, tt := range tests {
+		name, supported := ArchiveName(tt.path)
+		if name != tt.name || supported != tt.supported {
+			t.Errorf("ArchiveName(%q) = %q, %v, want %q, %v", tt.path, name, supported, tt.name, tt.supported)
+		}
+	}
+}
+
+func TestExtractArchiveLimits(t *testing.T) {
+	files := map[string]string{
+		"a.txt": strings.Repeat("a", 600),
+		"b.txt": strings.Repeat("b", 600),
+	}
+
+	tests := []struct {
+		name    string
+		limits  ArchiveLimits
+		wantErr string
+	}{
+		{"within limits", ArchiveLimits{MaxUncompressedBytes: 1200, MaxFileBytes: 600, MaxEntries: 2}, ""},
+		{"file too large", ArchiveLimits{MaxFileBytes: 599}, "exceeds the limit of 599 bytes"},
+		{"archive too large", ArchiveLimits{MaxUncompressedBytes: 1000}, "archive exceeds the limit of 1000 uncompressed bytes"},
+		{"too many entries", ArchiveLimits{MaxEntries: 1}, "archive has more than 1 entries"},
+	}
+
+	for _, tt := range tests {
+		for _, format := range []string{"zip", "tar.gz"} {
+			t.Run(tt.name+" "+format, func(t *testing.T) {
+				dir := t.TempDir()
+				archive := filepath.Join(dir, "bomb."+format)
+				if format == "zip" {
+					writeZip(t, archive, files)
+				} else {
+					writeTar(t, archive, files, true)
+				}
+
+				service := &MCPService{scanner: NewScanner(), limits: tt.limits}
+				err := service.extractArchive(archive, filepath.Join(dir, "dest"))
+				if tt.wantErr == "" {
+					if err != nil {
+						t.Fatalf("extractArchive failed: %v", err)
+					}
+					return
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+			})
+		}
+	}
+}
+
+func TestExtractNestedArchives(t *testing.T) {
+	dir := t.TempDir()
+	inner := filepath.Join(dir, "inner.zip")
+	writeZip(t, inner, map[string]string{"META-INF/NOTICE": "Copyright 2023 Inner Corp.\n"})
+	innerData, err := os.ReadFile(inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	middle := filepath.Join(dir, "middle.zip")
+	writeZip(t, middle, map[string]string{"dep.jar": string(innerData)})
+	middleData, err := os.ReadFile(middle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "release.zip")
+	writeZip(t, archive, map[string]string{
+		"lib/middle.zip": string(middleData),
+		"data/fake.jar":  "not an archive",
+	})
+
+	tests := []struct {
+		name    string
+		limits  ArchiveLimits
+		want    []string
+		wantErr string
+	}{
+		{name: "default depth", want: []string{"data/fake.jar", "lib/middle.zip"}},
+		{name: "depth 2", limits: ArchiveLimits{MaxDepth: 2}, want: []string{"data/fake.jar", "lib/middle.zip/dep.jar"}},
+		{name: "depth 3", limits: ArchiveLimits{MaxDepth: 3}, want: []string{"data/fake.jar", "lib/middle.zip/dep.jar/META-INF/NOTICE"}},
+		{name: "size cap shared", limits: ArchiveLimits{MaxDepth: 3, MaxUncompressedBytes: int64(len(middleData) + 20)}, wantErr: "failed to extract nested archive lib/middle.zip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destDir := filepath.Join(t.TempDir(), "dest")
+			service := &MCPService{scanner: NewScanner(), limits: tt.limits}
+			err := service.extractArchive(archive, destDir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractArchive failed: %v", err)
+			}
+
+			var got []string
+			filepath.WalkDir(destDir, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(destDir, path)
+					got = append(got, filepath.ToSlash(rel))
+				}
+				return err
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extracted files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// LineKind is the classification of a single line of a file
+type LineKind int
+
+const (
+	// LineOther is any other line, such as the continuation of a copyright statement
+	LineOther LineKind = iota
+	// LineCopyright starts a copyright statement
+	LineCopyright
+	// LineLicenseBoilerplate is part of license terms
+	LineLicenseBoilerplate
+	// LineCode is source code, a shell command or test content
+	LineCode
+)
+
+// codeKeywords mark code lines and test-related content. They only match
+// whole words, so "test" doesn't match "Latest"
+var codeKeywords = []string{
+	"func ", "type ", "var ", "const ", "package ", "import ", "return ", ":=", "if ",
+	"test", "echo", "find_", "append", "error:", "grep", "egrep", "while ", "read ", "|",
+}
+
+// codeSyntaxKeywords mark code on lines with a copyright marker, where the
+// other code keywords may be part of a holder name
+var codeSyntaxKeywords = []string{"func ", ":=", "find_", "error:", "echo ", "grep ", "egrep "}
+
+// licenseKeywords mark license boilerplate. On lines with a copyright marker
+// they only match whole words, so holders such as "Distributed Systems Inc."
+// or "Retained Earnings LLC" aren't taken for license terms
+var licenseKeywords = []string{
+	"grant of", "license", "permission", "permitted", "distribute", "notice", "provided",
+	"conditions", "subject to", "you may", "you must", "shall", "retain", "reproduce",
+}
+
+// copyrightMarkers mark a copyright statement, along with coprPattern
+var copyrightMarkers = []string{"copyright", "©", "(c)"}
+
+// copyrightExclusions mark lines that mention copyright without being a statement
+var copyrightExclusions = []string{
+	"copyrightadder", "copyrighttext", "addcopyright", "extractcopyright", "hascopyright",
+	"copyright.sh", "copyright notice", "copyright owner", "copyright holder", "above copyright",
+}
+
+// maxUnstructuredWords is the longest line without a year, symbol, legal
+// suffix or rights phrase still accepted as a copyright statement
+const maxUnstructuredWords = 12
+
+// copyrightVerbPattern matches "copyright" used as a verb in prose
+var copyrightVerbPattern = regexp.MustCompile(`\b(to|may|can|cannot|could|should|will|would|might|must|not)\s+copyright\b`)
+
+// spdxCopyrightPattern matches the REUSE copyright tag and the comment markers before it
+var spdxCopyrightPattern = regexp.MustCompile(`(?i)^[^a-z0-9]*spdx-filecopyrighttext:\s*`)
+
+// keywordClass is a bit set of the keyword lists a line matched
+type keywordClass uint8
+
+const (
+	classCode keywordClass = 1 << iota
+	classLicense
+	classMarker
+	classExclusion
+	// classCopr and classSPDX prefilter the lines checked by coprPattern and spdxCopyrightPattern
+	classCopr
+	classSPDX
+	classCodeSyntax
+	// classLicenseWord holds the license keywords matched as whole words
+	classLicenseWord
+)
+
+// wordClasses are the classes whose keywords only match whole words
+const wordClasses = classCode | classCodeSyntax | classLicenseWord
+
+// matcherKeyword is a keyword of one class
+type matcherKeyword struct {
+	text  string
+	class keywordClass
+}
+
+// keywordMatcher finds the lowercase keywords of all classes in a single
+// left-to-right pass, ignoring ASCII case and only comparing the keywords
+// starting with the byte at each position
+type keywordMatcher struct {
+	byFirstByte [256][]matcherKeyword
+	// singleByte holds the classes of one-byte keywords
+	singleByte [256]keywordClass
+	// secondBytes is a bit set of the second bytes following each first byte,
+	// rejecting most positions before any keyword is compared
+	secondBytes [256][4]uint64
+}
+
+// lineMatcher matches the keyword lists used by ClassifyLine
+var lineMatcher = newKeywordMatcher(map[keywordClass][]string{
+	classCode:        codeKeywords,
+	classCodeSyntax:  codeSyntaxKeywords,
+	classLicense:     licenseKeywords,
+	classLicenseWord: licenseKeywords,
+	classMarker:      copyrightMarkers,
+	classExclusion:   copyrightExclusions,
+	classCopr:        {"copr"},
+	classSPDX:        {"spdx-filecopyrighttext:"},
+})
+
+// newKeywordMatcher indexes keyword lists by their first byte
+func newKeywordMatcher(keywords map[keywordClass][]string) *keywordMatcher {
+	m := &keywordMatcher{}
+	for class, texts := range keywords {
+		for _, text := range texts {
+			if len(text) == 1 {
+				m.singleByte[text[0]] |= class
+				continue
+			}
+			m.byFirstByte[text[0]] = append(m.byFirstByte[text[0]], matcherKeyword{text: text, class: class})
+			m.secondBytes[text[0]][text[1]/64] |= 1 << (text[1] % 64)
+		}
+	}
+	return m
+}
+
+// match returns the classes of the keywords contained in s
+func (m *keywordMatcher) match(s string) keywordClass {
+	var found keywordClass
+	for i := 0; i < len(s); i++ {
+		first := lowerASCII(s[i])
+		found |= m.singleByte[first]
+		if i+1 == len(s) {
+			break
+		}
+		second := lowerASCII(s[i+1])
+		if m.secondBytes[first][second/64]&(1<<(second%64)) == 0 {
+			continue
+		}
+		for _, keyword := range m.byFirstByte[first] {
+			if found&keyword.class == 0 && hasPrefixFold(s[i:], keyword.text) &&
+				(keyword.class&wordClasses == 0 || isWholeWord(s, i, i+len(keyword.text))) {
+				found |= keyword.class
+			}
+		}
+	}
+	return found
+}
+
+// hasPrefixFold checks if s starts with the lowercase prefix, ignoring ASCII case
+func hasPrefixFold(s, prefix string) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+	for i := 0; i < len(prefix); i++ {
+		if lowerASCII(s[i]) != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isWholeWord checks if s[start:end] isn't part of a longer word, where
+// only its ends that are letters or digits need a boundary
+func isWholeWord(s string, start, end int) bool {
+	if isWordByte(s[start]) && start > 0 && isWordByte(s[start-1]) {
+		return false
+	}
+	return !isWordByte(s[end-1]) || end == len(s) || !isWordByte(s[end])
+}
+
+// isWordByte checks if c is part of a word: an ASCII letter or digit, or a
+// byte of a non-ASCII character
+func isWordByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c >= 0x80
+}
+
+// lowerASCII lowercases an ASCII letter byte
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// String returns the name of a line kind
+func (k LineKind) String() string {
+	switch k {
+	case LineCopyright:
+		return "Copyright"
+	case LineLicenseBoilerplate:
+		return "LicenseBoilerplate"
+	case LineCode:
+		return "Code"
+	default:
+		return "Other"
+	}
+}
+
+// ClassifyLine classifies a line as a copyright statement, license
+// boilerplate, code or other content. Code and license keywords take
+// precedence, so a copyright mention inside code or license terms is not
+// a statement. On lines with a copyright marker only code syntax counts as
+// code, as holders like "Return Path Inc." contain other code keywords, and
+// license keywords must be whole words
+func ClassifyLine(line string) LineKind {
+	line = strings.TrimSpace(norm.NFKC.String(line))
+
+	// Match all keyword lists in one pass, the patterns only run on prefiltered lines
+	found := lineMatcher.match(line)
+	if found&(classMarker|classCopr|classSPDX) == 0 {
+		switch {
+		case found&classCode != 0:
+			return LineCode
+		case found&classLicense != 0:
+			return LineLicenseBoilerplate
+		}
+		return LineOther
+	}
+
+	lowercaseLine := strings.ToLower(line)
+	switch {
+	// A REUSE copyright tag is a statement whatever its holder contains
+	case found&classSPDX != 0 && spdxCopyrightPattern.MatchString(lowercaseLine):
+		return LineCopyright
+	case found&classCodeSyntax != 0:
+		return LineCode
+	case found&classLicenseWord != 0:
+		return LineLicenseBoilerplate
+	case (found&classMarker != 0 || (found&classCopr != 0 && coprPattern.MatchString(lowercaseLine))) &&
+		found&classExclusion == 0 && !isCopyrightProse(lowercaseLine):
+		return LineCopyright
+	}
+	return LineOther
+}
+
+// isCopyrightProse checks if a lowercase line mentioning copyright is a prose
+// sentence rather than a statement. Only lines without a year, symbol, legal
+// suffix or rights phrase are considered: they are prose if they use
+// copyright as a verb, run longer than maxUnstructuredWords words, or don't
+// mention copyright within their first three words
+func isCopyrightProse(lowercaseLine string) bool {
+	if strings.Contains(lowercaseLine, "©") || strings.Contains(lowercaseLine, "(c)") ||
+		coprPattern.MatchString(lowercaseLine) || isConfirmedCopyright(lowercaseLine, DefaultRightsPhrases) {
+		return false
+	}
+
+	words := strings.Fields(cleanLine(lowercaseLine, DefaultCommentPrefixes))
+	if copyrightVerbPattern.MatchString(lowercaseLine) || len(words) > maxUnstructuredWords {
+		return true
+	}
+	for i, word := range words {
+		if strings.Contains(word, "copyright") {
+			return i > 2
+		}
+	}
+	return false
+}
+
+// normalizeSPDXCopyright turns a REUSE "SPDX-FileCopyrightText:" tag into a
+// plain copyright statement, adding a "Copyright" marker if the text lacks one
+func normalizeSPDXCopyright(line string) string {
+	loc := spdxCopyrightPattern.FindStringIndex(line)
+	if loc == nil {
+		return line
+	}
+	text := line[loc[1]:]
+	if copyrightMarkerPattern.MatchString(text) {
+		return text
+	}
+	return "Copyright " + text
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import "testing"
+
+func TestClassifyLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want LineKind
+	}{
+		{"// Copyright 2024 Acme Corp.", LineCopyright},
+		{" * (C) 2020 Jane Doe", LineCopyright},
+		{"© Example Inc.", LineCopyright},
+		{"Copr. 2001 Acme Widgets Inc.", LineCopyright},
+		{"Ｃｏｐｙｒｉｇｈｔ　２０２４　Acme", LineCopyright},
+		{"func extractCopyright(path string) string {", LineCode},
+		{"echo \"Copyright 2024\" >> NOTICE", LineCode},
+		{"Licensed under the Apache License, Version 2.0", LineLicenseBoilerplate},
+		{"The above copyright notice shall be included in all copies", LineLicenseBoilerplate},
+		{"and the names of the copyright holder", LineOther},
+		{"All rights reserved.", LineOther},
+		{"Contributors can copyright their own modifications", LineOther},
+		{"Every contribution keeps its copyright with the original author", LineOther},
+		{"Copyright The Acme Project", LineCopyright},
+		{"Portions copyright 2019 Jane Doe and the many contributors listed in the AUTHORS file", LineCopyright},
+		{"// SPDX-FileCopyrightText: 2024 The Test Authors", LineCopyright},
+		{"// SPDX-License-Identifier: MIT", LineLicenseBoilerplate},
+		{"Copyright (c) 2024 Latest Software Foundation", LineCopyright},
+		{"Copyright 2022 Return Path Inc.", LineCopyright},
+		{"Copyright 2020 Awhile Studios", LineCopyright},
+		{"Copyright 2020 Distributed Systems Inc.", LineCopyright},
+		{"Copyright (c) 2021 Noticeable Ltd.", LineCopyright},
+		{"© 2019 Licensed Software Foundation", LineCopyright},
+		{"Copyright 2018 Retained Earnings LLC", LineCopyright},
+		{"Copyright 2024 Acme Corp. Licensed under MIT", LineCopyright},
+		{"Copyright 2024 Acme Corp. under the MIT license", LineLicenseBoilerplate},
+		{"Run the latest build", LineOther},
+		{"go test ./...", LineCode},
+		{"", LineOther},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyLine(tt.line); got != tt.want {
+			t.Errorf("ClassifyLine(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
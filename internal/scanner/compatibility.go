@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LicenseConflict describes two licenses that can't be distributed together
+type LicenseConflict struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+	Reason string `json:"reason"`
+}
+
+// LicenseCompatibility is the verdict on whether a set of licenses can be distributed together
+type LicenseCompatibility struct {
+	Licenses    []string          `json:"licenses"`
+	Compatible  bool              `json:"compatible"`
+	Conflicts   []LicenseConflict `json:"conflicts,omitempty"`
+	Explanation string            `json:"explanation"`
+}
+
+// licenseConflicts is the embedded compatibility matrix, keyed by an
+// alphabetically ordered pair of SPDX ids. Pairs not listed are compatible
+var licenseConflicts = map[[2]string]string{
+	{"Apache-2.0", "GPL-2.0"}:  "the patent termination and indemnification terms of Apache-2.0 are additional restrictions not permitted by GPL-2.0",
+	{"AGPL-3.0", "GPL-2.0"}:    "GPL-2.0-only code can't be relicensed under the version 3 terms AGPL-3.0 requires",
+	{"GPL-2.0", "GPL-3.0"}:     "GPL-2.0-only and GPL-3.0 each require the combined work to be under their own version",
+	{"GPL-2.0", "LGPL-3.0"}:    "LGPL-3.0 code can only be combined under GPL-3.0 terms, which GPL-2.0-only code can't adopt",
+	{"Apache-2.0", "LGPL-2.1"}: "the patent terms of Apache-2.0 are additional restrictions not permitted by LGPL-2.1",
+}
+
+// copyleftLicenses maps copyleft licenses to the strength of their obligations,
+// the strongest present determines the license of the combined work
+var copyleftLicenses = map[string]int{
+	"MPL-2.0":  1,
+	"LGPL-2.1": 2,
+	"LGPL-3.0": 2,
+	"GPL-2.0":  3,
+	"GPL-3.0":  3,
+	"AGPL-3.0": 4,
+}
+
+// CheckLicenseCompatibility decides whether the given licenses can be
+// distributed together in one work and explains the verdict
+func CheckLicenseCompatibility(licenses []string) LicenseCompatibility {
+	ids := append([]string(nil), licenses...)
+	sort.Strings(ids)
+
+	result := LicenseCompatibility{Licenses: ids, Compatible: true}
+	for i := 0; i < len(ids); i++ {
+		for j := i + 1; j < len(ids); j++ {
+			if reason, ok := licenseConflicts[[2]string{ids[i], ids[j]}]; ok {
+				result.Compatible = false
+				result.Conflicts = append(result.Conflicts, LicenseConflict{First: ids[i], Second: ids[j], Reason: reason})
+			}
+		}
+	}
+
+	switch {
+	case len(ids) == 0:
+		result.Explanation = "No licenses detected, nothing to check"
+	case !result.Compatible:
+		var reasons []string
+		for _, conflict := range result.Conflicts {
+			reasons = append(reasons, fmt.Sprintf("%s and %s: %s", conflict.First, conflict.Second, conflict.Reason))
+		}
+		result.Explanation = "Incompatible licenses: " + strings.Join(reasons, "; ")
+	default:
+		// The strongest copyleft license governs the combined work
+		governing := ""
+		for _, id := range ids {
+			if copyleftLicenses[id] > copyleftLicenses[governing] {
+				governing = id
+			}
+		}
+		if governing == "" {
+			result.Explanation = "All licenses are permissive and can be distributed together"
+		} else {
+			result.Explanation = fmt.Sprintf("Licenses can be distributed together, the combined work must comply with %s", governing)
+		}
+	}
+
+	return result
+}
+
+// formatCompatibility formats a compatibility verdict as an output section
+func formatCompatibility(compatibility LicenseCompatibility) string {
+	var result strings.Builder
+
+	result.WriteString("\nLicense Compatibility:\n")
+	result.WriteString("----------------------------------------\n\n")
+	result.WriteString("Detected licenses: ")
+	if len(compatibility.Licenses) == 0 {
+		result.WriteString("none")
+	} else {
+		result.WriteString(strings.Join(compatibility.Licenses, ", "))
+	}
+	result.WriteString("\n")
+	if compatibility.Compatible {
+		result.WriteString("Verdict: compatible\n")
+	} else {
+		result.WriteString("Verdict: incompatible\n")
+	}
+	result.WriteString(compatibility.Explanation + "\n")
+
+	return result.String()
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckLicenseCompatibility(t *testing.T) {
+	tests := []struct {
+		name        string
+		licenses    []string
+		compatible  bool
+		explanation string
+	}{
+		{"none", nil, true, "No licenses detected"},
+		{"permissive", []string{"MIT", "Apache-2.0", "BSD-3-Clause"}, true, "All licenses are permissive"},
+		{"copyleft governs", []string{"MIT", "GPL-3.0", "Apache-2.0"}, true, "must comply with GPL-3.0"},
+		{"apache with gpl2", []string{"GPL-2.0", "Apache-2.0"}, false, "Apache-2.0 and GPL-2.0"},
+		{"gpl versions", []string{"GPL-3.0", "MIT", "GPL-2.0"}, false, "GPL-2.0 and GPL-3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckLicenseCompatibility(tt.licenses)
+			if got.Compatible != tt.compatible {
+				t.Errorf("Compatible = %v, want %v", got.Compatible, tt.compatible)
+			}
+			if !strings.Contains(got.Explanation, tt.explanation) {
+				t.Errorf("Explanation = %q, want it to contain %q", got.Explanation, tt.explanation)
+			}
+		})
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	tests := map[string]string{
+		"Apache License\n  Version 2.0, January 2004":                                                                             "Apache-2.0",
+		"GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991":                                                                        "GPL-2.0",
+		"GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007":                                                              "LGPL-3.0",
+		"Permission is hereby granted, free of charge, to any person ...\nThe above copyright notice and this\npermission notice": "MIT",
+		"Some custom terms": "",
+	}
+
+	for text, want := range tests {
+		if got := DetectLicense(text); got != want {
+			t.Errorf("DetectLicense(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestDetectFileLicenses(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"LICENSE":       "Apache License\n  Version 2.0, January 2004\n",
+		"lib/COPYING":   "GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991\n",
+		"NOTICE":        "GNU GENERAL PUBLIC LICENSE\nVersion 3\n",
+		"license.go":    "// GNU Affero General Public License version 3\n",
+		"lib/README.md": "MIT\n",
+	})
+
+	var paths []string
+	for _, name := range []string{"LICENSE", "lib/COPYING", "NOTICE", "license.go", "lib/README.md"} {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	licenses, err := DetectFileLicenses(paths)
+	if err != nil {
+		t.Fatalf("DetectFileLicenses failed: %v", err)
+	}
+	if want := []string{"Apache-2.0", "GPL-2.0"}; !reflect.DeepEqual(licenses, want) {
+		t.Errorf("DetectFileLicenses() = %v, want %v", licenses, want)
+	}
+
+	// The file list report carries the verdict too
+	s := NewScanner()
+	s.CheckCompatibility = true
+	report, err := s.ScanFiles(paths)
+	if err != nil {
+		t.Fatalf("ScanFiles failed: %v", err)
+	}
+	if !strings.Contains(report, "Verdict: incompatible\n") {
+		t.Errorf("expected an incompatible verdict, got:\n%s", report)
+	}
+}
+
+func TestCompatibilityJSONReport(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"app/main.go": "// Copyright 2024 Acme Corp.\n",
+		"app/LICENSE": "Apache License\n  Version 2.0, January 2004\n",
+		"app/COPYING": "GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991\n",
+	})
+
+	s := NewScanner()
+	s.OutputFormat = FormatJSON
+	s.CheckCompatibility = true
+	outputDir := t.TempDir()
+	if err := s.ScanSubDirectories(root, filepath.Join(outputDir, "{name}.json")); err != nil {
+		t.Fatalf("ScanSubDirectories failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "app.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Compatibility == nil || report.Compatibility.Compatible || len(report.Compatibility.Conflicts) != 1 {
+		t.Errorf("expected an incompatible verdict, got %+v", report.Compatibility)
+	}
+
+	// Without a verdict, the report has no compatibility object
+	plain, err := s.EntriesReport("app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(plain), "compatibility") {
+		t.Errorf("unexpected compatibility in %s", plain)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maxDecompressedBytes caps the content read from a compressed file, like the
+// size of a file extracted from an archive
+const maxDecompressedBytes = defaultMaxFileBytes
+
+// errNotGzip is returned for a .gz file that doesn't hold a gzip stream
+var errNotGzip = errors.New("not a gzip file")
+
+// compressedFile is an open gzip file whose content is read decompressed
+type compressedFile struct {
+	io.Reader
+	gzip *gzip.Reader
+	file io.Closer
+}
+
+// Close closes the decompressor and the underlying file
+func (f *compressedFile) Close() error {
+	f.gzip.Close()
+	return f.file.Close()
+}
+
+// openFile opens a file of the OS filesystem to scan like openFileFS
+func openFile(path string) (io.ReadCloser, error) {
+	return openFileFS(osFile(path))
+}
+
+// openFileFS opens the file name of fsys to scan. A .gz file is read
+// decompressed, up to maxDecompressedBytes, and fails with errNotGzip if it
+// isn't gzip despite its name
+func openFileFS(fsys fs.FS, name string) (io.ReadCloser, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(path.Ext(name), ".gz") {
+		return file, nil
+	}
+
+	buffered := bufio.NewReader(file)
+	if magic, err := buffered.Peek(len(gzipMagic)); err != nil || !bytes.Equal(magic, gzipMagic) {
+		file.Close()
+		return nil, errNotGzip
+	}
+	gzipReader, err := gzip.NewReader(buffered)
+	if err != nil {
+		file.Close()
+		return nil, errNotGzip
+	}
+	return &compressedFile{Reader: io.LimitReader(gzipReader, maxDecompressedBytes), gzip: gzipReader, file: file}, nil
+}
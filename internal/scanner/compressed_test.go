@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// gzipData compresses data
+func gzipData(t *testing.T, data string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	if _, err := gzipWriter.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestScanDirectoryGzipFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go":      "// Copyright 2024 Acme Corp.\n",
+		"legacy.go.gz": gzipData(t, "// Copyright 2019 Archived Code Ltd.\npackage legacy\n"),
+		"NOTES.TXT.GZ": gzipData(t, "Copyright (c) 2020 Upper Case Inc.\n"),
+		"fake.txt.gz":  "Copyright 2021 Not Compressed Inc.\n",
+		"broken.gz":    "\x1f\x8b\x00garbage",
+		"blob.bin.gz":  gzipData(t, "Copyright 2022 Binary Blob Inc.\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"),
+	})
+
+	s := NewScanner()
+	got, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	want := "Copyright (c) 2020 Upper Case Inc.\nCopyright 2019 Archived Code Ltd.\nCopyright 2024 Acme Corp.\n"
+	if got != want {
+		t.Errorf("ScanDirectory() = %q, want %q", got, want)
+	}
+}
+
+func TestOpenFileNotGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.gz")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openFile(path); err != errNotGzip {
+		t.Errorf("openFile() error = %v, want errNotGzip", err)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Points each signal adds to the confidence of a statement, out of 100
+const (
+	confidenceYear         = 30
+	confidenceGlyph        = 25
+	confidenceHolder       = 25
+	confidenceNotASentence = 20
+	confidenceMaxPoints    = 100
+	maxStatementWords      = 15
+)
+
+// copyrightGlyphPattern matches the copyright symbol and its ASCII forms
+var copyrightGlyphPattern = regexp.MustCompile(`(?i)©|\(c\)|\bcopr\b`)
+
+// sentencePunctuationPattern matches punctuation of prose rather than of a
+// notice, such as a question or a full stop followed by a lowercase word
+var sentencePunctuationPattern = regexp.MustCompile(`[?!;]|[.:]\s+\p{Ll}`)
+
+// copyrightConfidence scores how likely a statement is a real copyright
+// notice rather than prose mentioning copyright, from 0 to 1. A year, a
+// copyright glyph, a capitalized holder and the absence of sentence-like
+// punctuation each add to the score
+func copyrightConfidence(statement string) float64 {
+	statement = strings.Join(strings.Fields(statement), " ")
+	points := 0
+	if yearTokenPattern.MatchString(statement) {
+		points += confidenceYear
+	}
+	if copyrightGlyphPattern.MatchString(statement) {
+		points += confidenceGlyph
+	}
+	if _, holder, _ := splitHolder(statement); holder != "" {
+		if first, _ := utf8.DecodeRuneInString(holder); unicode.IsUpper(first) || unicode.IsDigit(first) {
+			points += confidenceHolder
+		}
+	}
+	if !sentencePunctuationPattern.MatchString(statement) && len(strings.Fields(statement)) <= maxStatementWords {
+		points += confidenceNotASentence
+	}
+	return float64(points) / confidenceMaxPoints
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyrightConfidence(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      float64
+	}{
+		{"Copyright (c) 2024 Acme Corp.", 1},
+		{"Copyright © 2019-2023 Jane Doe. All rights reserved.", 1},
+		{"Copyright 2024 Acme Corp.", 0.75},
+		{"Copyright (c) Jane Doe", 0.7},
+		{"Copyright Acme Corp.", 0.45},
+		{"copyright notices are kept as they are. see the docs for details", 0},
+		{"Copyright: please ask the maintainers whether the notice needs updating?", 0},
+		{"Copyright law protects the original works of authorship fixed in any tangible medium of expression", 0.2},
+	}
+
+	for _, tt := range tests {
+		if got := copyrightConfidence(tt.statement); got != tt.want {
+			t.Errorf("copyrightConfidence(%q) = %v, want %v", tt.statement, got, tt.want)
+		}
+	}
+}
+
+func TestMinConfidence(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go":   "// Copyright (c) 2024 Acme Corp.\npackage main\n",
+		"guide.md":  "The copyright of this page belongs to its authors; ask them.\n",
+		"holder.go": "// Copyright Acme Corp.\npackage main\n",
+	})
+
+	tests := []struct {
+		minConfidence float64
+		want          string
+	}{
+		{0, "The copyright of this page belongs to its authors; ask them.\nCopyright Acme Corp.\nCopyright (c) 2024 Acme Corp.\n"},
+		{0.4, "Copyright Acme Corp.\nCopyright (c) 2024 Acme Corp.\n"},
+		{0.5, "Copyright (c) 2024 Acme Corp.\n"},
+	}
+
+	for _, tt := range tests {
+		s := NewScanner()
+		s.MinConfidence = tt.minConfidence
+		got, err := s.ScanDirectory(dir)
+		if err != nil {
+			t.Fatalf("ScanDirectory failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("ScanDirectory() with MinConfidence %v = %q, want %q", tt.minConfidence, got, tt.want)
+		}
+	}
+
+	entries, err := NewScanner().ScanFileStructured(filepath.Join(dir, "holder.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Confidence != 0.45 {
+		t.Errorf("ScanFileStructured() = %+v, want one entry with confidence 0.45", entries)
+	}
+}
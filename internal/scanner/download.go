@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Default download limits of DownloadArchive
+const (
+	DefaultMaxDownloadBytes = 1 << 30
+	DefaultDownloadTimeout  = 5 * time.Minute
+)
+
+// archiveContentTypes are the content types accepted for a downloaded archive.
+// Object stores often serve archives as generic binary data
+var archiveContentTypes = map[string]bool{
+	"application/zip":              true,
+	"application/x-zip-compressed": true,
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/x-tar":            true,
+	"application/x-gtar":           true,
+	"application/x-compressed":     true,
+	"application/octet-stream":     true,
+	"binary/octet-stream":          true,
+}
+
+// DownloadOptions configures DownloadArchive
+type DownloadOptions struct {
+	// Headers are sent with the request, e.g. for authentication
+	Headers nethttp.Header
+	// MaxBytes caps the size of the download, DefaultMaxDownloadBytes if unset
+	MaxBytes int64
+	// Timeout limits the whole download, DefaultDownloadTimeout if unset
+	Timeout time.Duration
+}
+
+// DownloadArchive downloads the archive at rawURL to a temporary file and
+// returns its path, which the caller must remove. Responses that aren't
+// successful, exceed MaxBytes or have a content type other than an archive or
+// binary data, such as the HTML of a login page, fail without leaving a file
+func DownloadArchive(ctx context.Context, rawURL string, options DownloadOptions) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", fmt.Errorf("invalid archive URL %q", rawURL)
+	}
+
+	maxBytes := options.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxDownloadBytes
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultDownloadTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download request: %v", err)
+	}
+	for name, values := range options.Headers {
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+
+	response, err := nethttp.DefaultClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to download archive: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != nethttp.StatusOK {
+		return "", fmt.Errorf("failed to download archive: server returned %s", response.Status)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !archiveContentTypes[mediaType] {
+			return "", fmt.Errorf("failed to download archive: unexpected content type %q", contentType)
+		}
+	}
+	if response.ContentLength > maxBytes {
+		return "", fmt.Errorf("failed to download archive: size %d exceeds the limit of %d bytes", response.ContentLength, maxBytes)
+	}
+
+	// Keep the extension of the URL, the format is detected from the content anyway
+	base := path.Base(parsed.Path)
+	name, _ := ArchiveName(base)
+	file, err := os.CreateTemp("", "nemesis_download_*"+strings.TrimPrefix(base, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %v", err)
+	}
+
+	// Read one byte past the limit to tell a complete file from a truncated one
+	written, err := io.Copy(file, io.LimitReader(response.Body, maxBytes+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	switch {
+	case err != nil:
+		err = fmt.Errorf("failed to download archive: %v", err)
+	case written > maxBytes:
+		err = fmt.Errorf("failed to download archive: size exceeds the limit of %d bytes", maxBytes)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
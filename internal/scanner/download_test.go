@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDownloadArchive(t *testing.T) {
+	body := "archive content"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/release.tar.gz":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Write([]byte(body))
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	headers := http.Header{"Authorization": {"Bearer token"}}
+	tests := []struct {
+		name    string
+		path    string
+		options DownloadOptions
+		wantErr string
+	}{
+		{name: "success", path: "/release.tar.gz", options: DownloadOptions{Headers: headers}},
+		{name: "missing header", path: "/release.tar.gz", wantErr: "401"},
+		{name: "not found", path: "/missing.zip", wantErr: "404"},
+		{name: "html content", path: "/login", wantErr: "unexpected content type"},
+		{name: "too large", path: "/release.tar.gz", options: DownloadOptions{Headers: headers, MaxBytes: 4}, wantErr: "exceeds the limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := DownloadArchive(context.Background(), server.URL+tt.path, tt.options)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DownloadArchive() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadArchive() error = %v", err)
+			}
+			defer os.Remove(path)
+			if !strings.HasSuffix(path, ".tar.gz") {
+				t.Errorf("DownloadArchive() path = %q, want the .tar.gz extension", path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != body {
+				t.Errorf("downloaded content = %q, want %q", data, body)
+			}
+		})
+	}
+
+	if _, err := DownloadArchive(context.Background(), "ftp://example.com/a.zip", DownloadOptions{}); err == nil {
+		t.Error("DownloadArchive() accepted a non-HTTP URL")
+	}
+}
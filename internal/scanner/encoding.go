@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Byte order marks recognized by the scanner
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// textFile is an open text file whose content is read as UTF-8
+type textFile struct {
+	io.Reader
+	file io.Closer
+}
+
+// Close closes the underlying file
+func (f *textFile) Close() error {
+	return f.file.Close()
+}
+
+// openText opens the text file name of fsys like openFileFS, decoding UTF-16
+// and legacy single-byte files and stripping a leading byte order mark so
+// that the content is always read as UTF-8
+func (s *Scanner) openText(fsys fs.FS, name string) (io.ReadCloser, error) {
+	file, err := openFileFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return &textFile{Reader: s.decodeText(file), file: file}, nil
+}
+
+// DefaultEncoding is the legacy encoding assumed for text that is neither
+// UTF-8 nor has a byte order mark. Windows-1252 decodes every printable
+// Latin-1 character the same way
+const DefaultEncoding = "windows-1252"
+
+// legacyEncodings are the single-byte encodings Scanner.DefaultEncoding can name
+var legacyEncodings = map[string]encoding.Encoding{
+	"windows-1252": charmap.Windows1252,
+	"cp1252":       charmap.Windows1252,
+	"iso-8859-1":   charmap.ISO8859_1,
+	"latin1":       charmap.ISO8859_1,
+	"iso-8859-15":  charmap.ISO8859_15,
+	"latin9":       charmap.ISO8859_15,
+}
+
+// IsSupportedEncoding checks if name is a legacy encoding Scanner.DefaultEncoding accepts
+func IsSupportedEncoding(name string) bool {
+	_, ok := legacyEncodings[strings.ToLower(name)]
+	return ok
+}
+
+// legacyEncoding returns the encoding named by DefaultEncoding
+func (s *Scanner) legacyEncoding() encoding.Encoding {
+	if enc, ok := legacyEncodings[strings.ToLower(s.DefaultEncoding)]; ok {
+		return enc
+	}
+	return legacyEncodings[DefaultEncoding]
+}
+
+// decodeText reads r as UTF-8, decoding UTF-16 content and stripping a
+// leading byte order mark like openText. Content without a byte order mark
+// whose first TextDetectionBytes aren't valid UTF-8 is decoded from the
+// legacy encoding, so "Jos\xe9" in Latin-1 reads as "José"
+func (s *Scanner) decodeText(r io.Reader) io.Reader {
+	size := s.textDetectionBytes()
+	buffered := bufio.NewReaderSize(r, size)
+	sample, _ := buffered.Peek(size)
+
+	fallback := encoding.Nop
+	if !isUTF8Sample(sample) {
+		fallback = s.legacyEncoding()
+	}
+	return transform.NewReader(buffered, unicode.BOMOverride(fallback.NewDecoder()))
+}
+
+// isUTF8Sample checks if the leading bytes of a text are valid UTF-8,
+// ignoring a character cut off at the end of the sample
+func isUTF8Sample(sample []byte) bool {
+	for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(sample[i]) {
+			if !utf8.FullRune(sample[i:]) {
+				sample = sample[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(sample)
+}
+
+// decodeBOMPrefix decodes the leading bytes of a file with a byte order mark to
+// UTF-8, returning buf unchanged if it has none
+func decodeBOMPrefix(buf []byte) []byte {
+	var decoder *encoding.Decoder
+	switch {
+	case bytes.HasPrefix(buf, utf8BOM):
+		return buf[len(utf8BOM):]
+	case bytes.HasPrefix(buf, utf16LEBOM):
+		decoder = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()
+	case bytes.HasPrefix(buf, utf16BEBOM):
+		decoder = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()
+	default:
+		return buf
+	}
+
+	// A sample may end in the middle of a code unit
+	decoded, err := decoder.Bytes(buf[:len(buf)&^1])
+	if err != nil {
+		return buf
+	}
+	return decoded
+}
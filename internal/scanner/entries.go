@@ -0,0 +1,690 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CopyrightEntry is a single copyright statement found while scanning
+type CopyrightEntry struct {
+	Holder  string `json:"holder"`
+	Email   string `json:"email,omitempty"`
+	Years   []int  `json:"years,omitempty"`
+	RawText string `json:"rawText"`
+	// CleanText is the statement with its whitespace normalized, set if
+	// PreserveOriginal keeps RawText as written
+	CleanText  string `json:"cleanText,omitempty"`
+	SourceFile string `json:"sourceFile"`
+	ThirdParty bool   `json:"thirdParty,omitempty"`
+
+	// License is the SPDX license expression declared by the source file, if any
+	License string `json:"license,omitempty"`
+	// NoticeType classifies the statement as proprietary, SPDX-tagged or bare
+	NoticeType NoticeType `json:"noticeType"`
+	// Confidence scores from 0 to 1 how likely the statement is a real
+	// notice rather than prose, as filtered by MinConfidence
+	Confidence float64 `json:"confidence"`
+	// Warnings describes the implausible Years of the statement, set if
+	// ValidateYears is
+	Warnings []string `json:"warnings,omitempty"`
+	// SuspiciousYear is set for a statement with Warnings
+	SuspiciousYear bool `json:"suspiciousYear,omitempty"`
+	// MissingYear is set for a statement without any year if
+	// FlagMissingYears is
+	MissingYear bool `json:"missingYear,omitempty"`
+
+	// attribution is appended to the statement in text output, e.g. for images
+	attribution string
+}
+
+// String formats the entry as a line of the text report
+func (e CopyrightEntry) String() string {
+	if e.attribution == "" {
+		return e.RawText
+	}
+	return fmt.Sprintf("%s (%s)", e.RawText, e.attribution)
+}
+
+// FileError is a file or subdirectory of a scanned directory that couldn't be read
+type FileError struct {
+	Path string
+	Err  error
+}
+
+// Error describes the file and the reason it couldn't be read
+func (e FileError) Error() string {
+	return fmt.Sprintf("failed to process file %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// FileErrors is returned by a directory scan, along with its complete result,
+// when some files or subdirectories couldn't be read. Unless StrictErrors is
+// set, the scan continues past them, so callers that don't care can use the
+// result and ignore the error
+type FileErrors []FileError
+
+// Error summarizes the failed files
+func (e FileErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("failed to process %d files or directories, the first: %v", len(e), e[0])
+}
+
+// dedupKey identifies the entry when deduplicating statements. Like the
+// statements of a file, it ignores case, punctuation and email addresses, so
+// "Copyright Acme, Inc." and "copyright acme inc" are the same. Unlike them,
+// statements with different years stay apart
+func (e CopyrightEntry) dedupKey() string {
+	return fmt.Sprint(normalizeForComparison(withoutEmails(e.String())), e.Years)
+}
+
+// nicerStatement checks if statement a looks better than b, having more
+// capital letters and punctuation, as in "Copyright Acme, Inc." over
+// "copyright acme inc"
+func nicerStatement(a, b string) bool {
+	score := func(statement string) int {
+		n := 0
+		for _, r := range statement {
+			if unicode.IsUpper(r) || unicode.IsPunct(r) {
+				n++
+			}
+		}
+		return n
+	}
+	return score(a) > score(b)
+}
+
+// scanResult collects what a scan found, before it is formatted as a report
+type scanResult struct {
+	entries []CopyrightEntry
+	// licenses are the SPDX license expressions of the scanned files
+	licenses       []string
+	inlineLicenses strings.Builder
+	// licenseFiles are the LICENSE-like files of a directory scan
+	licenseFiles []directoryFile
+	fileErrors   FileErrors
+}
+
+// err returns the file errors of the scan, or nil if every file was read
+func (r *scanResult) err() error {
+	if len(r.fileErrors) == 0 {
+		return nil
+	}
+	return r.fileErrors
+}
+
+// ScanDirectoryStructured scans a directory like ScanDirectory, but returns
+// every copyright statement as an entry instead of a formatted report.
+// Entries are not deduplicated, so each one keeps the file it was found in.
+// Files that couldn't be read are returned as FileErrors with the entries
+func (s *Scanner) ScanDirectoryStructured(dir string) ([]CopyrightEntry, error) {
+	return s.scanDirectoryStructured(context.Background(), dir)
+}
+
+// scanDirectoryStructured implements ScanDirectoryStructured, aborting once ctx is done
+func (s *Scanner) scanDirectoryStructured(ctx context.Context, dir string) ([]CopyrightEntry, error) {
+	result, err := s.scanDirectoryEntries(ctx, os.DirFS(dir), dir, dir)
+	if err != nil {
+		return nil, err
+	}
+	return result.entries, result.err()
+}
+
+// ScanDirectoryPerFile scans a directory like ScanDirectoryStructured and
+// groups the entries by the file they were found in, so that each notice can
+// be traced to its origin. Statements repeated across files are kept in each
+// of them. Files that couldn't be read are returned as FileErrors with the map
+func (s *Scanner) ScanDirectoryPerFile(dir string) (map[string][]CopyrightEntry, error) {
+	entries, err := s.ScanDirectoryStructured(dir)
+	if err != nil && !errors.As(err, new(FileErrors)) {
+		return nil, err
+	}
+	files := make(map[string][]CopyrightEntry)
+	for _, entry := range entries {
+		files[entry.SourceFile] = append(files[entry.SourceFile], entry)
+	}
+	return files, err
+}
+
+// StopScan can be returned by the callback of ScanDirectoryFunc to end the
+// scan early without an error
+var StopScan = errors.New("stop scan")
+
+// ScanDirectoryFunc scans a directory like ScanDirectoryStructured, but calls
+// fn with each distinct statement as soon as it is found instead of
+// collecting the entries. Statements arrive in path order and are
+// deduplicated like the lines of a report, so only the first file of each is
+// reported. An error returned by fn stops the scan and is returned, except
+// StopScan, which stops it without an error
+func (s *Scanner) ScanDirectoryFunc(dir string, fn func(entry CopyrightEntry) error) error {
+	// The entries split from one statement by SplitHolders differ in holder
+	type entryKey struct {
+		line, holder string
+		thirdParty   bool
+	}
+	seen := make(map[entryKey]bool)
+	var fileErrors FileErrors
+
+	err := s.scanDirectoryFiles(context.Background(), os.DirFS(dir), dir, dir, func(file directoryFile, scan fileScan) error {
+		if scan.scanned {
+			s.reportFile(file.source, scan.statements)
+			for _, entry := range scan.entries {
+				key := entryKey{entry.dedupKey(), normalizeForComparison(entry.Holder), entry.ThirdParty}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if err := fn(entry); err != nil {
+					return err
+				}
+			}
+		}
+		if scan.err != nil {
+			fileError := FileError{Path: file.path, Err: scan.err}
+			if s.StrictErrors {
+				return fileError
+			}
+			fileErrors = append(fileErrors, fileError)
+		}
+		return nil
+	})
+	if err == StopScan {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(fileErrors) > 0 {
+		return fileErrors
+	}
+	return nil
+}
+
+// fileEntries turns the copyright lines extracted from one file into entries,
+// applying the holder map and anonymization. license is the first SPDX
+// expression declared by the file, if any
+func (s *Scanner) fileEntries(copyright, source, attribution, license string, thirdParty bool) []CopyrightEntry {
+	var entries []CopyrightEntry
+	for _, c := range strings.Split(copyright, "\n") {
+		c = s.canonicalizeCopyright(c)
+		if c == "" {
+			continue
+		}
+		if s.AnonymizePersonalNames {
+			c = anonymizeCopyright(c, s.pseudonymKey())
+		}
+
+		// Holder and years are read from the normalized form
+		clean := c
+		if s.PreserveOriginal {
+			clean = strings.Join(strings.Fields(c), " ")
+		}
+		_, holder, _ := splitHolder(clean)
+		holders := []string{holder}
+		if s.SplitHolders {
+			if split := splitHolderList(holder); len(split) > 0 {
+				holders = split
+			}
+		}
+		for _, holder := range holders {
+			holder, email := splitEmail(holder)
+			entry := CopyrightEntry{
+				Holder:      strings.TrimRight(holder, " .,;"),
+				Email:       email,
+				Years:       ParseCopyrightYears(clean),
+				RawText:     c,
+				SourceFile:  source,
+				ThirdParty:  thirdParty,
+				License:     license,
+				NoticeType:  classifyNotice(clean, license),
+				Confidence:  copyrightConfidence(clean),
+				attribution: attribution,
+			}
+			if s.PreserveOriginal {
+				entry.CleanText = clean
+			}
+			if s.ValidateYears {
+				entry.Warnings = ValidateCopyrightYears(entry.Years)
+				entry.SuspiciousYear = len(entry.Warnings) > 0
+			}
+			if s.FlagMissingYears {
+				entry.MissingYear = len(entry.Years) == 0
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// sortLines sorts the lines of text case-insensitively, lines differing only
+// in case in byte order
+func sortLines(text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := strings.ToLower(lines[i]), strings.ToLower(lines[j])
+		if a != b {
+			return a < b
+		}
+		return lines[i] < lines[j]
+	})
+	return joinLines(lines)
+}
+
+// directoryFile is a file found while walking a directory. path is the file
+// as reported in errors, relPath its slash-separated name in fsys
+type directoryFile struct {
+	fsys                  fs.FS
+	path, relPath, source string
+	// err is set for a directory that couldn't be read, listed in its place
+	err error
+}
+
+// fileScan is the result of scanning one file of a directory
+type fileScan struct {
+	// scanned is set if the file was read and its statements are reported
+	scanned        bool
+	statements     []string
+	entries        []CopyrightEntry
+	licenses       []string
+	inlineLicenses string
+	err            error
+}
+
+// scanDirectoryEntries walks fsys, the tree of dir, and collects the
+// copyright entries, license expressions and inline licenses of its files,
+// with their source joined to base as in ScanDirectoryAs. Files are scanned
+// by up to Concurrency workers and merged in walk order, so the result
+// doesn't depend on scheduling. Once ctx is done, the scan stops and returns
+// ctx.Err()
+func (s *Scanner) scanDirectoryEntries(ctx context.Context, fsys fs.FS, dir, base string) (*scanResult, error) {
+	result := &scanResult{}
+	err := s.scanDirectoryFiles(ctx, fsys, dir, base, func(file directoryFile, scan fileScan) error {
+		if scan.scanned {
+			s.reportFile(file.source, scan.statements)
+			result.addFile(scan.entries, scan.licenses)
+			result.inlineLicenses.WriteString(scan.inlineLicenses)
+			if name := filepath.Base(file.path); isLicenseTextFileName(name) && !isNoticeFileName(name) {
+				result.licenseFiles = append(result.licenseFiles, file)
+			}
+		}
+		if scan.err != nil {
+			fileError := FileError{Path: file.path, Err: scan.err}
+			if s.StrictErrors {
+				return fileError
+			}
+			result.fileErrors = append(result.fileErrors, fileError)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.entriesScanned(result.entries)
+	return result, nil
+}
+
+// scanDirectoryFiles walks fsys, the tree of dir, and scans its files with up
+// to Concurrency workers, passing each scan to handle in walk order, which is
+// sorted by path, as soon as it and those before it are done. An error
+// returned by handle stops the scan and is returned, as is ctx.Err() once
+// ctx is done
+func (s *Scanner) scanDirectoryFiles(ctx context.Context, fsys fs.FS, dir, base string, handle func(file directoryFile, scan fileScan) error) error {
+	files, err := s.walkDirectory(ctx, fsys, dir, base)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type indexedScan struct {
+		index int
+		scan  fileScan
+	}
+	jobs := make(chan int)
+	results := make(chan indexedScan)
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.concurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results <- indexedScan{index, s.scanDirectoryFile(ctx, files[index])}
+			}
+		}()
+	}
+	go func() {
+	feed:
+		for index := range files {
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	// Scans finishing out of order wait until those before them are handled
+	pending := make(map[int]fileScan)
+	next := 0
+	var handleErr error
+	for result := range results {
+		if handleErr != nil || ctx.Err() != nil {
+			// Drain the workers that are still running
+			continue
+		}
+		pending[result.index] = result.scan
+		for scan, ok := pending[next]; ok; scan, ok = pending[next] {
+			delete(pending, next)
+			if handleErr = handle(files[next], scan); handleErr != nil {
+				cancel()
+				break
+			}
+			next++
+			if s.ProgressFunc != nil {
+				s.ProgressFunc(next, len(files), files[next-1].path)
+			}
+		}
+	}
+	if handleErr != nil {
+		return handleErr
+	}
+	return ctx.Err()
+}
+
+// walkDirectory lists the files of fsys, the tree of dir, to scan in lexical
+// path order, skipping ignored, excluded and oversized paths. Symlinked files
+// are listed, symlinked directories only with FollowSymlinks
+func (s *Scanner) walkDirectory(ctx context.Context, fsys fs.FS, dir, base string) ([]directoryFile, error) {
+	walk := &directoryWalk{scanner: s, ctx: ctx, fsys: fsys, dir: dir, base: base}
+	info, err := fs.Stat(fsys, ".")
+	if err == nil {
+		err = walk.visit(".", info)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error scanning directory %s: %v", dir, err)
+	}
+	return walk.files, nil
+}
+
+// directoryWalk is the state of walkDirectory
+type directoryWalk struct {
+	scanner   *Scanner
+	ctx       context.Context
+	fsys      fs.FS
+	dir, base string
+	files     []directoryFile
+	ignore    gitignore
+	// visited holds the directories entered so far by modification time,
+	// so a symlink cycle is entered only once when following symlinks
+	visited map[int64][]os.FileInfo
+}
+
+// visit walks relPath, the path of a file or directory relative to the
+// scanned directory. info describes it, or the target of a symlink
+func (w *directoryWalk) visit(relPath string, info fs.FileInfo) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	s := w.scanner
+	if s.RespectGitignore && relPath != "." && w.ignore.ignored(relPath, info.IsDir()) {
+		return nil
+	}
+	if relPath != "." && s.excluded(relPath, info.IsDir()) {
+		return nil
+	}
+
+	if !info.IsDir() {
+		// Skip huge files, such as generated bundles, without opening them
+		if s.SkipFilesLargerThan > 0 && info.Size() > s.SkipFilesLargerThan {
+			return nil
+		}
+		if !s.includedExtension(relPath) {
+			return nil
+		}
+		w.files = append(w.files, w.file(relPath, nil))
+		return nil
+	}
+
+	// Enter each directory once, a symlink back to it would loop forever
+	if s.FollowSymlinks && w.seen(info) {
+		return nil
+	}
+
+	// Load the ignore rules of the directory before its entries
+	if s.RespectGitignore {
+		ignoreBase := relPath
+		if ignoreBase == "." {
+			ignoreBase = ""
+		}
+		if err := w.ignore.load(w.fsys, relPath, ignoreBase); err != nil {
+			return err
+		}
+	}
+
+	// An unreadable directory is skipped and reported like a file
+	entries, err := fs.ReadDir(w.fsys, relPath)
+	if errors.Is(err, fs.ErrPermission) && !s.StrictErrors && relPath != "." {
+		w.files = append(w.files, w.file(relPath, err))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entryRel := entry.Name()
+		if relPath != "." {
+			entryRel = relPath + "/" + entry.Name()
+		}
+
+		// Symlinks are judged by their target, dangling ones are skipped
+		entryInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entryInfo.Mode()&fs.ModeSymlink != 0 {
+			if entryInfo, err = fs.Stat(w.fsys, entryRel); err != nil {
+				continue
+			}
+			if entryInfo.IsDir() && !s.FollowSymlinks {
+				continue
+			}
+		}
+
+		if err := w.visit(entryRel, entryInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// file returns the listing of the file relPath, or of an unreadable directory
+// if err is set
+func (w *directoryWalk) file(relPath string, err error) directoryFile {
+	return directoryFile{
+		fsys:    w.fsys,
+		path:    filepath.Join(w.dir, filepath.FromSlash(relPath)),
+		relPath: relPath,
+		source:  filepath.Join(w.base, filepath.FromSlash(relPath)),
+		err:     err,
+	}
+}
+
+// seen records a directory as visited and reports whether it already was
+func (w *directoryWalk) seen(info os.FileInfo) bool {
+	if w.visited == nil {
+		w.visited = make(map[int64][]os.FileInfo)
+	}
+	key := info.ModTime().UnixNano()
+	for _, visited := range w.visited[key] {
+		if os.SameFile(visited, info) {
+			return true
+		}
+	}
+	w.visited[key] = append(w.visited[key], info)
+	return false
+}
+
+// concurrency returns the number of files scanned at once, one per CPU if unset
+func (s *Scanner) concurrency() int {
+	if s.Concurrency > 0 {
+		return s.Concurrency
+	}
+	return runtime.NumCPU()
+}
+
+// scanDirectoryFile scans a single file found by scanDirectoryEntries
+func (s *Scanner) scanDirectoryFile(ctx context.Context, file directoryFile) fileScan {
+	if file.err != nil {
+		return fileScan{err: file.err}
+	}
+	thirdParty := s.IsThirdParty(file.relPath)
+
+	// Images carry their copyright in metadata, attributed to the image itself
+	if s.ScanImageMetadata && isImageFile(file.path) {
+		statements, err := imageStatements(file.fsys, file.relPath)
+		if err != nil {
+			return fileScan{err: err}
+		}
+		return fileScan{
+			scanned:    true,
+			statements: statements,
+			entries:    s.fileEntries(joinLines(statements), file.source, file.relPath, "", thirdParty),
+		}
+	}
+
+	// Skip non-text files, but report those that can't be opened
+	if !s.isTextFileFS(file.fsys, file.relPath) {
+		return fileScan{err: openError(file.fsys, file.relPath)}
+	}
+
+	// Extract copyright information
+	statements, licenses, err := s.extractStatementsAndLicenses(ctx, file.fsys, file.relPath)
+	if err != nil {
+		return fileScan{err: err}
+	}
+	scan := fileScan{
+		scanned:    true,
+		statements: statements,
+		entries:    s.fileEntries(s.formatStatements(statements), file.source, "", firstLicense(licenses), thirdParty),
+		licenses:   licenses,
+	}
+
+	// Record license texts embedded in the file
+	if s.InlineLicenses {
+		inline, err := s.inlineLicenses(file.fsys, file.relPath)
+		if err != nil {
+			scan.err = err
+			return scan
+		}
+		scan.inlineLicenses = formatInlineLicenses(file.relPath, inline)
+	}
+	return scan
+}
+
+// openError returns the error opening the file name of fsys, or nil if it can be opened
+func openError(fsys fs.FS, name string) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// addFile records the entries and license expressions of a file
+func (r *scanResult) addFile(entries []CopyrightEntry, licenses []string) {
+	r.entries = append(r.entries, entries...)
+	r.licenses = append(r.licenses, licenses...)
+}
+
+// firstLicense returns the first of a file's license expressions, or ""
+func firstLicense(licenses []string) string {
+	if len(licenses) == 0 {
+		return ""
+	}
+	return licenses[0]
+}
+
+// formatResult formats a scan result as a text report, without the sections
+// that only apply to a whole directory
+func (s *Scanner) formatResult(result *scanResult) string {
+	return s.formatEntries(result.entries) +
+		detectedLicensesSection(result.licenses) +
+		licensesFoundSection(findLicenseTexts(result.licenseFiles)) +
+		inlineLicenseSection(result.inlineLicenses.String())
+}
+
+// formatEntries formats entries as the copyright part of a text report: the
+// deduplicated first-party statements, the third-party section and, if
+// enabled, the year warnings
+func (s *Scanner) formatEntries(entries []CopyrightEntry) string {
+	var result, thirdParty []string
+	seenCopyrights := make(map[string]int)
+	seenThirdParty := make(map[string]int)
+
+	for _, entry := range entries {
+		// Third-party copyrights are grouped separately
+		target, seen := &result, seenCopyrights
+		if entry.ThirdParty {
+			target, seen = &thirdParty, seenThirdParty
+		}
+
+		// A duplicate keeps the place of the first statement, but the nicest form
+		line := entry.String()
+		if i, ok := seen[entry.dedupKey()]; ok {
+			if nicerStatement(line, (*target)[i]) {
+				(*target)[i] = line
+			}
+			continue
+		}
+		seen[entry.dedupKey()] = len(*target)
+		*target = append(*target, line)
+	}
+
+	text, thirdPartyText := joinLines(result), joinLines(thirdParty)
+	if s.MergeYears {
+		text, thirdPartyText = mergeYears(text), mergeYears(thirdPartyText)
+	}
+	if s.SortOutput {
+		text, thirdPartyText = sortLines(text), sortLines(thirdPartyText)
+	}
+	if s.GroupByYear {
+		text, thirdPartyText = groupByYear(text), groupByYear(thirdPartyText)
+	}
+	text += thirdPartySection(thirdPartyText)
+
+	// List statements with implausible years before the license text
+	if s.ValidateYears || s.FlagMissingYears {
+		text += yearWarnings(text, s.ValidateYears, s.FlagMissingYears)
+	}
+	return text
+}
@@ -0,0 +1,439 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestScanDirectoryStructured(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go":        "// Copyright 2019, 2021 Acme Corp.\npackage main\n",
+		"util.go":        "// Copyright 2019, 2021 Acme Corp.\npackage main\n",
+		"vendor/lib.go":  "// Copyright (c) Jane Doe\npackage lib\n",
+		"docs/README.md": "No statements here.\n",
+	})
+
+	s := NewScanner()
+	s.ThirdPartyDirs = []string{"vendor"}
+	entries, err := s.ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].SourceFile < entries[j].SourceFile })
+
+	want := []CopyrightEntry{
+		{Holder: "Acme Corp", Years: []int{2019, 2021}, RawText: "Copyright 2019, 2021 Acme Corp.", SourceFile: filepath.Join(dir, "main.go"), NoticeType: NoticeBareCopyright, Confidence: 0.75},
+		{Holder: "Acme Corp", Years: []int{2019, 2021}, RawText: "Copyright 2019, 2021 Acme Corp.", SourceFile: filepath.Join(dir, "util.go"), NoticeType: NoticeBareCopyright, Confidence: 0.75},
+		{Holder: "Jane Doe", Years: []int{}, RawText: "Copyright (c) Jane Doe", SourceFile: filepath.Join(dir, "vendor", "lib.go"), ThirdParty: true, NoticeType: NoticeBareCopyright, Confidence: 0.7},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ScanDirectoryStructured() = %#v, want %#v", entries, want)
+	}
+
+	// The text report is formatted from the same entries
+	text, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantText := "Copyright 2019, 2021 Acme Corp.\n" +
+		"\nThird-Party Copyrights:\n----------------------------------------\n\n" +
+		"Copyright (c) Jane Doe\n"
+	if text != wantText {
+		t.Errorf("ScanDirectory() = %q, want %q", text, wantText)
+	}
+}
+
+func TestScanDirectoryConcurrencyIsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	files := make(map[string]string)
+	for i := 0; i < 40; i++ {
+		files[fmt.Sprintf("pkg%d/file%02d.go", i%4, i)] = fmt.Sprintf("// Copyright %d Holder %d Inc.\npackage pkg\n", 2000+i%7, i)
+	}
+	writeFiles(t, dir, files)
+
+	scan := func(concurrency int) (string, []string) {
+		s := NewScanner()
+		s.Concurrency = concurrency
+		var scanned []string
+		s.FileScanned = func(path string, copyrights []string) {
+			scanned = append(scanned, path)
+		}
+		result, err := s.ScanDirectory(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result, scanned
+	}
+
+	want, wantScanned := scan(1)
+	if !sort.StringsAreSorted(wantScanned) || len(wantScanned) != len(files) {
+		t.Fatalf("FileScanned paths = %q, want all %d files in path order", wantScanned, len(files))
+	}
+	for i := 0; i < 5; i++ {
+		got, gotScanned := scan(8)
+		if got != want {
+			t.Errorf("ScanDirectory() with 8 workers = %q, want %q", got, want)
+		}
+		if !reflect.DeepEqual(gotScanned, wantScanned) {
+			t.Errorf("FileScanned paths with 8 workers = %q, want %q", gotScanned, wantScanned)
+		}
+	}
+}
+
+func TestScanDirectorySizeLimits(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"bundle.js": "// Copyright 2024 Bundle Inc.\n" + strings.Repeat("var x = 1;\n", 100) + "// Copyright 2024 Trailer Inc.\n",
+		"small.go":  "// Copyright 2024 Acme Corp.\npackage main\n",
+	})
+
+	tests := []struct {
+		maxScanBytes int64
+		skipLarger   int64
+		want         []string
+	}{
+		{0, 0, []string{"Bundle Inc", "Trailer Inc", "Acme Corp"}},
+		{100, 0, []string{"Bundle Inc", "Acme Corp"}},
+		{-1, 500, []string{"Acme Corp"}},
+	}
+	for _, tt := range tests {
+		s := NewScanner()
+		s.MaxScanBytes = tt.maxScanBytes
+		s.SkipFilesLargerThan = tt.skipLarger
+		entries, err := s.ScanDirectoryStructured(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var holders []string
+		for _, entry := range entries {
+			holders = append(holders, entry.Holder)
+		}
+		if !reflect.DeepEqual(holders, tt.want) {
+			t.Errorf("MaxScanBytes=%d SkipFilesLargerThan=%d holders = %q, want %q", tt.maxScanBytes, tt.skipLarger, holders, tt.want)
+		}
+	}
+}
+
+func TestScanDirectoryFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":        "// Copyright 2024 Acme Corp.\n",
+		"long.min.js": "// Copyright 2024 Bundle Inc.\n" + strings.Repeat("a", 2<<20) + "\n",
+		"z.go":        "// Copyright 2024 Zeta Ltd.\n",
+	})
+
+	s := NewScanner()
+	s.InlineLicenses = true
+	result, err := s.ScanDirectory(dir)
+
+	var fileErrors FileErrors
+	if !errors.As(err, &fileErrors) {
+		t.Fatalf("expected FileErrors, got %v", err)
+	}
+	if len(fileErrors) != 1 || fileErrors[0].Path != filepath.Join(dir, "long.min.js") || fileErrors[0].Err == nil {
+		t.Errorf("FileErrors = %v, want the failure of long.min.js", fileErrors)
+	}
+	for _, holder := range []string{"Acme Corp.", "Bundle Inc.", "Zeta Ltd."} {
+		if !strings.Contains(result, holder) {
+			t.Errorf("report lacks %q despite the failed file:\n%s", holder, result)
+		}
+	}
+
+	entries, err := s.ScanDirectoryStructured(dir)
+	if !errors.As(err, &fileErrors) || len(entries) != 3 {
+		t.Errorf("ScanDirectoryStructured() = %d entries, %v; want 3 entries and FileErrors", len(entries), err)
+	}
+}
+
+func TestScanDirectoryStrictErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":        "// Copyright 2024 Acme Corp.\n",
+		"long.min.js": "// Copyright 2024 Bundle Inc.\n" + strings.Repeat("a", 2<<20) + "\n",
+	})
+
+	s := NewScanner()
+	s.InlineLicenses = true
+	s.StrictErrors = true
+	result, err := s.ScanDirectory(dir)
+
+	var fileError FileError
+	if !errors.As(err, &fileError) || errors.As(err, new(FileErrors)) {
+		t.Fatalf("expected a single FileError, got %v", err)
+	}
+	if fileError.Path != filepath.Join(dir, "long.min.js") || result != "" {
+		t.Errorf("ScanDirectory() = %q, %v; want no report and the failure of long.min.js", result, err)
+	}
+}
+
+func TestScanDirectoryUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions don't apply to root")
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":        "// Copyright 2024 Acme Corp.\n",
+		"locked/b.go": "// Copyright 2024 Locked Inc.\n",
+		"secret.go":   "// Copyright 2024 Secret Ltd.\n",
+		"z/zeta.go":   "// Copyright 2024 Zeta Ltd.\n",
+	})
+	for _, path := range []string{"locked", "secret.go"} {
+		path = filepath.Join(dir, path)
+		if err := os.Chmod(path, 0); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(path, 0755) })
+	}
+
+	s := NewScanner()
+	result, err := s.ScanDirectory(dir)
+	var fileErrors FileErrors
+	if !errors.As(err, &fileErrors) || len(fileErrors) != 2 {
+		t.Fatalf("expected FileErrors for the directory and the file, got %v", err)
+	}
+	if fileErrors[0].Path != filepath.Join(dir, "locked") || fileErrors[1].Path != filepath.Join(dir, "secret.go") {
+		t.Errorf("FileErrors = %v, want locked and secret.go", fileErrors)
+	}
+	if want := "Copyright 2024 Acme Corp.\nCopyright 2024 Zeta Ltd.\n"; result != want {
+		t.Errorf("ScanDirectory() = %q, want %q", result, want)
+	}
+
+	s.StrictErrors = true
+	if _, err := s.ScanDirectory(dir); err == nil || errors.As(err, &fileErrors) {
+		t.Errorf("expected the locked directory to abort a strict scan, got %v", err)
+	}
+}
+
+func TestScanDirectoryFunc(t *testing.T) {
+	dir := t.TempDir()
+	files := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("file%02d.go", i)] = fmt.Sprintf("// Copyright 2024 Holder %d Inc.\n\n// Copyright 2024 Acme Corp.\n", i)
+	}
+	writeFiles(t, dir, files)
+
+	s := NewScanner()
+	s.Concurrency = 4
+	var got []string
+	err := s.ScanDirectoryFunc(dir, func(entry CopyrightEntry) error {
+		got = append(got, entry.RawText)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Copyright 2024 Holder 0 Inc.", "Copyright 2024 Acme Corp."}
+	for i := 1; i < 20; i++ {
+		want = append(want, fmt.Sprintf("Copyright 2024 Holder %d Inc.", i))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanDirectoryFunc() entries = %q, want %q", got, want)
+	}
+
+	// StopScan ends the scan without an error
+	got = nil
+	err = s.ScanDirectoryFunc(dir, func(entry CopyrightEntry) error {
+		got = append(got, entry.RawText)
+		if len(got) == 3 {
+			return StopScan
+		}
+		return nil
+	})
+	if err != nil || len(got) != 3 {
+		t.Errorf("after StopScan got %d entries and %v, want 3 entries and no error", len(got), err)
+	}
+
+	// Other errors are returned
+	failed := errors.New("socket closed")
+	err = s.ScanDirectoryFunc(dir, func(entry CopyrightEntry) error {
+		return failed
+	})
+	if err != failed {
+		t.Errorf("expected the callback error, got %v", err)
+	}
+}
+
+func TestScanDirectoryEmails(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go": "// Copyright (c) 2024 Jane Doe <jane@example.com>\n",
+		"b.go": "// Copyright (c) 2024 Jane Doe\n",
+		"c.go": "// Copyright (c) 2024 John Roe, john@example.org. All rights reserved.\n",
+	})
+
+	s := NewScanner()
+	entries, err := s.ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Holder+"|"+entry.Email)
+	}
+	want := []string{"Jane Doe|jane@example.com", "Jane Doe|", "John Roe|john@example.org"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("holders and emails = %q, want %q", got, want)
+	}
+
+	report, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantReport := "Copyright (c) 2024 Jane Doe <jane@example.com>\nCopyright (c) 2024 John Roe, john@example.org. All rights reserved.\n"
+	if report != wantReport {
+		t.Errorf("ScanDirectory() = %q, want %q", report, wantReport)
+	}
+}
+
+func TestScanDirectoryDedupAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go": "// copyright acme inc\n",
+		"b.go": "// Copyright Acme, Inc.\n",
+		"c.go": "// Copyright 2024 Acme, Inc.\n",
+	})
+
+	result, err := NewScanner().ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "Copyright Acme, Inc.\nCopyright 2024 Acme, Inc.\n") {
+		t.Errorf("expected one statement per year in its nicest form, got:\n%s", result)
+	}
+	if strings.Contains(result, "copyright acme inc") {
+		t.Errorf("expected the lowercase variant to be merged:\n%s", result)
+	}
+}
+
+func TestScanDirectorySortOutput(t *testing.T) {
+	files := map[string]string{
+		"LICENSE":       "MIT License\n\nCopyright (c) 2024 Zeta Ltd.\n",
+		"a.go":          "// Copyright 2024 Zeta Ltd.\n",
+		"b.go":          "// copyright 2020 beta labs\n",
+		"c.go":          "// Copyright 2021 Alpha Corp.\n",
+		"vendor/x/x.go": "// Copyright 2019 Omega GmbH\n",
+		"vendor/y/y.go": "// Copyright 2018 Delta Inc.\n",
+	}
+	// The same statements in files arranged the other way around
+	rearranged := map[string]string{
+		"LICENSE":       files["LICENSE"],
+		"a.go":          files["c.go"],
+		"b.go":          files["b.go"],
+		"c.go":          files["a.go"],
+		"vendor/x/x.go": files["vendor/y/y.go"],
+		"vendor/y/y.go": files["vendor/x/x.go"],
+	}
+
+	scan := func(files map[string]string) string {
+		t.Helper()
+		dir := t.TempDir()
+		writeFiles(t, dir, files)
+		s := NewScanner()
+		s.SortOutput = true
+		s.ThirdPartyDirs = []string{"vendor"}
+		result, err := s.ScanDirectory(dir)
+		if err != nil {
+			t.Fatalf("ScanDirectory failed: %v", err)
+		}
+		return result
+	}
+
+	result := scan(files)
+	// Whole lines are compared, so "(c)" sorts before the years
+	want := "Copyright (c) 2024 Zeta Ltd.\ncopyright 2020 beta labs\nCopyright 2021 Alpha Corp.\n" +
+		"\nThird-Party Copyrights:\n----------------------------------------\n\n" +
+		"Copyright 2018 Delta Inc.\nCopyright 2019 Omega GmbH\n"
+	if !strings.HasPrefix(result, want) {
+		t.Errorf("expected sorted copyrights, got:\n%s", result)
+	}
+	if !strings.HasSuffix(result, "\nLicense Text:\n----------------------------------------\n\nLICENSE:\n\n"+files["LICENSE"]) {
+		t.Errorf("expected the license text at the end, got:\n%s", result)
+	}
+	if other := scan(rearranged); other != result {
+		t.Errorf("rearranged files changed the report:\n%s\nwant:\n%s", other, result)
+	}
+}
+
+func TestProgressFunc(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":     "// Copyright 2024 Acme Corp.\n",
+		"b/c.go":   "package b\n",
+		"b/d.txt":  "Copyright 2023 Jane Doe\n",
+		"e/f/g.go": "package f\n",
+	})
+
+	s := NewScanner()
+	s.Concurrency = 3
+	var got []string
+	s.ProgressFunc = func(scanned, total int, currentPath string) {
+		rel, _ := filepath.Rel(dir, currentPath)
+		got = append(got, fmt.Sprintf("%d/%d %s", scanned, total, filepath.ToSlash(rel)))
+	}
+	if _, err := s.ScanDirectory(dir); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	want := []string{"1/4 a.go", "2/4 b/c.go", "3/4 b/d.txt", "4/4 e/f/g.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("progress = %v, want %v", got, want)
+	}
+}
+
+func TestEntriesScanned(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"alpha/main.go": "// Copyright 2024 Acme Corp.\n",
+		"beta/main.go":  "// Copyright 2023 Example Inc.\n",
+	})
+
+	var mu sync.Mutex
+	var batches [][]CopyrightEntry
+	s := NewScanner()
+	s.NoTemplate = true
+	s.ParallelSubDirectories = 2
+	s.EntriesScanned = func(entries []CopyrightEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, entries)
+	}
+
+	// The text reports of each subdirectory come with their entries
+	if err := s.ScanSubDirectories(root, filepath.Join(t.TempDir(), "copyright_{name}.txt")); err != nil {
+		t.Fatalf("ScanSubDirectories failed: %v", err)
+	}
+	var holders []string
+	for _, batch := range batches {
+		for _, entry := range batch {
+			holders = append(holders, entry.Holder)
+		}
+	}
+	sort.Strings(holders)
+	if len(batches) != 2 || !reflect.DeepEqual(holders, []string{"Acme Corp", "Example Inc"}) {
+		t.Errorf("got %d batches with holders %q, want one per subdirectory", len(batches), holders)
+	}
+
+	// A file list is one batch
+	batches = nil
+	paths := []string{filepath.Join(root, "alpha", "main.go"), filepath.Join(root, "beta", "main.go")}
+	entries, err := s.ScanFilesStructured(paths)
+	if err != nil {
+		t.Fatalf("ScanFilesStructured failed: %v", err)
+	}
+	if len(batches) != 1 || !reflect.DeepEqual(batches[0], entries) {
+		t.Errorf("EntriesScanned got %v, want the %d scanned entries once", batches, len(entries))
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// excluded checks if a path relative to the scanned directory, with forward
+// slashes, matches one of the ExcludePatterns or is one of the ExcludeDirs
+func (s *Scanner) excluded(relPath string, isDir bool) bool {
+	if isDir {
+		name := path.Base(relPath)
+		for _, dir := range s.ExcludeDirs {
+			if name == dir {
+				return true
+			}
+		}
+	}
+	for _, pattern := range s.ExcludePatterns {
+		if matched, _ := filepath.Match(filepath.FromSlash(pattern), filepath.FromSlash(relPath)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// includedExtension checks if a file has one of the IncludeExtensions, or if
+// the list is empty. A gzip-compressed file also matches by the extension of
+// the file it holds, as "legacy.go.gz" does for ".go"
+func (s *Scanner) includedExtension(name string) bool {
+	if len(s.IncludeExtensions) == 0 {
+		return true
+	}
+	ext := strings.ToLower(path.Ext(name))
+	innerExt := ""
+	if ext == ".gz" {
+		innerExt = strings.ToLower(path.Ext(strings.TrimSuffix(name, path.Ext(name))))
+	}
+	for _, included := range s.IncludeExtensions {
+		included = strings.ToLower(included)
+		if !strings.HasPrefix(included, ".") {
+			included = "." + included
+		}
+		if included == ext || (innerExt != "" && included == innerExt) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestScanDirectoryExclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go":                "// Copyright 2024 Acme Corp.\n",
+		"app.min.js":             "// Copyright 2024 Bundle Inc.\n",
+		"web/app.min.js":         "// Copyright 2024 Nested Bundle Inc.\n",
+		"dist/out.js":            "// Copyright 2024 Dist Inc.\n",
+		"pkg/testdata/sample.go": "// Copyright 2024 Fixture Inc.\n",
+		"pkg/lib.go":             "// Copyright 2024 Library Inc.\n",
+	})
+
+	tests := []struct {
+		patterns []string
+		dirs     []string
+		want     []string
+	}{
+		{nil, nil, []string{"Bundle Inc", "Dist Inc", "Acme Corp", "Library Inc", "Fixture Inc", "Nested Bundle Inc"}},
+		{[]string{"*.min.js"}, nil, []string{"Dist Inc", "Acme Corp", "Library Inc", "Fixture Inc", "Nested Bundle Inc"}},
+		{[]string{"*/*.min.js", "dist"}, nil, []string{"Bundle Inc", "Acme Corp", "Library Inc", "Fixture Inc"}},
+		{nil, []string{"testdata", "dist"}, []string{"Bundle Inc", "Acme Corp", "Library Inc", "Nested Bundle Inc"}},
+	}
+	for _, tt := range tests {
+		s := NewScanner()
+		s.ExcludePatterns = tt.patterns
+		s.ExcludeDirs = tt.dirs
+		entries, err := s.ScanDirectoryStructured(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var holders []string
+		for _, entry := range entries {
+			holders = append(holders, entry.Holder)
+		}
+		if !reflect.DeepEqual(holders, tt.want) {
+			t.Errorf("patterns %q dirs %q: holders = %q, want %q", tt.patterns, tt.dirs, holders, tt.want)
+		}
+	}
+}
+
+func TestScanDirectoryIncludeExtensions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go":      "// Copyright 2024 Acme Corp.\n",
+		"lib/util.C":   "// Copyright 2023 Upper Case Ltd.\n",
+		"lib/util.h":   "// Copyright 2022 Header Inc.\n",
+		"README.md":    "Copyright 2024 Docs Team\n",
+		"config.yaml":  "# Copyright 2024 Config Inc.\n",
+		"blob.go":      "Copyright\x00\x00\x00\x00\x00\x00\x00\x00",
+		"Makefile":     "# Copyright 2024 Build Inc.\n",
+		"legacy.go.gz": gzipData(t, "// Copyright 2010 Legacy Inc.\n"),
+		"notes.txt.gz": gzipData(t, "Copyright 2011 Notes Inc.\n"),
+	})
+
+	tests := []struct {
+		exts []string
+		want []string
+	}{
+		{[]string{".go", ".c"}, []string{"Upper Case Ltd", "Acme Corp", "Legacy Inc"}},
+		{[]string{"h", ".GO"}, []string{"Header Inc", "Acme Corp", "Legacy Inc"}},
+		{[]string{".gz"}, []string{"Legacy Inc", "Notes Inc"}},
+	}
+	for _, tt := range tests {
+		s := NewScanner()
+		s.IncludeExtensions = tt.exts
+		entries, err := s.ScanDirectoryStructured(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var holders []string
+		for _, entry := range entries {
+			holders = append(holders, entry.Holder)
+		}
+		sort.Strings(holders)
+		want := append([]string(nil), tt.want...)
+		sort.Strings(want)
+		if !reflect.DeepEqual(holders, want) {
+			t.Errorf("extensions %q: holders = %q, want %q", tt.exts, holders, want)
+		}
+	}
+
+	// File lists are filtered too
+	s := NewScanner()
+	s.IncludeExtensions = []string{".go"}
+	entries, err := s.ScanFilesStructured([]string{filepath.Join(dir, "main.go"), filepath.Join(dir, "README.md")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Holder != "Acme Corp" {
+		t.Errorf("ScanFilesStructured() = %+v, want only the statement of main.go", entries)
+	}
+}
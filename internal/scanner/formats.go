@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Report formats written by the scanner
+const (
+	FormatText      = "text"
+	FormatJSON      = "json"
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// IsStructuredFormat checks if reports in format are built from copyright
+// entries rather than from the text report
+func IsStructuredFormat(format string) bool {
+	return format == FormatJSON || format == FormatSPDX || format == FormatCycloneDX
+}
+
+// WriteEntriesReport writes the entries of the named software to outputFile in
+// OutputFormat, which must be a structured format, and calls OutputWritten
+func (s *Scanner) WriteEntriesReport(outputFile, name string, entries []CopyrightEntry) (string, error) {
+	return s.WriteCheckedEntriesReport(outputFile, name, entries, nil)
+}
+
+// WriteCheckedEntriesReport writes the entries like WriteEntriesReport, with
+// the license compatibility verdict of the scanned files in JSON reports if
+// it isn't nil
+func (s *Scanner) WriteCheckedEntriesReport(outputFile, name string, entries []CopyrightEntry, compatibility *LicenseCompatibility) (string, error) {
+	report, err := s.CheckedEntriesReport(name, entries, compatibility)
+	if err != nil {
+		return "", err
+	}
+	return s.writeOutput(outputFile, name, report)
+}
+
+// EntriesReport returns the report WriteEntriesReport writes, e.g. to print it
+func (s *Scanner) EntriesReport(name string, entries []CopyrightEntry) ([]byte, error) {
+	return s.CheckedEntriesReport(name, entries, nil)
+}
+
+// CheckedEntriesReport returns the report WriteCheckedEntriesReport writes
+func (s *Scanner) CheckedEntriesReport(name string, entries []CopyrightEntry, compatibility *LicenseCompatibility) ([]byte, error) {
+	var report bytes.Buffer
+	switch s.OutputFormat {
+	case FormatJSON:
+		jsonReport := NewJSONReport(name, entries)
+		if s.JSONFiles {
+			jsonReport.groupByFile()
+		}
+		jsonReport.Compatibility = compatibility
+		data, err := marshalJSONReport(jsonReport, s.CompactJSON)
+		if err != nil {
+			return nil, err
+		}
+		report.Write(data)
+	case FormatSPDX:
+		if err := WriteSPDX(&report, name, entries); err != nil {
+			return nil, err
+		}
+	case FormatCycloneDX:
+		if err := WriteCycloneDX(&report, name, entries); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", s.OutputFormat)
+	}
+	return report.Bytes(), nil
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ScanFS scans the directory dir of fsys like ScanDirectory, e.g. an
+// embedded, in-memory or zip filesystem. dir is a slash-separated path in
+// fsys, "." for its root, and prefixes the files named in FileErrors and
+// passed to FileScanned. Symlinks are only followed if fsys resolves them
+func (s *Scanner) ScanFS(fsys fs.FS, dir string) (string, error) {
+	return s.ScanFSContext(context.Background(), fsys, dir)
+}
+
+// ScanFSContext scans the directory dir of fsys like ScanFS, aborting with
+// ctx.Err() as soon as ctx is cancelled or its deadline passes
+func (s *Scanner) ScanFSContext(ctx context.Context, fsys fs.FS, dir string) (string, error) {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return "", fmt.Errorf("Error scanning directory %s: %v", dir, err)
+	}
+	report, _, err := s.scanDirectoryReport(ctx, sub, filepath.FromSlash(dir), filepath.FromSlash(dir))
+	return report, err
+}
+
+// ScanFSStructured scans the directory dir of fsys like ScanFS, returning
+// every copyright statement as an entry like ScanDirectoryStructured
+func (s *Scanner) ScanFSStructured(fsys fs.FS, dir string) ([]CopyrightEntry, error) {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("Error scanning directory %s: %v", dir, err)
+	}
+	result, err := s.scanDirectoryEntries(context.Background(), sub, filepath.FromSlash(dir), filepath.FromSlash(dir))
+	if err != nil {
+		return nil, err
+	}
+	return result.entries, result.err()
+}
+
+// osFile returns the directory of a path of the OS filesystem as an fs.FS
+// and the name of the file in it, so the file is read like one of a scanned
+// filesystem
+func osFile(path string) (fs.FS, string) {
+	return os.DirFS(filepath.Dir(path)), filepath.Base(path)
+}
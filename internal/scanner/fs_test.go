@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"archive/zip"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"project/LICENSE":           {Data: []byte("MIT License\n\nCopyright (c) 2024 Acme Corp.\n")},
+		"project/.gitignore":        {Data: []byte("build/\n")},
+		"project/main.go":           {Data: []byte("// Copyright 2024 Acme Corp.\n// SPDX-License-Identifier: MIT\npackage main\n")},
+		"project/lib/util.go":       {Data: []byte("// Copyright (c) 2021 Jane Doe\npackage lib\n")},
+		"project/build/gen.go":      {Data: []byte("// Copyright 2020 Generated Inc.\n")},
+		"project/assets/logo.bin":   {Data: []byte("Copyright\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")},
+		"project/vendor/dep/dep.go": {Data: []byte("// Copyright 2019 Example Inc.\n")},
+		"other/ignored.go":          {Data: []byte("// Copyright 2018 Other Ltd.\n")},
+	}
+
+	var sources []string
+	s := NewScanner()
+	s.RespectGitignore = true
+	s.ThirdPartyDirs = []string{"vendor"}
+	s.FileScanned = func(path string, copyrights []string) {
+		sources = append(sources, path)
+	}
+	got, err := s.ScanFS(fsys, "project")
+	if err != nil {
+		t.Fatalf("ScanFS failed: %v", err)
+	}
+
+	want := "Copyright (c) 2024 Acme Corp.\nCopyright (c) 2021 Jane Doe\n" +
+		"\nThird-Party Copyrights:\n----------------------------------------\n\nCopyright 2019 Example Inc.\n" +
+		"\nDetected Licenses:\n----------------------------------------\n\nMIT\n" +
+		"\nLicenses Found:\n----------------------------------------\n\nMIT License (unrecognized, sha256:ef8c917eceb5)\n  LICENSE\n" +
+		"\nLicense Text:\n----------------------------------------\n\nLICENSE:\n\nMIT License\n\nCopyright (c) 2024 Acme Corp.\n"
+	if got != want {
+		t.Errorf("ScanFS() = %q, want %q", got, want)
+	}
+	wantSources := []string{
+		filepath.Join("project", ".gitignore"),
+		filepath.Join("project", "LICENSE"),
+		filepath.Join("project", "lib", "util.go"),
+		filepath.Join("project", "main.go"),
+		filepath.Join("project", "vendor", "dep", "dep.go"),
+	}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("FileScanned sources = %q, want %q", sources, wantSources)
+	}
+
+	entries, err := s.ScanFSStructured(fsys, "project/lib")
+	if err != nil {
+		t.Fatalf("ScanFSStructured failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].SourceFile != filepath.Join("project", "lib", "util.go") || entries[0].Holder != "Jane Doe" {
+		t.Errorf("ScanFSStructured() = %+v, want the statement of util.go", entries)
+	}
+
+	if _, err := s.ScanFS(fsys, "missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+	if _, err := s.ScanFS(fsys, "../project"); err == nil {
+		t.Error("expected an error for an invalid path")
+	}
+}
+
+func TestScanFSZip(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{
+		"src/a.go": "// Copyright 2023 Zipped Corp.\n",
+		"src/b.go": "// Copyright 2024 Zipped Corp.\n",
+	})
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	s := NewScanner()
+	s.MergeYears = true
+	got, err := s.ScanFS(reader, ".")
+	if err != nil {
+		t.Fatalf("ScanFS failed: %v", err)
+	}
+	if want := "Copyright 2023-2024 Zipped Corp.\n"; got != want {
+		t.Errorf("ScanFS() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGitignoreIgnored(t *testing.T) {
+	rules, err := parseGitignore(strings.NewReader(`# build output
+node_modules/
+*.log
+!keep.log
+/dist
+docs/**/generated
+build/**
+\#notes
+`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := parseGitignore(strings.NewReader("*.tmp\n"), "pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignore := gitignore{rules: append(rules, nested...)}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"node_modules", true, true},
+		{"web/node_modules", true, true},
+		{"node_modules", false, false},
+		{"server.log", false, true},
+		{"logs/app.log", false, true},
+		{"keep.log", false, false},
+		{"dist", true, true},
+		{"src/dist", true, false},
+		{"docs/generated", true, true},
+		{"docs/api/v1/generated", true, true},
+		{"build/out/main.o", false, true},
+		{"#notes", false, true},
+		{"pkg/cache.tmp", false, true},
+		{"cache.tmp", false, false},
+		{".git", true, true},
+		{"main.go", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := ignore.ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestScanDirectoryRespectGitignore(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".gitignore":                   "node_modules/\n*.gen.go\n",
+		"main.go":                      "// Copyright 2024 Acme Corp.\npackage main\n",
+		"api.gen.go":                   "// Copyright 2024 Generator Inc.\npackage main\n",
+		"node_modules/lib/index.js":    "// Copyright 2024 Npm Author Ltd.\n",
+		"pkg/.gitignore":               "fixtures/\n",
+		"pkg/fixtures/sample.txt":      "Copyright 2024 Fixture Corp.\n",
+		"pkg/lib.go":                   "// Copyright 2024 Lib Corp.\npackage pkg\n",
+		".git/hooks/pre-commit.sample": "# Copyright 2024 Git Hook Corp.\n",
+	})
+
+	s := NewScanner()
+	s.RespectGitignore = true
+	result, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Copyright 2024 Acme Corp.\nCopyright 2024 Lib Corp.\n"
+	if result != want {
+		t.Errorf("ScanDirectory() = %q, want %q", result, want)
+	}
+}
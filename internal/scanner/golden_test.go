@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// update rewrites the golden files from the current extraction output
+var update = flag.Bool("update", false, "update golden files")
+
+// fixtureFiles returns the text fixtures in testdata
+func fixtureFiles(t testing.TB) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			fixtures = append(fixtures, filepath.Join("testdata", entry.Name()))
+		}
+	}
+	return fixtures
+}
+
+func TestExtractCopyrightGolden(t *testing.T) {
+	s := NewScanner()
+	for _, fixture := range fixtureFiles(t) {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			got, err := s.extractCopyright(fixture)
+			if err != nil {
+				t.Fatalf("extractCopyright failed: %v", err)
+			}
+
+			golden := filepath.Join("testdata", "golden", filepath.Base(fixture)+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file, run with -update: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("extractCopyright() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// classifyLineReference is the original substring-by-substring classification
+// that ClassifyLine must stay equivalent to
+func classifyLineReference(line string) LineKind {
+	lowercaseLine := strings.ToLower(strings.TrimSpace(norm.NFKC.String(line)))
+	containsAny := func(substrings []string) bool {
+		for _, substring := range substrings {
+			if strings.Contains(lowercaseLine, substring) {
+				return true
+			}
+		}
+		return false
+	}
+	containsWord := func(words []string) bool {
+		for _, word := range words {
+			for i := 0; i+len(word) <= len(lowercaseLine); i++ {
+				if strings.HasPrefix(lowercaseLine[i:], word) && isWholeWord(lowercaseLine, i, i+len(word)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	hasMarker := containsAny(copyrightMarkers) || strings.Contains(lowercaseLine, "copr")
+
+	switch {
+	case spdxCopyrightPattern.MatchString(lowercaseLine):
+		return LineCopyright
+	case !hasMarker && containsWord(codeKeywords), hasMarker && containsWord(codeSyntaxKeywords):
+		return LineCode
+	case !hasMarker && containsAny(licenseKeywords), hasMarker && containsWord(licenseKeywords):
+		return LineLicenseBoilerplate
+	case (containsAny(copyrightMarkers) || coprPattern.MatchString(lowercaseLine)) &&
+		!containsAny(copyrightExclusions) && !isCopyrightProse(lowercaseLine):
+		return LineCopyright
+	}
+	return LineOther
+}
+
+// benchmarkLines returns the lines of the fixtures and the package sources
+func benchmarkLines(t testing.TB) []string {
+	t.Helper()
+
+	sources, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, path := range append(fixtureFiles(t), sources...) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, strings.Split(string(content), "\n")...)
+	}
+	return lines
+}
+
+func TestClassifyLineMatchesReference(t *testing.T) {
+	for _, line := range benchmarkLines(t) {
+		if got, want := ClassifyLine(line), classifyLineReference(line); got != want {
+			t.Errorf("ClassifyLine(%q) = %v, reference gives %v", line, got, want)
+		}
+	}
+}
+
+func BenchmarkClassifyLine(b *testing.B) {
+	lines := benchmarkLines(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			ClassifyLine(line)
+		}
+	}
+}
+
+func BenchmarkClassifyLineReference(b *testing.B) {
+	lines := benchmarkLines(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			classifyLineReference(line)
+		}
+	}
+}
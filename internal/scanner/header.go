@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// headerParser describes the comment syntax of a file type to the copyright
+// extraction, whose statements end at the bounds of comment blocks
+type headerParser struct {
+	// shebang skips a "#!" interpreter line at the top of the file
+	shebang bool
+	// bounds finds the delimiters of comment blocks in a line, see
+	// blockCommentBounds
+	bounds func(line string, inBlock bool) (opens, closes, inBlockAfter bool)
+	// strip, if set, removes the delimiters found by bounds from a line
+	strip func(line string) string
+}
+
+// defaultHeaderParser handles the C block comments most languages use
+var defaultHeaderParser = headerParser{bounds: blockCommentBounds}
+
+// pythonHeaderParser reads the docstrings of Python files as comment blocks
+var pythonHeaderParser = headerParser{shebang: true, bounds: docstringBounds, strip: stripDocstringQuotes}
+
+// shellHeaderParser skips the shebang line of shell scripts
+var shellHeaderParser = headerParser{shebang: true, bounds: blockCommentBounds}
+
+// headerParsers maps lowercase file extensions to the header parser of their
+// language, other files use defaultHeaderParser
+var headerParsers = map[string]headerParser{
+	".py": pythonHeaderParser, ".pyw": pythonHeaderParser, ".pyi": pythonHeaderParser,
+	".sh": shellHeaderParser, ".bash": shellHeaderParser, ".zsh": shellHeaderParser,
+	".ksh": shellHeaderParser, ".fish": shellHeaderParser,
+}
+
+// headerParserFor returns the header parser of a file name. A gzip-compressed
+// file is parsed like the file it holds
+func headerParserFor(name string) headerParser {
+	name = strings.ToLower(name)
+	if path.Ext(name) == ".gz" {
+		name = strings.TrimSuffix(name, ".gz")
+	}
+	if parser, ok := headerParsers[path.Ext(name)]; ok {
+		return parser
+	}
+	return defaultHeaderParser
+}
+
+// isShebang checks if a line is the interpreter line of a script
+func (p headerParser) isShebang(line string, lineNumber int) bool {
+	return p.shebang && lineNumber == 1 && strings.HasPrefix(line, "#!")
+}
+
+// docstringDelimiterPattern matches the triple quotes around Python
+// docstrings, with the string prefix of raw and unicode strings
+var docstringDelimiterPattern = regexp.MustCompile(`(?:\b[rRuU])?("""|''')`)
+
+// docstringBounds finds the triple quotes of Python docstrings in a line like
+// blockCommentBounds finds C comments
+func docstringBounds(line string, inBlock bool) (opens, closes, inBlockAfter bool) {
+	for range docstringDelimiterPattern.FindAllString(line, -1) {
+		if inBlock {
+			closes = true
+		} else {
+			opens = true
+		}
+		inBlock = !inBlock
+	}
+	return opens, closes, inBlock
+}
+
+// stripDocstringQuotes removes the triple quotes of Python docstrings from a line
+func stripDocstringQuotes(line string) string {
+	return strings.TrimSpace(docstringDelimiterPattern.ReplaceAllString(line, " "))
+}
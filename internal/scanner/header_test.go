@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeaderParserFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		shebang bool
+		strip   bool
+	}{
+		{"tool.py", true, true},
+		{"TYPES.PYI", true, true},
+		{"tool.py.gz", true, true},
+		{"build.sh", true, false},
+		{"setup.bash", true, false},
+		{"main.c", false, false},
+		{"Makefile", false, false},
+	}
+
+	for _, tt := range tests {
+		parser := headerParserFor(tt.name)
+		if parser.shebang != tt.shebang || (parser.strip != nil) != tt.strip {
+			t.Errorf("headerParserFor(%q) = shebang %v, strip %v, want %v, %v",
+				tt.name, parser.shebang, parser.strip != nil, tt.shebang, tt.strip)
+		}
+	}
+}
+
+func TestDocstringBounds(t *testing.T) {
+	tests := []struct {
+		line                        string
+		inBlock                     bool
+		opens, closes, inBlockAfter bool
+	}{
+		{`"""`, false, true, false, true},
+		{"Copyright 2025 Acme Corp", true, false, false, true},
+		{`"""`, true, false, true, false},
+		{`"""Copyright 2023 Jane Doe"""`, false, true, true, false},
+		{`'''Copyright 2023 Jane Doe`, false, true, false, true},
+		{`r"""Raw docstring`, false, true, false, true},
+		{`x = "quoted"`, false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		opens, closes, inBlockAfter := docstringBounds(tt.line, tt.inBlock)
+		if opens != tt.opens || closes != tt.closes || inBlockAfter != tt.inBlockAfter {
+			t.Errorf("docstringBounds(%q, %v) = %v, %v, %v, want %v, %v, %v",
+				tt.line, tt.inBlock, opens, closes, inBlockAfter, tt.opens, tt.closes, tt.inBlockAfter)
+		}
+	}
+}
+
+func TestStripDocstringQuotes(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`"""Copyright 2023 Jane Doe"""`, "Copyright 2023 Jane Doe"},
+		{`Copyright 2024 Acme Corp. All rights reserved. """`, "Copyright 2024 Acme Corp. All rights reserved."},
+		{`u'''Copyright 2021 Example Ltd`, "Copyright 2021 Example Ltd"},
+		{"Copyright 2020 Bob", "Copyright 2020 Bob"},
+	}
+
+	for _, tt := range tests {
+		if got := stripDocstringQuotes(tt.line); got != tt.want {
+			t.Errorf("stripDocstringQuotes(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestScanReaderStructuredHeaderParser(t *testing.T) {
+	const source = "#!/usr/bin/env python3\n\"\"\"Copyright 2024 Acme Corp.\n\nModule docs.\n\"\"\"\n"
+	s := &Scanner{}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		// The docstring quotes are stripped for Python files only
+		{"tool.py", "Copyright 2024 Acme Corp."},
+		{"tool.txt", `"""Copyright 2024 Acme Corp.`},
+	}
+
+	for _, tt := range tests {
+		entries, err := s.ScanReaderStructured(strings.NewReader(source), tt.name)
+		if err != nil {
+			t.Fatalf("ScanReaderStructured(%s) failed: %v", tt.name, err)
+		}
+		if len(entries) != 1 || entries[0].RawText != tt.want {
+			t.Errorf("ScanReaderStructured(%s) = %+v, want one entry %q", tt.name, entries, tt.want)
+		}
+	}
+}
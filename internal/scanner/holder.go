@@ -0,0 +1,347 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// individualPlaceholder replaces holders that look like personal names
+const individualPlaceholder = "Individual Contributor"
+
+// copyrightLeadPattern matches the copyright marker, years and "by" preceding the holder
+var copyrightLeadPattern = regexp.MustCompile(`(?i)^(\s*(copyright|\(copr\)|copr\b\.?|\(c\)|©|[0-9]{4}|by\b|[-,–]|\s)\s*)+`)
+
+// copyrightMarkerPattern checks that a statement actually starts with a copyright marker
+var copyrightMarkerPattern = regexp.MustCompile(`(?i)^\s*(copyright|\(copr\)|copr\b|\(c\)|©)`)
+
+// rightsReservedPattern matches the trailing rights reservation of a statement
+var rightsReservedPattern = regexp.MustCompile(`(?i)[.,;]?\s*all rights reserved\.?\s*$`)
+
+// emailPattern matches an email address, optionally wrapped in angle brackets
+var emailPattern = regexp.MustCompile(`<?[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}>?`)
+
+// holderEmailPattern matches an email address of a statement with the comma or
+// parentheses setting it off from the name
+var holderEmailPattern = regexp.MustCompile(`[\s,]*\(?` + emailPattern.String() + `\)?`)
+
+// splitEmail separates the first email address from a holder such as
+// "Jane Doe <jane@example.com>", returning the name and the bare address
+func splitEmail(holder string) (name, email string) {
+	email = strings.Trim(emailPattern.FindString(holder), "<>")
+	if email == "" {
+		return holder, ""
+	}
+	return withoutEmails(holder), email
+}
+
+// withoutEmails removes the email addresses from a statement or holder
+func withoutEmails(text string) string {
+	return strings.Join(strings.Fields(holderEmailPattern.ReplaceAllString(text, "")), " ")
+}
+
+// holderSeparatorPattern matches the separators of a holder list such as "Alice, Bob and Carol"
+var holderSeparatorPattern = regexp.MustCompile(`\s*,\s*and\s+|\s*,\s*|\s+and\s+|\s*&\s*`)
+
+// lastFirstPattern matches a single personal name written as "Last, First" or "Last, First M."
+var lastFirstPattern = regexp.MustCompile(`^\p{Lu}[\p{L}'\-]+,\s*\p{Lu}[\p{L}'\-]+(\s+\p{Lu}\.)?$`)
+
+// organizationWords name the kinds of organization without a legal entity
+// suffix, such as "Apache Software Foundation" or "The Go Authors"
+var organizationWords = map[string]bool{
+	"company": true, "foundation": true, "project": true, "authors": true,
+	"contributors": true, "developers": true, "team": true, "group": true,
+	"university": true, "institute": true, "organization": true,
+	"organisation": true, "association": true, "consortium": true,
+	"society": true, "affiliates": true,
+}
+
+// legalSuffixes are the legal entity suffixes that confirm a copyright statement
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "corp": true, "corporation": true,
+	"llc": true, "llp": true, "ltd": true, "limited": true, "gmbh": true,
+	"ag": true, "sa": true, "bv": true, "plc": true, "co": true,
+	"kg": true, "oy": true, "ab": true, "srl": true, "pty": true,
+}
+
+// DefaultRightsPhrases are the phrases confirming a copyright statement when RequireRightsPhrase is set
+var DefaultRightsPhrases = []string{
+	"all rights reserved",
+	"some rights reserved",
+	"alle rechte vorbehalten",
+	"tous droits réservés",
+	"todos los derechos reservados",
+}
+
+// hasLegalSuffix checks if a statement contains a legal entity suffix
+func hasLegalSuffix(statement string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToLower(statement), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if legalSuffixes[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// isConfirmedCopyright checks if a statement contains a rights phrase, a year or a legal suffix
+func isConfirmedCopyright(statement string, rightsPhrases []string) bool {
+	lower := strings.ToLower(statement)
+	for _, phrase := range rightsPhrases {
+		if phrase != "" && strings.Contains(lower, strings.ToLower(phrase)) {
+			return true
+		}
+	}
+	return yearTokenPattern.MatchString(statement) || hasLegalSuffix(statement)
+}
+
+// splitHolder splits a cleaned copyright statement into the leading marker and
+// years, the holder itself and the trailing rights reservation
+func splitHolder(statement string) (lead, holder, trail string) {
+	lead = copyrightLeadPattern.FindString(statement)
+	rest := statement[len(lead):]
+	if loc := rightsReservedPattern.FindStringIndex(rest); loc != nil {
+		trail = rest[loc[0]:]
+		rest = rest[:loc[0]]
+	}
+	return lead, strings.TrimSpace(rest), trail
+}
+
+// isOrganizationHolder checks if a holder contains a legal suffix or a word
+// naming a kind of organization
+func isOrganizationHolder(holder string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToLower(holder), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if legalSuffixes[word] || organizationWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikePersonalName checks if a holder reads like "First Last"
+func looksLikePersonalName(holder string) bool {
+	words := strings.Fields(holder)
+	if len(words) < 2 || len(words) > 4 {
+		return false
+	}
+	for _, word := range words {
+		runes := []rune(word)
+		if !unicode.IsUpper(runes[0]) {
+			return false
+		}
+		for _, r := range runes[1:] {
+			if !unicode.IsLetter(r) && r != '.' && r != '-' && r != '\'' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isIndividualHolder heuristically classifies a single holder as an individual person
+func isIndividualHolder(holder string) bool {
+	if holder == "" || isOrganizationHolder(holder) {
+		return false
+	}
+	if emailPattern.MatchString(holder) {
+		return true
+	}
+	return looksLikePersonalName(holder) || lastFirstPattern.MatchString(holder)
+}
+
+// pseudonymLength is the number of hex digits of the keyed hash appended to
+// hashed placeholders
+const pseudonymLength = 16
+
+// anonymizeHolder returns the placeholder for an individual holder, followed
+// by a pseudonym keyed with key unless key is empty
+func anonymizeHolder(holder string, key []byte) string {
+	if len(key) == 0 {
+		return individualPlaceholder
+	}
+
+	// Hash the name without the email so the same person groups together
+	name := strings.ToLower(strings.TrimSpace(emailPattern.ReplaceAllString(holder, "")))
+	if name == "" {
+		name = strings.ToLower(holder)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	return individualPlaceholder + " " + hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+}
+
+// anonymizeCopyright replaces the holders of a statement that look like
+// individuals with a placeholder, leaving organization holders intact
+func anonymizeCopyright(statement string, key []byte) string {
+	if !copyrightMarkerPattern.MatchString(statement) {
+		return statement
+	}
+
+	lead, holder, trail := splitHolder(statement)
+	if holder == "" {
+		return statement
+	}
+
+	// A whole "Last, First" holder is one person, not a list of two
+	if isIndividualHolder(holder) {
+		return lead + anonymizeHolder(holder, key) + trail
+	}
+
+	// Classify each entry of a holder list, keeping the separators and
+	// company names such as "Red Hat, Inc." whole
+	changed := false
+	var result strings.Builder
+	rest := holder
+	for _, part := range splitHolderList(holder) {
+		i := strings.Index(rest, part)
+		result.WriteString(rest[:i])
+		rest = rest[i+len(part):]
+		if isIndividualHolder(part) {
+			part = anonymizeHolder(part, key)
+			changed = true
+		}
+		result.WriteString(part)
+	}
+	result.WriteString(rest)
+	if !changed {
+		return statement
+	}
+
+	return lead + result.String() + trail
+}
+
+// statementHolders returns the holders named by a copyright statement, a
+// holder list is split into its entries unless it names a single person
+func statementHolders(statement string) []string {
+	_, holder, _ := splitHolder(statement)
+	holder = strings.TrimRight(holder, " .,;")
+	if holder == "" {
+		return nil
+	}
+	if isIndividualHolder(holder) {
+		return []string{holder}
+	}
+
+	var holders []string
+	for _, part := range holderSeparatorPattern.Split(holder, -1) {
+		if part = strings.TrimRight(strings.TrimSpace(part), " .,;"); part != "" {
+			holders = append(holders, part)
+		}
+	}
+	return holders
+}
+
+// HolderMatches checks if a copyright statement names holder, ignoring case,
+// width and punctuation. The holder matches whole words of a holder of the
+// statement, so "Acme" matches "Copyright 2024 Acme Corp." but not "Acmeware"
+func HolderMatches(statement, holder string) bool {
+	want := holderKey(holder)
+	if want == "" {
+		return false
+	}
+	for _, name := range statementHolders(withoutEmails(statement)) {
+		if strings.Contains(" "+holderKey(name)+" ", " "+want+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// companySuffixes are the words that end a company name rather than name a
+// holder of their own, as in "Smith, Johnson & Co" or "Acme, Inc."
+var companySuffixes = map[string]bool{
+	"company": true, "sons": true, "brothers": true, "bros": true,
+	"partners": true, "associates": true, "affiliates": true,
+}
+
+// isCompanySuffix checks if a holder list entry only consists of legal or
+// company suffixes, such as "Inc." or "Co"
+func isCompanySuffix(part string) bool {
+	words := strings.FieldsFunc(strings.ToLower(part), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if !legalSuffixes[word] && !companySuffixes[word] {
+			return false
+		}
+	}
+	return len(words) > 0
+}
+
+// splitHolderList splits a holder list such as "Alice, Bob and Carol" into
+// its holders, keeping company names whole: a suffix after a comma belongs
+// to the name before it ("Acme, Inc."), a suffix after "and" or "&" ends a
+// firm name made of the whole list ("Smith, Johnson & Co"), two single words
+// joined by "&" are one firm ("Procter & Gamble") and entries starting in
+// lower case continue the previous one ("Acme and its affiliates")
+func splitHolderList(holder string) []string {
+	holder = strings.TrimRight(holder, " .,;")
+	if holder == "" {
+		return nil
+	}
+	if isIndividualHolder(withoutEmails(holder)) {
+		return []string{holder}
+	}
+
+	// Each holder is a span of the list, the entries are merged into the
+	// span before them or start their own
+	type span struct{ start, end int }
+	separators := holderSeparatorPattern.FindAllStringIndex(holder, -1)
+	spans := []span{{0, len(holder)}}
+	if len(separators) > 0 {
+		spans[0].end = separators[0][0]
+	}
+	for i, separator := range separators {
+		end := len(holder)
+		if i+1 < len(separators) {
+			end = separators[i+1][0]
+		}
+		part := holder[separator[1]:end]
+		last := &spans[len(spans)-1]
+		conjunction := strings.Trim(holder[separator[0]:separator[1]], " \t,")
+		switch first, _ := utf8.DecodeRuneInString(part); {
+		case part == "":
+		case isCompanySuffix(part) && conjunction != "":
+			spans = []span{{0, end}}
+		case isCompanySuffix(part), !unicode.IsUpper(first) && !unicode.IsDigit(first),
+			conjunction == "&" && !strings.Contains(part, " ") && !strings.Contains(holder[last.start:last.end], " "):
+			last.end = end
+		default:
+			spans = append(spans, span{separator[1], end})
+		}
+	}
+
+	var holders []string
+	for _, span := range spans {
+		if part := strings.TrimRight(holder[span.start:span.end], " .,;"); part != "" {
+			holders = append(holders, part)
+		}
+	}
+	return holders
+}
+
+// anonymizeLicenseText anonymizes the copyright lines of a license text,
+// keeping their indentation and leaving all other lines untouched
+func anonymizeLicenseText(text string, key []byte) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := line[:strings.Index(line, trimmed)]
+		lines[i] = indent + anonymizeCopyright(trimmed, key) + line[len(indent)+len(trimmed):]
+	}
+	return strings.Join(lines, "\n")
+}
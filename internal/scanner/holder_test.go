@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizeCopyright(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      string
+	}{
+		{"corporate suffix", "Copyright 2019-2023 Acme Corp. All rights reserved.", "Copyright 2019-2023 Acme Corp. All rights reserved."},
+		{"corporate with comma", "Copyright (C) 2000 Free Software Foundation, Inc.", "Copyright (C) 2000 Free Software Foundation, Inc."},
+		{"legal suffix", "Copyright 2020 Red Hat, Inc.", "Copyright 2020 Red Hat, Inc."},
+		{"organization word", "Copyright 2020 Apache Software Foundation", "Copyright 2020 Apache Software Foundation"},
+		{"university", "Copyright 2020 University of California", "Copyright 2020 University of California"},
+		{"company without suffix", "Copyright 2020 Acme Widgets", "Copyright 2020 Individual Contributor"},
+		{"company name list", "Copyright 2020 Smith, Johnson & Co", "Copyright 2020 Smith, Johnson & Co"},
+		{"project authors", "© 2020, 2021 The Go Authors", "© 2020, 2021 The Go Authors"},
+		{"first last", "Copyright (c) 2025 Clement Li. All rights reserved.", "Copyright (c) 2025 Individual Contributor. All rights reserved."},
+		{"name with email", "Copyright (c) 2024 Jane Doe <jane@example.com>", "Copyright (c) 2024 Individual Contributor"},
+		{"bare email", "Copyright 2020 bob@example.org", "Copyright 2020 Individual Contributor"},
+		{"by name", "Copyright 2020 by John Smith", "Copyright 2020 by Individual Contributor"},
+		{"last first", "Copyright 2020 Smith, John", "Copyright 2020 Individual Contributor"},
+		{"two people", "Copyright 2020 John Smith and Jane Doe", "Copyright 2020 Individual Contributor and Individual Contributor"},
+		{"people list", "Copyright 2024 Alice Liddell, Bob Marley & Carol King", "Copyright 2024 Individual Contributor, Individual Contributor & Individual Contributor"},
+		{"person and company", "Copyright 2020 Acme Corp. and John Smith", "Copyright 2020 Acme Corp. and Individual Contributor"},
+		{"no copyright marker", "John Smith", "John Smith"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := anonymizeCopyright(tt.statement, nil); got != tt.want {
+				t.Errorf("anonymizeCopyright(%q) = %q, want %q", tt.statement, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnonymizeCopyrightHashed(t *testing.T) {
+	key := []byte("test key")
+	withEmail := anonymizeCopyright("Copyright 2024 Jane Doe <jane@example.com>", key)
+	withoutEmail := anonymizeCopyright("Copyright 2021 Jane Doe", key)
+	other := anonymizeCopyright("Copyright 2021 John Smith", key)
+
+	hash := strings.TrimPrefix(withEmail, "Copyright 2024 "+individualPlaceholder+" ")
+	if len(hash) != pseudonymLength {
+		t.Fatalf("expected a %d character hash, got %q", pseudonymLength, withEmail)
+	}
+	if !strings.HasSuffix(withoutEmail, hash) {
+		t.Errorf("same person should hash identically: %q vs %q", withEmail, withoutEmail)
+	}
+	if strings.HasSuffix(other, hash) {
+		t.Errorf("different people should hash differently: %q vs %q", withEmail, other)
+	}
+	if again := anonymizeCopyright("Copyright 2024 Jane Doe <jane@example.com>", key); again != withEmail {
+		t.Errorf("hash is not stable: %q vs %q", withEmail, again)
+	}
+	if rekeyed := anonymizeCopyright("Copyright 2024 Jane Doe <jane@example.com>", []byte("other key")); rekeyed == withEmail {
+		t.Errorf("hash should depend on the key: %q", rekeyed)
+	}
+}
+
+func TestScannerPseudonymKey(t *testing.T) {
+	s := NewScanner()
+	if key := s.pseudonymKey(); key != nil {
+		t.Errorf("expected no key without HashPersonalNames, got %x", key)
+	}
+
+	s.HashPersonalNames = true
+	first := s.pseudonymKey()
+	if len(first) == 0 || !bytes.Equal(first, (&Scanner{HashPersonalNames: true}).pseudonymKey()) {
+		t.Errorf("expected one random key per run, got %x", first)
+	}
+
+	s.PseudonymKey = []byte("supplied")
+	if key := s.pseudonymKey(); string(key) != "supplied" {
+		t.Errorf("expected the supplied key, got %q", key)
+	}
+}
+
+func TestAnonymizeLicenseText(t *testing.T) {
+	text := "MIT License\n\n    Copyright (c) 2025 Clement Li\n\nPermission is hereby granted, free of charge, to any person\n"
+	want := "MIT License\n\n    Copyright (c) 2025 Individual Contributor\n\nPermission is hereby granted, free of charge, to any person\n"
+	if got := anonymizeLicenseText(text, nil); got != want {
+		t.Errorf("anonymizeLicenseText() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitHolderList(t *testing.T) {
+	tests := []struct {
+		name   string
+		holder string
+		want   []string
+	}{
+		{"single holder", "Acme Corp.", []string{"Acme Corp"}},
+		{"people list", "Alice, Bob and Carol", []string{"Alice", "Bob", "Carol"}},
+		{"serial comma", "Alice Liddell, Bob Marley, and Carol King", []string{"Alice Liddell", "Bob Marley", "Carol King"}},
+		{"ampersand", "John Smith & Jane Doe", []string{"John Smith", "Jane Doe"}},
+		{"company name list", "Smith, Johnson & Co", []string{"Smith, Johnson & Co"}},
+		{"company name list with and", "Smith, Johnson and Company", []string{"Smith, Johnson and Company"}},
+		{"firm with sons", "Smith & Sons Ltd.", []string{"Smith & Sons Ltd"}},
+		{"two word firm", "Procter & Gamble", []string{"Procter & Gamble"}},
+		{"no spaces", "AT&T", []string{"AT&T"}},
+		{"suffix after comma", "Free Software Foundation, Inc. and Jane Doe", []string{"Free Software Foundation, Inc", "Jane Doe"}},
+		{"affiliates", "Acme Corp. and its affiliates", []string{"Acme Corp. and its affiliates"}},
+		{"last first", "Smith, John", []string{"Smith, John"}},
+		{"emails", "Jane Doe <jane@example.com> and John Smith <john@example.com>", []string{"Jane Doe <jane@example.com>", "John Smith <john@example.com>"}},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitHolderList(tt.holder); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitHolderList(%q) = %q, want %q", tt.holder, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitHoldersFixture(t *testing.T) {
+	s := NewScanner()
+	s.SplitHolders = true
+	copyright, err := s.extractCopyright(filepath.Join("testdata", "holder_lists.c"))
+	if err != nil {
+		t.Fatalf("extractCopyright failed: %v", err)
+	}
+
+	var holders []string
+	for _, entry := range s.fileEntries(copyright, "holder_lists.c", "", "", false) {
+		holders = append(holders, entry.Holder)
+	}
+	want := []string{
+		"Alice Liddell", "Bob Marley", "Carol King",
+		"Smith, Johnson & Co",
+		"Free Software Foundation, Inc", "Jane Doe",
+		"Procter & Gamble",
+		"Acme Corp. and its affiliates",
+		"Dewey", "Cheatem", "Howe",
+	}
+	if !reflect.DeepEqual(holders, want) {
+		t.Errorf("holders = %q, want %q", holders, want)
+	}
+
+	// Without the option each statement is one entry
+	s.SplitHolders = false
+	if entries := s.fileEntries(copyright, "holder_lists.c", "", "", false); len(entries) != 6 {
+		t.Errorf("expected 6 entries without SplitHolders, got %d", len(entries))
+	}
+}
+
+func TestHolderMatches(t *testing.T) {
+	tests := []struct {
+		statement string
+		holder    string
+		want      bool
+	}{
+		{"Copyright 2024 Acme Corp. All rights reserved.", "Acme", true},
+		{"Copyright 2024 Acme Corp. All rights reserved.", "acme corp", true},
+		{"Copyright 2024 Acmeware Inc.", "Acme", false},
+		{"Copyright (c) 2020 Alice Liddell, Bob Marley and Carol King", "Bob Marley", true},
+		{"Copyright 2021 Jane Doe <acme@example.com>", "acme", false},
+		{"Copyright 2021 Jane Doe", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := HolderMatches(tt.statement, tt.holder); got != tt.want {
+			t.Errorf("HolderMatches(%q, %q) = %v, want %v", tt.statement, tt.holder, got, tt.want)
+		}
+	}
+}
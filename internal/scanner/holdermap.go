@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// holderKey normalizes a holder name for HolderMap lookups: case, width,
+// whitespace and trailing punctuation don't matter
+func holderKey(holder string) string {
+	key := strings.Join(strings.Fields(strings.ToLower(norm.NFKC.String(holder))), " ")
+	return strings.TrimRight(key, " .,;")
+}
+
+// LoadHolderMap reads a variant to canonical holder mapping from a .csv file
+// with variant,canonical rows or from a JSON object, keyed for HolderMap
+func LoadHolderMap(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holder map: %v", err)
+	}
+	defer file.Close()
+
+	format := "json"
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		format = "csv"
+	}
+	return ParseHolderMap(file, format)
+}
+
+// ParseHolderMap reads a variant to canonical holder mapping as "json" or
+// "csv". A CSV header row of "variant,canonical" is skipped
+func ParseHolderMap(r io.Reader, format string) (map[string]string, error) {
+	var pairs map[string]string
+	switch format {
+	case "json":
+		if err := json.NewDecoder(r).Decode(&pairs); err != nil {
+			return nil, fmt.Errorf("failed to parse holder map: %v", err)
+		}
+	case "csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = 2
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse holder map: %v", err)
+		}
+		pairs = make(map[string]string, len(records))
+		for i, record := range records {
+			if i == 0 && strings.EqualFold(record[0], "variant") && strings.EqualFold(record[1], "canonical") {
+				continue
+			}
+			pairs[record[0]] = record[1]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported holder map format: %s", format)
+	}
+
+	holderMap := make(map[string]string, len(pairs))
+	for variant, canonical := range pairs {
+		if key := holderKey(variant); key != "" {
+			holderMap[key] = strings.TrimSpace(canonical)
+		}
+	}
+	return holderMap, nil
+}
+
+// canonicalizeCopyright replaces the holders of a statement found in
+// HolderMap with their canonical names, leaving unmapped holders unchanged
+func (s *Scanner) canonicalizeCopyright(statement string) string {
+	if len(s.HolderMap) == 0 || !copyrightMarkerPattern.MatchString(statement) {
+		return statement
+	}
+
+	lead, holder, trail := splitHolder(statement)
+	if holder == "" {
+		return statement
+	}
+	if canonical, ok := s.HolderMap[holderKey(holder)]; ok {
+		return lead + canonical + trail
+	}
+
+	// Map each entry of a holder list, keeping the separators
+	separators := holderSeparatorPattern.FindAllString(holder, -1)
+	parts := holderSeparatorPattern.Split(holder, -1)
+	changed := false
+	var result strings.Builder
+	for i, part := range parts {
+		if canonical, ok := s.HolderMap[holderKey(part)]; ok {
+			part = canonical
+			changed = true
+		}
+		result.WriteString(part)
+		if i < len(separators) {
+			result.WriteString(separators[i])
+		}
+	}
+	if !changed {
+		return statement
+	}
+	return lead + result.String() + trail
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHolderMap(t *testing.T) {
+	csvMap, err := ParseHolderMap(strings.NewReader("variant,canonical\nAcme Corp.,Acme Corporation\n\"Widgets, Inc\",Acme Corporation\n"), "csv")
+	if err != nil {
+		t.Fatalf("ParseHolderMap(csv) failed: %v", err)
+	}
+	if len(csvMap) != 2 || csvMap["acme corp"] != "Acme Corporation" || csvMap["widgets, inc"] != "Acme Corporation" {
+		t.Errorf("unexpected csv holder map: %v", csvMap)
+	}
+
+	jsonMap, err := ParseHolderMap(strings.NewReader(`{"  ACME   corp ": "Acme Corporation"}`), "json")
+	if err != nil {
+		t.Fatalf("ParseHolderMap(json) failed: %v", err)
+	}
+	if jsonMap["acme corp"] != "Acme Corporation" {
+		t.Errorf("unexpected json holder map: %v", jsonMap)
+	}
+
+	if _, err := ParseHolderMap(strings.NewReader("a,b,c\n"), "csv"); err == nil {
+		t.Error("expected an error for a malformed csv row")
+	}
+	if _, err := ParseHolderMap(strings.NewReader(""), "yaml"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestCanonicalizeCopyright(t *testing.T) {
+	s := NewScanner()
+	s.HolderMap = map[string]string{
+		"acme corp":    "Acme Corporation",
+		"acme gmbh":    "Acme Corporation",
+		"widgets inc":  "Acme Corporation",
+		"example, inc": "Example Holdings Inc.",
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Copyright 2024 Acme Corp.", "Copyright 2024 Acme Corporation"},
+		{"Copyright (c) 2019 ACME GmbH. All rights reserved.", "Copyright (c) 2019 Acme Corporation. All rights reserved."},
+		{"Copyright 2020 Widgets Inc and Jane Doe", "Copyright 2020 Acme Corporation and Jane Doe"},
+		{"Copyright 2021 Example, Inc", "Copyright 2021 Example Holdings Inc."},
+		{"Copyright 2022 Unmapped Ltd.", "Copyright 2022 Unmapped Ltd."},
+	}
+
+	for _, tt := range tests {
+		if got := s.canonicalizeCopyright(tt.in); got != tt.want {
+			t.Errorf("canonicalizeCopyright(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxImageMetadataBytes caps how much of an image is read when looking for metadata
+const maxImageMetadataBytes = 16 * 1024 * 1024
+
+// exifCopyrightTag is the EXIF/TIFF tag holding the copyright notice
+const exifCopyrightTag = 0x8298
+
+// xmpJPEGHeader prefixes XMP packets stored in a JPEG APP1 segment
+const xmpJPEGHeader = "http://ns.adobe.com/xap/1.0/\x00"
+
+// imageExtensions are the image formats whose metadata is scanned
+var imageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".tif": true, ".tiff": true,
+	".gif": true, ".webp": true, ".heic": true,
+}
+
+// xmpRightsPattern matches the dc:rights property of an XMP packet
+var xmpRightsPattern = regexp.MustCompile(`(?s)<dc:rights>(.*?)</dc:rights>`)
+
+// xmpListItemPattern matches the language alternatives inside dc:rights
+var xmpListItemPattern = regexp.MustCompile(`(?s)<rdf:li[^>]*>(.*?)</rdf:li>`)
+
+// isImageFile checks if a file has a supported image extension
+func isImageFile(path string) bool {
+	return imageExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// extractImageCopyright extracts copyright notices from the EXIF, PNG text and XMP
+// metadata of an image, reporting them to FileScanned as found in source
+func (s *Scanner) extractImageCopyright(filePath, source string) (string, error) {
+	statements, err := imageStatements(osFile(filePath))
+	if err != nil {
+		return "", err
+	}
+
+	s.reportFile(source, statements)
+	return joinLines(statements), nil
+}
+
+// imageStatements extracts the distinct copyright notices of the image name of fsys
+func imageStatements(fsys fs.FS, name string) ([]string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageMetadataBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		values = jpegCopyrights(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		values = pngCopyrights(data)
+	case bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")):
+		values = append(exifCopyrights(data), xmpRights(data)...)
+	default:
+		// Other formats are only searched for an embedded XMP packet
+		values = xmpRights(data)
+	}
+
+	// Emit each distinct notice as a copyright statement
+	var statements []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		value = strings.Join(strings.Fields(value), " ")
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		if !copyrightMarkerPattern.MatchString(value) {
+			value = "Copyright " + value
+		}
+		statements = append(statements, value)
+	}
+	return statements, nil
+}
+
+// joinLines joins statements into newline-terminated lines
+func joinLines(statements []string) string {
+	if len(statements) == 0 {
+		return ""
+	}
+	return strings.Join(statements, "\n") + "\n"
+}
+
+// jpegCopyrights reads the EXIF and XMP segments of a JPEG image
+func jpegCopyrights(data []byte) []string {
+	var values []string
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// Fill byte before the actual marker
+			pos++
+			continue
+		}
+		// Metadata segments precede the image data, stop at start of scan or end of image
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			break
+		}
+		segment := data[pos+4 : pos+2+length]
+
+		if marker == 0xE1 {
+			switch {
+			case bytes.HasPrefix(segment, []byte("Exif\x00\x00")):
+				values = append(values, exifCopyrights(segment[6:])...)
+			case bytes.HasPrefix(segment, []byte(xmpJPEGHeader)):
+				values = append(values, xmpRights(segment[len(xmpJPEGHeader):])...)
+			}
+		}
+		pos += 2 + length
+	}
+	return values
+}
+
+// exifCopyrights reads the copyright tag from the first IFD of a TIFF structure
+func exifCopyrights(tiff []byte) []string {
+	if len(tiff) < 8 {
+		return nil
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return nil
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) != exifCopyrightTag {
+			continue
+		}
+
+		// Values of up to 4 bytes are stored inline, longer ones at an offset
+		size := int(order.Uint32(tiff[entry+4:]))
+		var value []byte
+		if size <= 4 {
+			value = tiff[entry+8 : entry+8+size]
+		} else {
+			offset := int(order.Uint32(tiff[entry+8:]))
+			if offset < 0 || offset+size > len(tiff) {
+				return nil
+			}
+			value = tiff[offset : offset+size]
+		}
+
+		// The tag may hold photographer and editor copyrights separated by NUL
+		var values []string
+		for _, part := range strings.Split(string(value), "\x00") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// pngCopyrights reads the Copyright text chunks and XMP packet of a PNG image
+func pngCopyrights(data []byte) []string {
+	var values []string
+	pos := 8
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(data) || chunkType == "IEND" {
+			break
+		}
+		chunk := data[pos+8 : pos+8+length]
+		pos += 12 + length
+
+		keyword, text, ok := pngTextChunk(chunkType, chunk)
+		if !ok {
+			continue
+		}
+		switch keyword {
+		case "Copyright":
+			values = append(values, text)
+		case "XML:com.adobe.xmp":
+			values = append(values, xmpRights([]byte(text))...)
+		}
+	}
+	return values
+}
+
+// pngTextChunk decodes the keyword and text of a tEXt, zTXt or iTXt chunk
+func pngTextChunk(chunkType string, chunk []byte) (string, string, bool) {
+	keyword, rest, found := bytes.Cut(chunk, []byte{0})
+	if !found {
+		return "", "", false
+	}
+
+	switch chunkType {
+	case "tEXt":
+		return string(keyword), string(rest), true
+	case "zTXt":
+		// Compression method byte followed by zlib data
+		if len(rest) < 1 {
+			return "", "", false
+		}
+		text, err := inflate(rest[1:])
+		return string(keyword), text, err == nil
+	case "iTXt":
+		// Compression flag, compression method, language tag and translated keyword precede the text
+		if len(rest) < 2 {
+			return "", "", false
+		}
+		compressed := rest[0] == 1
+		_, rest, found = bytes.Cut(rest[2:], []byte{0})
+		if !found {
+			return "", "", false
+		}
+		_, text, found := bytes.Cut(rest, []byte{0})
+		if !found {
+			return "", "", false
+		}
+		if !compressed {
+			return string(keyword), string(text), true
+		}
+		inflated, err := inflate(text)
+		return string(keyword), inflated, err == nil
+	}
+	return "", "", false
+}
+
+// inflate decompresses zlib data, capped at maxImageMetadataBytes
+func inflate(data []byte) (string, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	text, err := io.ReadAll(io.LimitReader(reader, maxImageMetadataBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to inflate text chunk: %v", err)
+	}
+	return string(text), nil
+}
+
+// xmpRights extracts the dc:rights values of an XMP packet
+func xmpRights(data []byte) []string {
+	var values []string
+	for _, rights := range xmpRightsPattern.FindAllSubmatch(data, -1) {
+		items := xmpListItemPattern.FindAllSubmatch(rights[1], -1)
+		if len(items) == 0 {
+			values = append(values, html.UnescapeString(string(rights[1])))
+			continue
+		}
+		for _, item := range items {
+			values = append(values, html.UnescapeString(string(item[1])))
+		}
+	}
+	return values
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// pngChunk encodes a single PNG chunk
+func pngChunk(chunkType string, data []byte) []byte {
+	var chunk bytes.Buffer
+	binary.Write(&chunk, binary.BigEndian, uint32(len(data)))
+	chunk.WriteString(chunkType)
+	chunk.Write(data)
+	binary.Write(&chunk, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(chunkType), data...)))
+	return chunk.Bytes()
+}
+
+// jpegWithExif builds a minimal JPEG holding an EXIF copyright tag
+func jpegWithExif(copyright string) []byte {
+	value := append([]byte(copyright), 0)
+
+	// Little-endian TIFF header, one IFD entry, value stored after the IFD
+	var tiff bytes.Buffer
+	tiff.WriteString("II*\x00")
+	binary.Write(&tiff, binary.LittleEndian, uint32(8))
+	binary.Write(&tiff, binary.LittleEndian, uint16(1))
+	binary.Write(&tiff, binary.LittleEndian, uint16(exifCopyrightTag))
+	binary.Write(&tiff, binary.LittleEndian, uint16(2))
+	binary.Write(&tiff, binary.LittleEndian, uint32(len(value)))
+	binary.Write(&tiff, binary.LittleEndian, uint32(8+2+12+4))
+	binary.Write(&tiff, binary.LittleEndian, uint32(0))
+	tiff.Write(value)
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	var jpeg bytes.Buffer
+	jpeg.Write([]byte{0xFF, 0xD8, 0xFF, 0xE1})
+	binary.Write(&jpeg, binary.BigEndian, uint16(len(segment)+2))
+	jpeg.Write(segment)
+	jpeg.Write([]byte{0xFF, 0xD9})
+	return jpeg.Bytes()
+}
+
+func TestExtractImageCopyright(t *testing.T) {
+	xmp := `<x:xmpmeta><rdf:RDF><rdf:Description><dc:rights><rdf:Alt><rdf:li xml:lang="x-default">© 2022 Studio &amp; Sons</rdf:li></rdf:Alt></dc:rights></rdf:Description></rdf:RDF></x:xmpmeta>`
+
+	var png bytes.Buffer
+	png.WriteString("\x89PNG\r\n\x1a\n")
+	png.Write(pngChunk("IHDR", make([]byte, 13)))
+	png.Write(pngChunk("tEXt", []byte("Copyright\x002021 Jane Photographer")))
+	png.Write(pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"+xmp)))
+	png.Write(pngChunk("IEND", nil))
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"photo.jpg", jpegWithExif("(c) 2020 John Smith"), "(c) 2020 John Smith\n"},
+		{"logo.png", png.Bytes(), "Copyright 2021 Jane Photographer\n© 2022 Studio & Sons\n"},
+		{"broken.jpg", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF}, ""},
+	}
+
+	dir := t.TempDir()
+	s := NewScanner()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.extractImageCopyright(path, path)
+			if err != nil {
+				t.Fatalf("extractImageCopyright failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("extractImageCopyright() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
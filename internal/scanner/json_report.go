@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"encoding/json"
+)
+
+// JSONReport is the JSON form of a scan report
+type JSONReport struct {
+	Software   string          `json:"software"`
+	Copyrights []JSONCopyright `json:"copyrights"`
+	// Files maps each file to the copyrights found in it, set by
+	// Scanner.JSONFiles
+	Files map[string][]JSONCopyright `json:"files,omitempty"`
+	// Compatibility is the license compatibility verdict of the scanned
+	// files, set by Scanner.CheckCompatibility
+	Compatibility *LicenseCompatibility `json:"compatibility,omitempty"`
+}
+
+// JSONCopyright is a single copyright statement of a JSON report
+type JSONCopyright struct {
+	File    string `json:"file"`
+	Holder  string `json:"holder"`
+	Email   string `json:"email,omitempty"`
+	Raw     string `json:"raw"`
+	License string `json:"license"`
+	// NoticeType is Proprietary, SPDXTagged, BareCopyright or Unknown
+	NoticeType NoticeType `json:"noticeType"`
+	Confidence float64    `json:"confidence"`
+	// Warnings describes implausible years, set by Scanner.ValidateYears
+	Warnings []string `json:"warnings,omitempty"`
+	// SuspiciousYear marks a statement with Warnings
+	SuspiciousYear bool `json:"suspiciousYear,omitempty"`
+	// MissingYear marks a statement without any year, set by
+	// Scanner.FlagMissingYears
+	MissingYear bool `json:"missingYear,omitempty"`
+}
+
+// NewJSONReport builds the JSON report of the given software from scanned entries,
+// keeping their order
+func NewJSONReport(software string, entries []CopyrightEntry) JSONReport {
+	report := JSONReport{Software: software, Copyrights: make([]JSONCopyright, 0, len(entries))}
+	for _, entry := range entries {
+		report.Copyrights = append(report.Copyrights, JSONCopyright{
+			File:           entry.SourceFile,
+			Holder:         entry.Holder,
+			Email:          entry.Email,
+			Raw:            entry.RawText,
+			License:        entry.License,
+			NoticeType:     entry.NoticeType,
+			Confidence:     entry.Confidence,
+			Warnings:       entry.Warnings,
+			SuspiciousYear: entry.SuspiciousYear,
+			MissingYear:    entry.MissingYear,
+		})
+	}
+	return report
+}
+
+// groupByFile sets Files from the copyrights of the report
+func (r *JSONReport) groupByFile() {
+	r.Files = make(map[string][]JSONCopyright)
+	for _, copyright := range r.Copyrights {
+		r.Files[copyright.File] = append(r.Files[copyright.File], copyright)
+	}
+}
+
+// marshalJSONReport encodes a JSON report, indented unless compact is set
+func marshalJSONReport(report JSONReport, compact bool) ([]byte, error) {
+	var data []byte
+	var err error
+	if compact {
+		data, err = json.Marshal(report)
+	} else {
+		data, err = json.MarshalIndent(report, "", "  ")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
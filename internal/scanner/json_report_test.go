@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanSubDirectoriesJSON(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"alpha/a.go": "// SPDX-License-Identifier: MIT\n// Copyright 2024 Acme Corp.\npackage a\n",
+		"alpha/b.go": "// Copyright 2023 Jane Doe\npackage a\n",
+	})
+	outDir := t.TempDir()
+
+	tests := []struct {
+		compact bool
+		want    string
+	}{
+		{false, `{
+  "software": "alpha",
+  "copyrights": [
+    {
+      "file": "` + filepath.Join(root, "alpha", "a.go") + `",
+      "holder": "Acme Corp",
+      "raw": "Copyright 2024 Acme Corp.",
+      "license": "MIT",
+      "noticeType": "SPDXTagged",
+      "confidence": 0.75
+    },
+    {
+      "file": "` + filepath.Join(root, "alpha", "b.go") + `",
+      "holder": "Jane Doe",
+      "raw": "Copyright 2023 Jane Doe",
+      "license": "",
+      "noticeType": "BareCopyright",
+      "confidence": 0.75
+    }
+  ]
+}
+`},
+		{true, `{"software":"alpha","copyrights":[` +
+			`{"file":"` + filepath.Join(root, "alpha", "a.go") + `","holder":"Acme Corp","raw":"Copyright 2024 Acme Corp.","license":"MIT","noticeType":"SPDXTagged","confidence":0.75},` +
+			`{"file":"` + filepath.Join(root, "alpha", "b.go") + `","holder":"Jane Doe","raw":"Copyright 2023 Jane Doe","license":"","noticeType":"BareCopyright","confidence":0.75}]}
+`},
+	}
+
+	for _, tt := range tests {
+		s := NewScanner()
+		s.OutputFormat = FormatJSON
+		s.CompactJSON = tt.compact
+		if err := s.ScanSubDirectories(root, filepath.Join(outDir, "copyright_{name}.json")); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := os.ReadFile(filepath.Join(outDir, "copyright_alpha.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("compact=%v report = %s, want %s", tt.compact, got, tt.want)
+		}
+	}
+}
+
+func TestScanDirectoryPerFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":     "// Copyright 2024 Acme Corp.\npackage a\n",
+		"b.go":     "// Copyright 2024 Acme Corp.\n\n// Copyright 2023 Jane Doe\npackage a\n",
+		"empty.go": "package a\n",
+	})
+
+	s := NewScanner()
+	files, err := s.ScanDirectoryPerFile(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectoryPerFile failed: %v", err)
+	}
+	got := make(map[string][]string)
+	for file, entries := range files {
+		for _, entry := range entries {
+			got[filepath.Base(file)] = append(got[filepath.Base(file)], entry.RawText)
+		}
+	}
+	want := map[string][]string{
+		"a.go": {"Copyright 2024 Acme Corp."},
+		"b.go": {"Copyright 2024 Acme Corp.", "Copyright 2023 Jane Doe"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanDirectoryPerFile() = %v, want %v", got, want)
+	}
+}
+
+func TestJSONReportFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"alpha/a.go": "// Copyright 2024 Acme Corp.\npackage a\n",
+		"alpha/b.go": "// Copyright 2024 Acme Corp.\npackage a\n",
+	})
+	outDir := t.TempDir()
+
+	s := NewScanner()
+	s.OutputFormat = FormatJSON
+	s.JSONFiles = true
+	if err := s.ScanSubDirectories(root, filepath.Join(outDir, "copyright_{name}.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "copyright_alpha.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		copyrights := report.Files[filepath.Join(root, "alpha", name)]
+		if len(copyrights) != 1 || copyrights[0].Raw != "Copyright 2024 Acme Corp." {
+			t.Errorf("files[%s] = %+v, want the Acme Corp. statement", name, copyrights)
+		}
+	}
+}
+
+func TestJSONReportWarnings(t *testing.T) {
+	report := NewJSONReport("acme", []CopyrightEntry{
+		{Holder: "Acme Corp", RawText: "Copyright 2205 Acme Corp.", Years: []int{2205}, Warnings: []string{"future year 2205"}},
+		{Holder: "Example Inc", RawText: "Copyright 2019 Example Inc.", Years: []int{2019}},
+	})
+	data, err := marshalJSONReport(report, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"warnings":["future year 2205"]`) || strings.Count(string(data), `"warnings"`) != 1 {
+		t.Errorf("unexpected warnings in JSON report: %s", data)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// licenseSignature identifies a license by phrases that all appear in its text
+type licenseSignature struct {
+	id      string
+	phrases []string
+}
+
+// licenseSignatures is checked in order, so more specific licenses come first
+var licenseSignatures = []licenseSignature{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"MIT", []string{"permission is hereby granted, free of charge", "the above copyright notice and this permission notice"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+// DetectLicense identifies the SPDX license id of a license text, or returns
+// an empty string if the text matches no known license
+func DetectLicense(text string) string {
+	match, _ := MatchLicense(text)
+	return match.Name
+}
+
+// DetectLicenses walks a directory tree and returns the sorted, distinct SPDX
+// ids of all recognized license files in it
+func (s *Scanner) DetectLicenses(dir string) ([]string, error) {
+	return s.detectLicenses(os.DirFS(dir))
+}
+
+// detectLicenses implements DetectLicenses for the tree of fsys
+func (s *Scanner) detectLicenses(fsys fs.FS) ([]string, error) {
+	seen := make(map[string]bool)
+
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isLicenseTextFileName(entry.Name()) || isNoticeFileName(entry.Name()) {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil
+		}
+		if id := DetectLicense(string(content)); id != "" {
+			seen[id] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedLicenses(seen), nil
+}
+
+// DetectFileLicenses returns the sorted, distinct SPDX ids of the recognized
+// license files among the given paths, as DetectLicenses does for a tree
+func DetectFileLicenses(paths []string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if !isLicenseTextFileName(name) || isNoticeFileName(name) {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read license file %s: %v", path, err)
+		}
+		if id := DetectLicense(string(content)); id != "" {
+			seen[id] = true
+		}
+	}
+	return sortedLicenses(seen), nil
+}
+
+// sortedLicenses returns the license ids of a set in order
+func sortedLicenses(seen map[string]bool) []string {
+	licenses := make([]string, 0, len(seen))
+	for id := range seen {
+		licenses = append(licenses, id)
+	}
+	sort.Strings(licenses)
+	return licenses
+}
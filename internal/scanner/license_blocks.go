@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// commentLinePrefixes start a line comment or a continuation line of a block comment
+var commentLinePrefixes = []string{"//", "/*", "*", "#", "--", "<!--"}
+
+// InlineLicense is a license text found in a comment block of a source file
+type InlineLicense struct {
+	License string
+	// Line is the first line of the first block holding the license text
+	Line int
+	// Count is the number of identical blocks merged into this one
+	Count int
+}
+
+// inlineLicenses detects the license texts in the comment blocks of a file.
+// With MergeRepeatedLicenseBlocks, identical blocks are reported once
+func (s *Scanner) inlineLicenses(fsys fs.FS, name string) ([]InlineLicense, error) {
+	file, err := s.openText(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var licenses []InlineLicense
+	merged := make(map[string]int)
+
+	var block []string
+	blockStart := 0
+	flushBlock := func() {
+		text := strings.Join(block, " ")
+		block = nil
+		start := blockStart
+		blockStart = 0
+		if text == "" {
+			return
+		}
+
+		id := DetectLicense(text)
+		if id == "" {
+			return
+		}
+		key := strings.ToLower(strings.Join(strings.Fields(text), " "))
+		if s.MergeRepeatedLicenseBlocks {
+			if i, ok := merged[key]; ok {
+				licenses[i].Count++
+				return
+			}
+			merged[key] = len(licenses)
+		}
+		licenses = append(licenses, InlineLicense{License: id, Line: start, Count: 1})
+	}
+
+	lines := bufio.NewScanner(s.limitScan(file))
+	lines.Buffer(make([]byte, 1024*1024), 1024*1024)
+	lineNumber := 0
+	inBlockComment := false
+	for lines.Scan() {
+		lineNumber++
+		trimmed := strings.TrimSpace(lines.Text())
+
+		isComment := inBlockComment
+		for _, prefix := range commentLinePrefixes {
+			if strings.HasPrefix(trimmed, prefix) {
+				isComment = true
+				break
+			}
+		}
+		if strings.Contains(trimmed, "/*") {
+			inBlockComment = true
+		}
+		if strings.Contains(trimmed, "*/") {
+			inBlockComment = false
+		}
+
+		// Code ends the current comment block, and so do blank lines outside block comments
+		if trimmed == "" && isComment {
+			continue
+		}
+		if !isComment || trimmed == "" {
+			flushBlock()
+			continue
+		}
+		if blockStart == 0 {
+			blockStart = lineNumber
+		}
+		if cleaned := s.cleanLine(trimmed); cleaned != "" {
+			block = append(block, cleaned)
+		}
+	}
+	flushBlock()
+
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return licenses, nil
+}
+
+// formatInlineLicenses formats the inline licenses of one file as section lines
+func formatInlineLicenses(source string, licenses []InlineLicense) string {
+	var result strings.Builder
+	for _, license := range licenses {
+		result.WriteString(fmt.Sprintf("%s:%d %s", source, license.Line, license.License))
+		if license.Count > 1 {
+			result.WriteString(fmt.Sprintf(" (%d identical blocks)", license.Count))
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// inlineLicenseSection wraps inline license lines in an output section
+func inlineLicenseSection(lines string) string {
+	if lines == "" {
+		return ""
+	}
+	return "\nInline Licenses:\n----------------------------------------\n\n" + lines
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInlineLicenses(t *testing.T) {
+	fixture := filepath.Join("testdata", "amalgamated.c")
+
+	tests := []struct {
+		name  string
+		merge bool
+		want  []InlineLicense
+	}{
+		{"every block", false, []InlineLicense{
+			{License: "MIT", Line: 1, Count: 1},
+			{License: "MIT", Line: 13, Count: 1},
+			{License: "BSD-3-Clause", Line: 25, Count: 1},
+			{License: "MIT", Line: 34, Count: 1},
+		}},
+		{"merged", true, []InlineLicense{
+			{License: "MIT", Line: 1, Count: 3},
+			{License: "BSD-3-Clause", Line: 25, Count: 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner()
+			s.MergeRepeatedLicenseBlocks = tt.merge
+			got, err := s.inlineLicenses(osFile(fixture))
+			if err != nil {
+				t.Fatalf("inlineLicenses failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inlineLicenses() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInlineLicenseSection(t *testing.T) {
+	s := NewScanner()
+	s.InlineLicenses = true
+	s.MergeRepeatedLicenseBlocks = true
+
+	got, err := s.ScanFile(filepath.Join("testdata", "amalgamated.c"))
+	if err != nil {
+		t.Fatalf("ScanFile failed: %v", err)
+	}
+	if !strings.Contains(got, "\nInline Licenses:\n") || !strings.Contains(got, "amalgamated.c:1 MIT (3 identical blocks)\n") {
+		t.Errorf("missing merged inline license, got %q", got)
+	}
+}
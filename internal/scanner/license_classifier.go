@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LicenseMatch is a license recognized in a license text, in the shape of a
+// classification of Google's licenseclassifier, so its field names are kept
+// as JSON keys. Offsets are byte offsets into the text, EndOffset exclusive,
+// and lines start at 1. The span runs from the first to the last signature
+// phrase found. Licenses are recognized by all their signature phrases
+// appearing in the text, so every match has a Confidence of 1
+type LicenseMatch struct {
+	Name        string
+	Confidence  float64
+	StartLine   int
+	EndLine     int
+	StartOffset int
+	EndOffset   int
+}
+
+// LicenseClassification lists the licenses recognized in one license file,
+// in the shape of a licenseclassifier result
+type LicenseClassification struct {
+	Filepath        string
+	Classifications []LicenseMatch
+}
+
+// normalizedLicenseText is a license text lowercased with its whitespace
+// collapsed to single spaces, as licenses are compared, along with the
+// offset in the original text of each of its bytes
+type normalizedLicenseText struct {
+	text    string
+	offsets []int
+}
+
+// normalizeLicenseText lowercases text and collapses its whitespace,
+// recording where each byte came from
+func normalizeLicenseText(text string) normalizedLicenseText {
+	var normalized strings.Builder
+	var offsets []int
+	for i := 0; i < len(text); {
+		if r, size := utf8.DecodeRuneInString(text[i:]); unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		start := i
+		for i < len(text) {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+
+		// The separating space belongs to the start of the next word
+		if normalized.Len() > 0 {
+			normalized.WriteByte(' ')
+			offsets = append(offsets, start)
+		}
+		word := strings.ToLower(text[start:i])
+		normalized.WriteString(word)
+		for j := 0; j < len(word); j++ {
+			// Lowercasing may change the length of non-ASCII letters
+			offsets = append(offsets, min(start+j, i-1))
+		}
+	}
+	return normalizedLicenseText{text: normalized.String(), offsets: offsets}
+}
+
+// MatchLicense identifies the license of a text like DetectLicense and
+// returns where in the text its signature phrases were found
+func MatchLicense(text string) (LicenseMatch, bool) {
+	// Compare on lowercase text with normalized whitespace so line wrapping doesn't matter
+	normalized := normalizeLicenseText(text)
+
+	for _, signature := range licenseSignatures {
+		start, end := len(normalized.text), 0
+		for _, phrase := range signature.phrases {
+			i := strings.Index(normalized.text, phrase)
+			if i < 0 {
+				start = -1
+				break
+			}
+			start, end = min(start, i), max(end, i+len(phrase))
+		}
+		if start < 0 {
+			continue
+		}
+
+		startOffset := normalized.offsets[start]
+		endOffset := normalized.offsets[end-1] + 1
+		return LicenseMatch{
+			Name:        signature.id,
+			Confidence:  1,
+			StartLine:   1 + strings.Count(text[:startOffset], "\n"),
+			EndLine:     1 + strings.Count(text[:endOffset], "\n"),
+			StartOffset: startOffset,
+			EndOffset:   endOffset,
+		}, true
+	}
+	return LicenseMatch{}, false
+}
+
+// ClassifyLicenseFiles walks a directory tree and classifies its license
+// files, named as for DetectLicenses, in walk order. Files without a
+// recognized license are left out, and paths are joined to dir
+func (s *Scanner) ClassifyLicenseFiles(dir string) ([]LicenseClassification, error) {
+	var results []LicenseClassification
+	fsys := os.DirFS(dir)
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isLicenseTextFileName(entry.Name()) || isNoticeFileName(entry.Name()) {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil
+		}
+		if match, ok := MatchLicense(string(content)); ok {
+			results = append(results, LicenseClassification{
+				Filepath:        filepath.Join(dir, filepath.FromSlash(path)),
+				Classifications: []LicenseMatch{match},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// WriteLicenseClassifications writes classifications as the indented JSON
+// array licenseclassifier's tools print
+func WriteLicenseClassifications(w io.Writer, results []LicenseClassification) error {
+	if results == nil {
+		results = []LicenseClassification{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
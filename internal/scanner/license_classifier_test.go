@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchLicense(t *testing.T) {
+	mit := "MIT License\n\nCopyright (c) 2024 Acme Corp.\n\n" +
+		"Permission is hereby granted, free of charge, to any person obtaining a copy\n" +
+		"of this software. The above copyright notice and this   permission\n" +
+		"notice shall be included in all copies.\n\nTHE SOFTWARE IS PROVIDED \"AS IS\"\n"
+
+	match, ok := MatchLicense(mit)
+	if !ok {
+		t.Fatal("expected the MIT license to match")
+	}
+	start := strings.Index(mit, "Permission is")
+	end := strings.Index(mit, "permission\nnotice") + len("permission\nnotice")
+	want := LicenseMatch{Name: "MIT", Confidence: 1, StartLine: 5, EndLine: 7, StartOffset: start, EndOffset: end}
+	if match != want {
+		t.Errorf("MatchLicense() = %+v, want %+v", match, want)
+	}
+
+	// Offsets point into the original text, whatever its case and non-ASCII letters
+	apache := "Ünïcödé preamble\n  APACHE   LICENSE\n  Version 2.0, January 2004\n"
+	match, ok = MatchLicense(apache)
+	if !ok || match.Name != "Apache-2.0" || apache[match.StartOffset:match.EndOffset] != "APACHE   LICENSE\n  Version 2.0" {
+		t.Errorf("MatchLicense() = %+v, %v", match, ok)
+	}
+
+	if _, ok := MatchLicense("All rights reserved."); ok {
+		t.Error("expected no match for a proprietary notice")
+	}
+}
+
+func TestClassifyLicenseFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"LICENSE":              "Apache License\n  Version 2.0, January 2004\n",
+		"vendor/lib/LICENSE":   "Proprietary. All rights reserved.\n",
+		"vendor/gpl/COPYING":   "GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991\n",
+		"license.go":           "// GNU Affero General Public License version 3\n",
+		"vendor/gpl/main.c":    "// Copyright 2020 Jane Doe\n",
+		"vendor/gpl/NOTICE":    "GNU GENERAL PUBLIC LICENSE\nVersion 3\n",
+		"docs/LICENSE.fr.md":   "# Licence MIT\n",
+		"docs/unrelated.md":    "Apache License, Version 2.0\n",
+		"vendor/empty/LICENSE": "",
+	})
+
+	s := NewScanner()
+	results, err := s.ClassifyLicenseFiles(dir)
+	if err != nil {
+		t.Fatalf("ClassifyLicenseFiles failed: %v", err)
+	}
+	want := []LicenseClassification{
+		{Filepath: filepath.Join(dir, "LICENSE"), Classifications: []LicenseMatch{
+			{Name: "Apache-2.0", Confidence: 1, StartLine: 1, EndLine: 2, StartOffset: 0, EndOffset: 28},
+		}},
+		{Filepath: filepath.Join(dir, "vendor", "gpl", "COPYING"), Classifications: []LicenseMatch{
+			{Name: "GPL-2.0", Confidence: 1, StartLine: 1, EndLine: 2, StartOffset: 0, EndOffset: 36},
+		}},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("ClassifyLicenseFiles() = %+v, want %+v", results, want)
+	}
+
+	var out bytes.Buffer
+	if err := WriteLicenseClassifications(&out, results[:1]); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"Filepath"`, `"Classifications"`, `"Name": "Apache-2.0"`, `"Confidence": 1`, `"StartOffset": 0`, `"EndOffset": 28`} {
+		if !strings.Contains(out.String(), key) {
+			t.Errorf("expected %s in:\n%s", key, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := WriteLicenseClassifications(&out, nil); err != nil || out.String() != "[]\n" {
+		t.Errorf("WriteLicenseClassifications(nil) = %q, %v", out.String(), err)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxLicenseTitleChars caps the first line shown for a license text
+const maxLicenseTitleChars = 80
+
+// licenseText is a distinct license text found in one or more license files
+type licenseText struct {
+	title string
+	id    string
+	hash  string
+	paths []string
+}
+
+// findLicenseTexts reads the license files of a scanned tree and groups them
+// by their text, ignoring whitespace, in the order they were found
+func findLicenseTexts(files []directoryFile) []licenseText {
+	var texts []licenseText
+	index := make(map[string]int)
+	for _, file := range files {
+		content, err := fs.ReadFile(file.fsys, file.relPath)
+		if err != nil {
+			continue
+		}
+		hash := licenseTextHash(string(content))
+		if hash == "" {
+			continue
+		}
+		location := filepath.ToSlash(file.relPath)
+		if i, ok := index[hash]; ok {
+			texts[i].paths = append(texts[i].paths, location)
+			continue
+		}
+		index[hash] = len(texts)
+		texts = append(texts, licenseText{
+			title: licenseTitle(string(content)),
+			id:    DetectLicense(string(content)),
+			hash:  hash,
+			paths: []string{location},
+		})
+	}
+	return texts
+}
+
+// licenseTextHash returns a short hash of a license text with its whitespace
+// normalized, or "" for an empty text
+func licenseTextHash(content string) string {
+	normalized := strings.Join(strings.Fields(content), " ")
+	if normalized == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// licenseTitle returns the first non-empty line of a license text
+func licenseTitle(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if len(line) > maxLicenseTitleChars {
+				line = strings.ToValidUTF8(line[:maxLicenseTitleChars], "") + "..."
+			}
+			return line
+		}
+	}
+	return ""
+}
+
+// licensesFoundSection lists each distinct license text with the files
+// holding it, warning when the tree ships more than one
+func licensesFoundSection(texts []licenseText) string {
+	if len(texts) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("\nLicenses Found:\n")
+	result.WriteString("----------------------------------------\n\n")
+	for _, text := range texts {
+		id := text.id
+		if id == "" {
+			id = "unrecognized"
+		}
+		fmt.Fprintf(&result, "%s (%s, sha256:%s)\n", text.title, id, text.hash)
+		for _, path := range text.paths {
+			fmt.Fprintf(&result, "  %s\n", path)
+		}
+	}
+	if len(texts) > 1 {
+		fmt.Fprintf(&result, "\nWarning: %d distinct license texts found, check that they don't conflict\n", len(texts))
+	}
+	return result.String()
+}
+
+// licenseTextStemPattern matches the start of a license or notice file name
+// and captures the rest: LICENSE, LICENCE, COPYING, UNLICENSE and NOTICE
+var licenseTextStemPattern = regexp.MustCompile(`(?i)^(?:licen[cs]e|copying|unlicense|notice)(.*)$`)
+
+// languageSuffixPattern matches a language suffix such as "de" or "zh-CN"
+var languageSuffixPattern = regexp.MustCompile(`(?i)^[a-z]{2}(?:[-_][a-z]{2})?$`)
+
+// licenseTextExtensions are the extensions a license text may have
+var licenseTextExtensions = map[string]bool{".txt": true, ".md": true, ".markdown": true, ".rst": true}
+
+// licenseTextVariants are the suffixes naming a variant of a license text
+var licenseTextVariants = map[string]bool{"lesser": true, "lib": true}
+
+// licenseTextKinds orders the root license files by the name they start with
+var licenseTextKinds = []string{"licen", "copying", "unlicense", "notice"}
+
+// licenseTextDir is the directory of per-license files, as in REUSE
+const licenseTextDir = "licenses"
+
+// rootLicenseFile is a license or notice file whose text ends a directory report
+type rootLicenseFile struct {
+	path    string
+	content string
+}
+
+// isLicenseTextFileName checks if a file name is a license or notice file:
+// LICENSE, LICENCE, COPYING, UNLICENSE or NOTICE, optionally followed by a
+// known SPDX license ID (LICENSE-MIT), a variant (COPYING.LESSER) or a
+// language (LICENSE_zh-CN), and by a text extension (LICENSE.fr.md). A
+// language after a dot needs the text extension, so License.cs isn't taken
+// for a Czech license
+func isLicenseTextFileName(name string) bool {
+	ext := path.Ext(name)
+	hasTextExtension := licenseTextExtensions[strings.ToLower(ext)]
+	if hasTextExtension {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	match := licenseTextStemPattern.FindStringSubmatch(name)
+	if match == nil {
+		return false
+	}
+	suffix := match[1]
+	if suffix == "" {
+		return true
+	}
+	if !strings.ContainsRune("-_.", rune(suffix[0])) {
+		return false
+	}
+
+	separator, suffix := suffix[0], suffix[1:]
+	if _, ok := knownSPDXLicense(suffix); ok || licenseTextVariants[strings.ToLower(suffix)] {
+		return true
+	}
+	return languageSuffixPattern.MatchString(suffix) && (separator != '.' || hasTextExtension)
+}
+
+// isNoticeFileName checks if a license or notice file name is a NOTICE,
+// which carries attributions rather than license terms
+func isNoticeFileName(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), "notice")
+}
+
+// licenseTextKind returns the index in licenseTextKinds of a license file name
+func licenseTextKind(name string) int {
+	lower := strings.ToLower(name)
+	for i, prefix := range licenseTextKinds {
+		if strings.HasPrefix(lower, prefix) {
+			return i
+		}
+	}
+	return len(licenseTextKinds)
+}
+
+// rootLicenseTexts reads the license and notice files of the root of fsys and
+// every file of its licenses directory, whatever the case of its name. The
+// root files come first, LICENSE before COPYING, UNLICENSE and NOTICE, then
+// the licenses directory, each by name. A text repeating an earlier one,
+// ignoring whitespace, is left out
+func rootLicenseTexts(fsys fs.FS) []rootLicenseFile {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil
+	}
+
+	var rootFiles, dirFiles []string
+	for _, entry := range entries {
+		switch {
+		case entry.IsDir() && strings.EqualFold(entry.Name(), licenseTextDir):
+			dirEntries, err := fs.ReadDir(fsys, entry.Name())
+			if err != nil {
+				continue
+			}
+			for _, dirEntry := range dirEntries {
+				if dirEntry.Type().IsRegular() {
+					dirFiles = append(dirFiles, path.Join(entry.Name(), dirEntry.Name()))
+				}
+			}
+		case entry.Type().IsRegular() && isLicenseTextFileName(entry.Name()):
+			rootFiles = append(rootFiles, entry.Name())
+		}
+	}
+	sort.SliceStable(rootFiles, func(i, j int) bool {
+		return licenseTextKind(rootFiles[i]) < licenseTextKind(rootFiles[j])
+	})
+	sort.Strings(dirFiles)
+
+	var files []rootLicenseFile
+	seen := make(map[string]bool)
+	for _, name := range append(rootFiles, dirFiles...) {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+		hash := licenseTextHash(string(content))
+		if hash == "" || seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		files = append(files, rootLicenseFile{path: name, content: string(content)})
+	}
+	return files
+}
+
+// licenseTextSection appends the text of each license file under its name
+func (s *Scanner) licenseTextSection(files []rootLicenseFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("\nLicense Text:\n")
+	result.WriteString("----------------------------------------\n")
+	for _, file := range files {
+		content := file.content
+		if s.AnonymizePersonalNames {
+			content = anonymizeLicenseText(content, s.pseudonymKey())
+		}
+		fmt.Fprintf(&result, "\n%s:\n\n%s", file.path, content)
+
+		// Ensure each text ends with a newline
+		if !strings.HasSuffix(content, "\n") {
+			result.WriteString("\n")
+		}
+	}
+	return result.String()
+}
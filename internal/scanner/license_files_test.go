@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLicensesFoundSection(t *testing.T) {
+	mit := "MIT License\n\nPermission is hereby granted, free of charge, to any person\n" +
+		"The above copyright notice and this permission notice shall be included\n"
+	apache := "\n   Apache License\n   Version 2.0, January 2004\n"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "no license files",
+			files: map[string]string{"main.go": "// Copyright 2024 Acme Corp.\n"},
+			want:  "",
+		},
+		{
+			name: "same license rewrapped",
+			files: map[string]string{
+				"LICENSE":            mit,
+				"vendor/dep/LICENSE": strings.ReplaceAll(mit, "\n", "\n\n"),
+			},
+			want: "\nLicenses Found:\n----------------------------------------\n\n" +
+				"MIT License (MIT, sha256:" + licenseTextHash(mit) + ")\n  LICENSE\n  vendor/dep/LICENSE\n",
+		},
+		{
+			name: "conflicting licenses",
+			files: map[string]string{
+				"LICENSE":                     mit,
+				"third_party/lib/LICENSE.txt": apache,
+			},
+			want: "\nLicenses Found:\n----------------------------------------\n\n" +
+				"MIT License (MIT, sha256:" + licenseTextHash(mit) + ")\n  LICENSE\n" +
+				"Apache License (Apache-2.0, sha256:" + licenseTextHash(apache) + ")\n  third_party/lib/LICENSE.txt\n" +
+				"\nWarning: 2 distinct license texts found, check that they don't conflict\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			s := NewScanner()
+			result, err := s.scanDirectoryEntries(context.Background(), os.DirFS(dir), dir, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := licensesFoundSection(findLicenseTexts(result.licenseFiles)); got != tt.want {
+				t.Errorf("licensesFoundSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLicenseTextFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"LICENSE", true},
+		{"license.txt", true},
+		{"LICENCE.md", true},
+		{"LICENSE-MIT", true},
+		{"LICENSE-APACHE-2.0.txt", true},
+		{"LICENSE.de.md", true},
+		{"LICENSE_zh-CN", true},
+		{"LICENSE.Apache-2.0", true},
+		{"LICENSE.de", false},
+		{"License.cs", false},
+		{"license.java", false},
+		{"LICENSE.h", false},
+		{"license_files.go", false},
+		{"COPYING", true},
+		{"COPYING.LESSER", true},
+		{"UNLICENSE", true},
+		{"NOTICE.txt", true},
+		{"license.go", false},
+		{"license-check.sh", false},
+		{"LICENSES", false},
+		{"notices.json", false},
+		{"README.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLicenseTextFileName(tt.name); got != tt.want {
+			t.Errorf("isLicenseTextFileName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLicenseTextSection(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go":              "// Copyright 2024 Acme Corp.\n",
+		"NOTICE":               "Acme Widget\nCopyright 2024 Acme Corp.\n",
+		"LICENSE":              "MIT License\n",
+		"LICENSE.txt":          "MIT   License\n",
+		"COPYING.LESSER":       "GNU Lesser General Public License",
+		"license.go":           "package license\n",
+		"LICENSES/MIT.txt":     "MIT License\n",
+		"LICENSES/CC0-1.0.txt": "Creative Commons Legal Code\n",
+		"docs/LICENSE":         "Docs License\n",
+	})
+
+	s := NewScanner()
+	result, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	// Repeated texts are left out, and the last text gets its newline
+	want := "\nLicense Text:\n----------------------------------------\n" +
+		"\nLICENSE:\n\nMIT License\n" +
+		"\nCOPYING.LESSER:\n\nGNU Lesser General Public License\n" +
+		"\nNOTICE:\n\nAcme Widget\nCopyright 2024 Acme Corp.\n" +
+		"\nLICENSES/CC0-1.0.txt:\n\nCreative Commons Legal Code\n"
+	if !strings.HasSuffix(result, want) {
+		t.Errorf("expected the license texts at the end, got:\n%s", result)
+	}
+}
+
+func TestLicensesFoundSkipsSourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"LICENSE":          "MIT License\n\nPermission is hereby granted, free of charge, to any person\n",
+		"NOTICE":           "Acme Widget\nCopyright 2024 Acme Corp.\n",
+		"license.go":       "// GNU Affero General Public License version 3\npackage license\n",
+		"license_files.go": "package license\n",
+		"License.cs":       "namespace Acme;\n",
+	})
+
+	s := NewScanner()
+	result, err := s.scanDirectoryEntries(context.Background(), os.DirFS(dir), dir, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\nLicenses Found:\n----------------------------------------\n\n" +
+		"MIT License (unrecognized, sha256:" + licenseTextHash("MIT License\n\nPermission is hereby granted, free of charge, to any person\n") + ")\n  LICENSE\n"
+	if got := licensesFoundSection(findLicenseTexts(result.licenseFiles)); got != want {
+		t.Errorf("licensesFoundSection() = %q, want %q", got, want)
+	}
+
+	licenses, err := s.DetectLicenses(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 0 {
+		t.Errorf("DetectLicenses() = %v, want no license from source files", licenses)
+	}
+}
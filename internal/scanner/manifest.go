@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ManifestEntry lists every copyright statement found in one file, including duplicates
+type ManifestEntry struct {
+	File       string   `json:"file"`
+	Copyrights []string `json:"copyrights"`
+	ThirdParty bool     `json:"thirdParty,omitempty"`
+}
+
+// WriteManifest writes per-file manifest entries as "json" or "csv". The CSV
+// form has one row per statement, and one row with an empty copyright for
+// files without any
+func WriteManifest(w io.Writer, entries []ManifestEntry, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"file", "copyright"}); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if len(entry.Copyrights) == 0 {
+				if err := writer.Write([]string{entry.File, ""}); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, copyright := range entry.Copyrights {
+				if err := writer.Write([]string{entry.File, copyright}); err != nil {
+					return err
+				}
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported manifest format: %s", format)
+	}
+}
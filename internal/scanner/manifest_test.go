@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileScannedReportsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	content := "// Copyright 2024 Acme Corp.\n\nfunc main() {}\n\n// Copyright 2024 Acme Corp.\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "empty.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []ManifestEntry
+	s := NewScanner()
+	s.FileScanned = func(path string, copyrights []string) {
+		entries = append(entries, ManifestEntry{File: filepath.Base(path), Copyrights: copyrights})
+	}
+	result, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if result != "Copyright 2024 Acme Corp.\n" {
+		t.Errorf("summary should stay deduplicated, got %q", result)
+	}
+
+	var csv bytes.Buffer
+	if err := WriteManifest(&csv, entries, "csv"); err != nil {
+		t.Fatalf("WriteManifest failed: %v", err)
+	}
+	want := "file,copyright\nempty.go,\nmain.go,Copyright 2024 Acme Corp.\nmain.go,Copyright 2024 Acme Corp.\n"
+	if csv.String() != want {
+		t.Errorf("csv manifest = %q, want %q", csv.String(), want)
+	}
+
+	if err := WriteManifest(&bytes.Buffer{}, entries, "xml"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
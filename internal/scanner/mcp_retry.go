@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryBaseDelay is the delay before the first retry of an MCP call
+// when MCPConfig.RetryBaseDelay is unset
+const DefaultRetryBaseDelay = 500 * time.Millisecond
+
+// DefaultMCPTimeout limits a single MCP request when MCPConfig.Timeout is unset
+const DefaultMCPTimeout = 60 * time.Second
+
+// ErrMCPTimeout is returned when an MCP request doesn't complete within the timeout
+var ErrMCPTimeout = errors.New("MCP request timed out")
+
+// maxRetryDelay caps the backoff between two MCP calls
+const maxRetryDelay = time.Minute
+
+// httpStatusPattern extracts the status code from the errors of the MCP HTTP transport
+var httpStatusPattern = regexp.MustCompile(`\(status: ([0-9]{3})\)`)
+
+// retryableError checks if an MCP call failed transiently: on a network
+// error, or with a 429 or 5xx status. Other statuses, such as an
+// authentication failure, won't change on a retry
+func retryableError(err error) bool {
+	if errors.Is(err, ErrMCPTimeout) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if match := httpStatusPattern.FindStringSubmatch(err.Error()); match != nil {
+		status, _ := strconv.Atoi(match[1])
+		return status == 429 || status >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryDelay returns the backoff before the given retry, counted from zero:
+// the base delay doubled per retry up to maxRetryDelay, with random jitter
+// of up to half of it
+func (m *MCPService) retryDelay(retry int) time.Duration {
+	base := m.retryBaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	delay := base
+	for i := 0; i < retry && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// withRetry runs call, retrying it up to the configured number of times
+// while it fails with a retryable error. Each attempt is limited to the
+// request timeout. No retry is started once ctx is done or its deadline would
+// pass during the backoff; the last error is returned
+func (m *MCPService) withRetry(ctx context.Context, call func(ctx context.Context) error) error {
+	for retry := 0; ; retry++ {
+		err := m.callWithTimeout(ctx, call)
+		if err == nil || retry >= m.maxRetries || !retryableError(err) {
+			return err
+		}
+
+		delay := m.retryDelay(retry)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		m.log().Warn("MCP call failed, retrying", "error", err, "retry", retry+1, "delay", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// callWithTimeout runs call with a context limited to the request timeout. An
+// attempt that runs out of time, while ctx itself is still live, fails with
+// ErrMCPTimeout rather than a transport error
+func (m *MCPService) callWithTimeout(ctx context.Context, call func(ctx context.Context) error) error {
+	timeout := m.requestTimeout()
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := call(callCtx)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	var netErr net.Error
+	if callCtx.Err() == context.DeadlineExceeded || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w after %v: %v", ErrMCPTimeout, timeout, err)
+	}
+	return err
+}
+
+// requestTimeout returns the time limit of a single MCP request
+func (m *MCPService) requestTimeout() time.Duration {
+	if m.timeout > 0 {
+		return m.timeout
+	}
+	return DefaultMCPTimeout
+}
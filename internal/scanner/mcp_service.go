@@ -7,11 +7,17 @@ package scanner
 import (
 	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	nethttp "net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	mcp "github.com/metoro-io/mcp-golang"
 	"github.com/metoro-io/mcp-golang/transport/http"
@@ -23,11 +29,30 @@ type MCPClient interface {
 	GetPrompt(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error)
 }
 
+// Analyzer analyzes the copyright information of a scanned project, such as
+// an MCP endpoint or a chat completions API
+type Analyzer interface {
+	Analyze(ctx context.Context, copyrightInfo string) (string, error)
+}
+
 // MCPService handles the Model Context Protocol integration
 type MCPService struct {
 	scanner   *Scanner
 	mcpClient MCPClient
+	analyzer  Analyzer
+	endpoint  string
 	model     string
+	limits    ArchiveLimits
+	cacheDir  string
+	scanOnly  bool
+	logger    *slog.Logger
+	// structured requests JSON analyses, see MCPConfig.StructuredAnalysis
+	structured bool
+
+	timeout        time.Duration
+	maxChunkChars  int
+	maxRetries     int
+	retryBaseDelay time.Duration
 }
 
 // MCPConfig holds the configuration for MCP service
@@ -35,157 +60,561 @@ type MCPConfig struct {
 	Model    string
 	Endpoint string
 	APIKey   string
+	// Analyzer, if set, analyzes the scanned archives instead of the MCP
+	// endpoint, e.g. an OpenAIAnalyzer
+	Analyzer Analyzer
+	// CacheDir, if set, stores each analysis under the SHA-256 of the
+	// provider, its base URL, the model name and the scanned copyright
+	// information, and returns it when the same information is analyzed
+	// again instead of calling the model
+	CacheDir string
+	// ScanOnly skips the analysis, so AnalyzeZipFile returns the scanned
+	// copyright information as is and no endpoint is contacted
+	ScanOnly bool
+	// StructuredAnalysis asks the model for a JSON analysis listing the
+	// holders, the year range, conflicts and recommendations, parsed into
+	// the AnalysisResult of AnalyzeZipFileResult. A response that isn't valid
+	// JSON is kept as free text
+	StructuredAnalysis bool
+	// Limits caps the extraction of analyzed archives; zero fields use the defaults
+	Limits ArchiveLimits
+	// Timeout limits each MCP request, including the HTTP round trip;
+	// DefaultMCPTimeout if unset
+	Timeout time.Duration
+	// MaxChunkChars is the size of the copyright text analyzed in one
+	// request. Larger texts are analyzed in chunks whose analyses are then
+	// consolidated; DefaultMaxChunkChars if unset
+	MaxChunkChars int
+	// MaxRetries is the number of times an MCP call failing with a network
+	// error, a 429 or a 5xx status is retried, none if unset
+	MaxRetries int
+	// RetryBaseDelay is the backoff before the first retry, doubled for each
+	// further one and jittered; DefaultRetryBaseDelay if unset
+	RetryBaseDelay time.Duration
+	// Logger receives the progress and error messages of the analyses, such
+	// as retries and cache hits; the logger of the scanner if unset
+	Logger *slog.Logger
 }
 
 // NewMCPService creates a new MCP service instance
 func NewMCPService(scanner *Scanner, config MCPConfig) (*MCPService, error) {
 	// Create a new MCP client with HTTP transport
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultMCPTimeout
+	}
 	transport := http.NewHTTPClientTransport("/mcp")
+	transport.WithClient(&nethttp.Client{Timeout: timeout})
 	transport.WithBaseURL(config.Endpoint)
 	transport.WithHeader("Authorization", "Bearer "+config.APIKey)
 
 	mcpClient := mcp.NewClient(transport)
 
 	return &MCPService{
-		scanner:   scanner,
-		mcpClient: mcpClient,
-		model:     config.Model,
+		scanner:    scanner,
+		mcpClient:  mcpClient,
+		analyzer:   config.Analyzer,
+		endpoint:   config.Endpoint,
+		model:      config.Model,
+		limits:     config.Limits,
+		cacheDir:   config.CacheDir,
+		scanOnly:   config.ScanOnly,
+		logger:     config.Logger,
+		structured: config.StructuredAnalysis,
+
+		timeout:        timeout,
+		maxChunkChars:  config.MaxChunkChars,
+		maxRetries:     config.MaxRetries,
+		retryBaseDelay: config.RetryBaseDelay,
 	}, nil
 }
 
-// AnalyzeCopyright analyzes copyright information in a zip file
+// AnalyzeCopyright analyzes copyright information in a zip, tar or tar.gz archive
 func (s *MCPService) AnalyzeCopyright(zipFile string) (string, error) {
-	// Create a temporary directory to extract the zip file
-	tempDir, err := os.MkdirTemp("", "nemesis_analysis_*")
+	copyrightInfo, err := s.ScanZipFile(zipFile)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp directory: %v", err)
+		return "", err
+	}
+
+	// Use MCP to analyze the content
+	ctx := context.Background()
+	var response *mcp.ToolResponse
+	err = s.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.mcpClient.CallTool(ctx, "analyze_copyright", map[string]interface{}{
+			"content": copyrightInfo,
+		})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to analyze content with MCP: %w", err)
 	}
-	defer os.RemoveAll(tempDir)
 
-	// Extract the zip file
-	if err := s.extractZip(zipFile, tempDir); err != nil {
-		return "", fmt.Errorf("failed to extract zip file: %v", err)
+	// Extract the analysis from the response
+	if response == nil || len(response.Content) == 0 {
+		return "", fmt.Errorf("%w: no analysis result received", ErrUnexpectedContent)
 	}
+	return contentText(response.Content[0])
+}
+
+// ScanZipFile extracts a zip, tar or tar.gz archive and returns the copyright
+// information found in it, without analyzing it
+func (m *MCPService) ScanZipFile(zipPath string) (string, error) {
+	copyrightInfo, _, err := m.scanArchive(zipPath)
+	return copyrightInfo, err
+}
 
-	// Use Scanner to extract copyright information
-	copyrightInfo, err := s.scanner.ScanDirectory(tempDir)
+// SummarizeZipFile extracts and scans an archive like ScanZipFile and
+// summarizes the holders found in it, without analyzing them
+func (m *MCPService) SummarizeZipFile(zipPath string) (Summary, error) {
+	_, entries, err := m.scanArchive(zipPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to scan directory: %v", err)
+		return Summary{}, err
 	}
+	return SummarizeEntries(entries), nil
+}
 
-	// Use MCP to analyze the content
-	ctx := context.Background()
-	response, err := s.mcpClient.CallTool(ctx, "analyze_copyright", map[string]interface{}{
-		"content": copyrightInfo,
+// scanArchive implements ScanZipFile, also returning the scanned entries
+func (m *MCPService) scanArchive(zipPath string) (string, []CopyrightEntry, error) {
+	return m.scanExtracted(zipPath, func(destDir string) error {
+		return m.extractArchive(zipPath, destDir)
 	})
+}
 
+// scanExtracted scans the archive that extract extracts to a temporary
+// directory, logging unreadable files under the archive name
+func (m *MCPService) scanExtracted(name string, extract func(destDir string) error) (string, []CopyrightEntry, error) {
+	// Create a temporary directory to extract the archive
+	tempDir, err := os.MkdirTemp("", "nemesis_analysis_*")
 	if err != nil {
-		return "", fmt.Errorf("failed to analyze content with MCP: %v", err)
+		return "", nil, fmt.Errorf("failed to create temp directory: %v", err)
 	}
+	defer os.RemoveAll(tempDir)
 
-	// Extract the analysis from the response
-	if response != nil && len(response.Content) > 0 {
-		return response.Content[0].TextContent.Text, nil
+	// Extract the archive
+	if err := extract(tempDir); err != nil {
+		return "", nil, fmt.Errorf("failed to extract archive: %v", err)
 	}
 
-	return "", fmt.Errorf("no analysis result received from MCP")
+	// Scan the extracted directory for copyright information, attributing
+	// files by their name in the archive rather than the temp path
+	copyrightInfo, entries, err := m.scanner.scanDirectoryReport(context.Background(), os.DirFS(tempDir), tempDir, "")
+	if err = logFileErrors(m.log().With("archive", name), err); err != nil {
+		return "", nil, fmt.Errorf("failed to scan directory: %v", err)
+	}
+	return copyrightInfo, entries, nil
 }
 
-// AnalyzeZipFile analyzes copyright information in a zip file using MCP
+// AnalyzeZipFile analyzes copyright information in a zip, tar or tar.gz archive
+// using MCP. With ScanOnly it returns the scanned information unanalyzed
 func (m *MCPService) AnalyzeZipFile(ctx context.Context, zipPath string) (string, error) {
-	// Create a temporary directory to extract the zip file
-	tempDir, err := os.MkdirTemp("", "nemesis_analysis_*")
+	report, _, err := m.AnalyzeZipFileResult(ctx, zipPath)
+	return report, err
+}
+
+// AnalyzeZipFileResult analyzes an archive like AnalyzeZipFile, also
+// returning the analysis of the model. With StructuredAnalysis it is parsed
+// from the JSON response, the report then shows the parsed analysis
+func (m *MCPService) AnalyzeZipFileResult(ctx context.Context, zipPath string) (string, AnalysisResult, error) {
+	copyrightInfo, entries, err := m.scanArchive(zipPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp directory: %v", err)
+		return "", AnalysisResult{}, err
 	}
-	defer os.RemoveAll(tempDir)
+	return m.analyzeScanned(ctx, zipPath, copyrightInfo, SummarizeEntries(entries))
+}
 
-	// Extract the zip file
-	if err := m.extractZip(zipPath, tempDir); err != nil {
-		return "", fmt.Errorf("failed to extract zip file: %v", err)
+// AnalyzeZipFileSummary analyzes an archive like AnalyzeZipFile, also
+// returning the summary of the holders computed from the same scan, so the
+// archive is extracted and scanned only once
+func (m *MCPService) AnalyzeZipFileSummary(ctx context.Context, zipPath string) (string, Summary, error) {
+	copyrightInfo, entries, err := m.scanArchive(zipPath)
+	if err != nil {
+		return "", Summary{}, err
 	}
+	summary := SummarizeEntries(entries)
+	report, _, err := m.analyzeScanned(ctx, zipPath, copyrightInfo, summary)
+	if err != nil {
+		return "", Summary{}, err
+	}
+	return report, summary, nil
+}
 
-	// Scan the extracted directory for copyright information
-	copyrightInfo, err := m.scanner.ScanDirectory(tempDir)
+// readerArchiveName stands for an archive read from a reader in log messages
+const readerArchiveName = "(reader)"
+
+// AnalyzeZipReader analyzes the zip, tar or tar.gz archive of size bytes read
+// from r like AnalyzeZipFile, e.g. an archive streamed into memory that was
+// never written to disk. The format is detected from the content alone
+func (m *MCPService) AnalyzeZipReader(ctx context.Context, r io.ReaderAt, size int64) (string, error) {
+	copyrightInfo, entries, err := m.scanExtracted(readerArchiveName, func(destDir string) error {
+		return m.extractArchiveReader(r, size, destDir)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to scan directory: %v", err)
+		return "", err
 	}
+	report, _, err := m.analyzeScanned(ctx, readerArchiveName, copyrightInfo, SummarizeEntries(entries))
+	return report, err
+}
 
-	// Prepare context for MCP
-	messages := []*mcp.PromptMessage{
-		mcp.NewPromptMessage(
-			mcp.NewTextContent(`You are a copyright analysis expert. Analyze the provided copyright information and provide:
+// analyzeScanned implements AnalyzeZipFileResult for the scanned copyright
+// information of the archive name and the summary of its entries
+func (m *MCPService) analyzeScanned(ctx context.Context, name, copyrightInfo string, summary Summary) (string, AnalysisResult, error) {
+	if m.scanOnly {
+		return copyrightInfo, AnalysisResult{}, nil
+	}
+
+	// Reuse the analysis of identical copyright information
+	if cached, ok := m.cachedAnalysis(copyrightInfo); ok {
+		m.log().Debug("Using cached analysis", "archive", name)
+		result := m.analysisResult(cached.Analysis, name)
+		return m.formatAnalysisResult(copyrightInfo, summary, result.String(), cached.Chunks), result, nil
+	}
+
+	analysis, chunks, err := m.analyzeChunked(ctx, copyrightInfo)
+	if err != nil {
+		return "", AnalysisResult{}, err
+	}
+	m.cacheAnalysis(copyrightInfo, analysis, chunks)
+
+	// Format and return the result
+	result := m.analysisResult(analysis, name)
+	return m.formatAnalysisResult(copyrightInfo, summary, result.String(), chunks), result, nil
+}
+
+// analysisResult parses the analysis of an archive if StructuredAnalysis is
+// set, falling back to the raw text for responses that aren't valid JSON
+func (m *MCPService) analysisResult(analysis, zipPath string) AnalysisResult {
+	if !m.structured {
+		return AnalysisResult{Raw: analysis}
+	}
+	result, err := ParseAnalysisResult(analysis)
+	if err != nil {
+		m.log().Warn("Using the analysis as free text", "archive", zipPath, "error", err)
+	}
+	return result
+}
+
+// analysisInstructions tell the model what an analysis consists of
+const analysisInstructions = `You are a copyright analysis expert. Analyze the provided copyright information and provide:
 1. A summary of all copyright holders
 2. The years covered by the copyrights
 3. Any potential conflicts or overlapping claims
 4. Recommendations for compliance
-Please format your response in a clear, structured manner.`),
-			mcp.RoleAssistant,
-		),
-		mcp.NewPromptMessage(
-			mcp.NewTextContent(fmt.Sprintf("Please analyze the following copyright information from a software project:\n\n%s",
-				copyrightInfo)),
-			mcp.RoleUser,
-		),
+Please format your response in a clear, structured manner.`
+
+// analysisPrompt asks for an analysis of copyrightInfo
+func analysisPrompt(copyrightInfo string) string {
+	return fmt.Sprintf("Please analyze the following copyright information from a software project:\n\n%s", copyrightInfo)
+}
+
+// DefaultMaxChunkChars is the size of the copyright text analyzed in one
+// request when MCPConfig.MaxChunkChars is unset
+const DefaultMaxChunkChars = 100000
+
+// analyzeChunked analyzes copyrightInfo in one request if it fits into
+// MaxChunkChars. Larger texts are split into chunks that are analyzed one by
+// one, followed by a request consolidating the partial analyses. It returns
+// the analysis and the number of chunks analyzed
+func (m *MCPService) analyzeChunked(ctx context.Context, copyrightInfo string) (string, int, error) {
+	maxChars := m.maxChunkChars
+	if maxChars <= 0 {
+		maxChars = DefaultMaxChunkChars
+	}
+	chunks := splitChunks(copyrightInfo, maxChars)
+	if len(chunks) <= 1 {
+		analysis, err := m.analyzeValid(ctx, copyrightInfo, copyrightInfo)
+		return analysis, 1, err
+	}
+	m.log().Info("Analyzing copyright information in chunks", "chunks", len(chunks), "max_chars", maxChars)
+
+	var consolidation strings.Builder
+	fmt.Fprintf(&consolidation, "The copyright information of a software project was too large for one request, so it was analyzed in %d parts. "+
+		"Please merge the partial analyses below into a single analysis of the whole project.\n\n", len(chunks))
+	if holders := distinctHolders(copyrightInfo); len(holders) > 0 {
+		fmt.Fprintf(&consolidation, "Distinct copyright holders across all parts:\n%s\n\n", strings.Join(holders, "\n"))
+	}
+	for i, chunk := range chunks {
+		analysis, err := m.analyzeValid(ctx, fmt.Sprintf("Part %d of %d:\n\n%s", i+1, len(chunks), chunk), chunk)
+		if err != nil {
+			return "", 0, fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+		fmt.Fprintf(&consolidation, "Analysis of part %d:\n%s\n\n", i+1, analysis)
+	}
+
+	analysis, err := m.analyzeValid(ctx, consolidation.String(), copyrightInfo)
+	if err != nil {
+		return "", 0, fmt.Errorf("consolidation: %w", err)
+	}
+	return analysis, len(chunks), nil
+}
+
+// analyzeValid analyzes input with the configured analyzer, retrying
+// transient failures, and checks that the result is a real analysis of
+// copyrightInfo
+func (m *MCPService) analyzeValid(ctx context.Context, input, copyrightInfo string) (string, error) {
+	analyzer := m.analyzer
+	if analyzer == nil {
+		analyzer = m
+	}
+	if m.structured {
+		ctx = withAnalysisInstructions(ctx, structuredAnalysisInstructions)
 	}
 
-	// Call MCP for analysis
+	var analysis string
+	err := m.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		analysis, err = analyzer.Analyze(ctx, input)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get MCP analysis: %w", err)
+	}
+
+	// Don't save refusals, errors or unrelated text as an analysis
+	if err := validateAnalysis(analysis, copyrightInfo); err != nil {
+		return "", fmt.Errorf("invalid MCP analysis: %v", err)
+	}
+	return analysis, nil
+}
+
+// Analyze requests an analysis of copyrightInfo from the MCP endpoint, in a
+// single request. It implements Analyzer
+func (m *MCPService) Analyze(ctx context.Context, copyrightInfo string) (string, error) {
+	messages := []*mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.NewTextContent(instructionsFrom(ctx)), mcp.RoleAssistant),
+		mcp.NewPromptMessage(mcp.NewTextContent(analysisPrompt(copyrightInfo)), mcp.RoleUser),
+	}
 	response, err := m.mcpClient.GetPrompt(ctx, "analyze_copyright", messages)
 	if err != nil {
-		return "", fmt.Errorf("failed to get MCP analysis: %v", err)
+		return "", err
 	}
 
 	// Get the response text from the last message
-	var analysisText string
-	if len(response.Messages) > 0 {
-		lastMessage := response.Messages[len(response.Messages)-1]
-		if lastMessage.Content != nil && lastMessage.Content.Type == mcp.ContentTypeText {
-			analysisText = lastMessage.Content.TextContent.Text
+	if response == nil || len(response.Messages) == 0 {
+		return "", fmt.Errorf("%w: no messages", ErrUnexpectedContent)
+	}
+	lastMessage := response.Messages[len(response.Messages)-1]
+	if lastMessage == nil {
+		return "", fmt.Errorf("%w: nil message", ErrUnexpectedContent)
+	}
+	return contentText(lastMessage.Content)
+}
+
+// ErrUnexpectedContent is returned when an MCP response lacks the text of an analysis
+var ErrUnexpectedContent = errors.New("MCP returned empty or unexpected content")
+
+// contentText returns the text of MCP content, failing with
+// ErrUnexpectedContent for missing content or content of another type
+func contentText(content *mcp.Content) (string, error) {
+	switch {
+	case content == nil:
+		return "", fmt.Errorf("%w: nil content", ErrUnexpectedContent)
+	case content.Type != mcp.ContentTypeText:
+		return "", fmt.Errorf("%w: %q content instead of text", ErrUnexpectedContent, content.Type)
+	case content.TextContent == nil:
+		return "", fmt.Errorf("%w: text content without text", ErrUnexpectedContent)
+	}
+	return content.TextContent.Text, nil
+}
+
+// splitChunks splits text at line breaks into chunks of at most maxChars
+// bytes. Lines longer than that are split on their own
+func splitChunks(text string, maxChars int) []string {
+	if len(text) <= maxChars {
+		return []string{text}
+	}
+
+	var chunks []string
+	var chunk strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if chunk.Len() > 0 && chunk.Len()+len(line) > maxChars {
+			chunks = append(chunks, chunk.String())
+			chunk.Reset()
 		}
+		for len(line) > maxChars {
+			// Don't split a multi-byte character
+			cut := maxChars
+			for cut > 1 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			chunks = append(chunks, line[:cut])
+			line = line[cut:]
+		}
+		chunk.WriteString(line)
+	}
+	if chunk.Len() > 0 {
+		chunks = append(chunks, chunk.String())
 	}
+	return chunks
+}
 
-	// Format and return the result
-	return m.formatAnalysisResult(copyrightInfo, analysisText), nil
+// distinctHolders lists the holders of the statements in copyrightInfo once
+// each, ignoring case, in order of appearance
+func distinctHolders(copyrightInfo string) []string {
+	var holders []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(copyrightInfo, "\n") {
+		if !copyrightMarkerPattern.MatchString(line) {
+			continue
+		}
+		for _, holder := range statementHolders(withoutEmails(line)) {
+			if key := strings.ToLower(holder); !seen[key] {
+				seen[key] = true
+				holders = append(holders, holder)
+			}
+		}
+	}
+	return holders
+}
+
+// maxRefusalPrefix is how far into an analysis refusal and error phrases are looked for
+const maxRefusalPrefix = 200
+
+// refusalPhrases start responses that refuse or fail instead of analyzing
+var refusalPhrases = []string{
+	"i can't help", "i cannot help", "i can't assist", "i cannot assist", "i'm unable to",
+	"i am unable to", "i'm sorry", "i am sorry", "as an ai", "i won't be able to",
+	"error:", "internal server error", "rate limit", "service unavailable",
+}
+
+// validateAnalysis checks that a model response is a real analysis of
+// copyrightInfo: it must not be empty, must not open with a refusal or error
+// and must mention at least one holder or year of the input, if it has any
+func validateAnalysis(analysis, copyrightInfo string) error {
+	lower := strings.ToLower(strings.TrimSpace(analysis))
+	if lower == "" {
+		return fmt.Errorf("empty response")
+	}
+
+	opening := lower
+	if len(opening) > maxRefusalPrefix {
+		opening = opening[:maxRefusalPrefix]
+	}
+	for _, phrase := range refusalPhrases {
+		if strings.Contains(opening, phrase) {
+			return fmt.Errorf("response looks like a refusal or error: %q", firstLine(analysis))
+		}
+	}
+
+	// Collect the holders and years the analysis is expected to refer to
+	var references []string
+	for _, line := range strings.Split(copyrightInfo, "\n") {
+		if !copyrightMarkerPattern.MatchString(line) {
+			continue
+		}
+		for _, holder := range statementHolders(line) {
+			references = append(references, strings.ToLower(holder))
+		}
+		for _, year := range ParseCopyrightYears(line) {
+			references = append(references, fmt.Sprint(year))
+		}
+	}
+	if len(references) == 0 {
+		return nil
+	}
+	for _, reference := range references {
+		if strings.Contains(lower, reference) {
+			return nil
+		}
+	}
+	return fmt.Errorf("response doesn't mention any holder or year of the input: %q", firstLine(analysis))
+}
+
+// firstLine returns the first line of a text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
 }
 
-// extractZip extracts a zip file to the specified directory
-func (m *MCPService) extractZip(zipPath, destDir string) error {
+// ArchiveResult holds the analysis of one archive of a batch
+type ArchiveResult struct {
+	Path     string
+	Analysis string
+	// Result is the analysis of the model, see AnalyzeZipFileResult
+	Result AnalysisResult
+	Err    error
+}
+
+// AnalyzeZipFiles analyzes several zip files with at most concurrency analyses
+// running at once. A failing archive doesn't stop the others, its error is
+// reported in its result instead
+func (m *MCPService) AnalyzeZipFiles(ctx context.Context, zipPaths []string, concurrency int) []ArchiveResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]ArchiveResult, len(zipPaths))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, zipPath := range zipPaths {
+		wg.Add(1)
+		go func(i int, zipPath string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// Don't start new analyses once the batch is cancelled
+			if err := ctx.Err(); err != nil {
+				results[i] = ArchiveResult{Path: zipPath, Err: err}
+				return
+			}
+
+			analysis, result, err := m.AnalyzeZipFileResult(ctx, zipPath)
+			results[i] = ArchiveResult{Path: zipPath, Analysis: analysis, Result: result, Err: err}
+		}(i, zipPath)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// extractZip extracts a zip file to the specified directory within budget
+func extractZip(zipPath, destDir string, budget *extractionBudget) error {
 	reader, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return err
 	}
 	defer reader.Close()
+	return extractZipReader(&reader.Reader, destDir, budget)
+}
+
+// extractZipReader extracts the entries of a zip archive to destDir within budget
+func extractZipReader(reader *zip.Reader, destDir string, budget *extractionBudget) error {
+	if err := budget.checkEntries(len(reader.File)); err != nil {
+		return err
+	}
 
 	for _, file := range reader.File {
-		path := filepath.Join(destDir, file.Name)
+		path, err := archiveEntryPath(destDir, file.Name, file.FileInfo().IsDir())
+		if err != nil {
+			return err
+		}
 
 		// Create directory if needed
 		if file.FileInfo().IsDir() {
-			os.MkdirAll(path, file.Mode())
+			if _, err := makeArchiveDir(destDir, path, file.Name); err != nil {
+				return err
+			}
 			continue
 		}
 
-		// Ensure parent directory exists
-		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-			return err
-		}
-
-		// Create file
-		outFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
-		if err != nil {
-			return err
+		// Symlinks must stay inside destDir, or later entries could be written through them
+		if file.Mode()&os.ModeSymlink != 0 {
+			if err := extractSymlink(file, path, destDir); err != nil {
+				return err
+			}
+			continue
 		}
 
 		// Open zip file
 		rc, err := file.Open()
 		if err != nil {
-			outFile.Close()
 			return err
 		}
 
 		// Copy contents
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
+		err = writeArchiveFile(destDir, path, file.Name, rc, file.Mode(), budget)
 		rc.Close()
 		if err != nil {
 			return err
@@ -194,8 +623,45 @@ func (m *MCPService) extractZip(zipPath, destDir string) error {
 	return nil
 }
 
-// formatAnalysisResult formats the analysis result
-func (m *MCPService) formatAnalysisResult(copyrightInfo, analysis string) string {
+// maxSymlinkTargetBytes caps the size of a symlink target read from an archive
+const maxSymlinkTargetBytes = 4096
+
+// extractSymlink creates the symlink stored in a zip entry, refusing targets outside destDir
+func extractSymlink(file *zip.File, path, destDir string) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	target, err := io.ReadAll(io.LimitReader(rc, maxSymlinkTargetBytes))
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	return createArchiveSymlink(file.Name, string(target), path, destDir)
+}
+
+// isWithinDir checks if path stays inside dir once both are cleaned
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// log returns the configured logger, or the logger of the scanner if unset
+func (m *MCPService) log() *slog.Logger {
+	if m.logger != nil {
+		return m.logger
+	}
+	if m.scanner != nil {
+		return m.scanner.logger()
+	}
+	return discardLogger
+}
+
+// formatAnalysisResult formats the analysis result. The summary computed from
+// the scanned entries precedes the analysis, so the holders and years can be
+// trusted even where the model gets them wrong
+func (m *MCPService) formatAnalysisResult(copyrightInfo string, summary Summary, analysis string, chunks int) string {
 	var result strings.Builder
 
 	result.WriteString("Copyright Analysis Result\n")
@@ -206,8 +672,19 @@ func (m *MCPService) formatAnalysisResult(copyrightInfo, analysis string) string
 	result.WriteString(copyrightInfo)
 	result.WriteString("\n\n")
 
-	result.WriteString("AI Analysis:\n")
-	result.WriteString("-----------\n")
+	result.WriteString("Computed Summary:\n")
+	result.WriteString("----------------\n")
+	result.WriteString(summary.String())
+	result.WriteString("\n")
+
+	if chunks > 1 {
+		title := fmt.Sprintf("AI Analysis (consolidated from %d chunks):", chunks)
+		result.WriteString(title + "\n")
+		result.WriteString(strings.Repeat("-", len(title)) + "\n")
+	} else {
+		result.WriteString("AI Analysis:\n")
+		result.WriteString("-----------\n")
+	}
 	result.WriteString(analysis)
 
 	return result.String()
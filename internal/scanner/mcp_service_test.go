@@ -0,0 +1,822 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// mockMCPClient is an MCPClient whose behavior is set per test
+type mockMCPClient struct {
+	callTool  func(ctx context.Context, tool string, params any) (*mcp.ToolResponse, error)
+	getPrompt func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error)
+}
+
+func (c *mockMCPClient) CallTool(ctx context.Context, tool string, params any) (*mcp.ToolResponse, error) {
+	return c.callTool(ctx, tool, params)
+}
+
+func (c *mockMCPClient) GetPrompt(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+	return c.getPrompt(ctx, tool, messages)
+}
+
+// promptReply builds a prompt response whose last message holds text
+func promptReply(text string) *mcp.PromptResponse {
+	return mcp.NewPromptResponse("analysis", mcp.NewPromptMessage(mcp.NewTextContent(text), mcp.RoleAssistant))
+}
+
+// writeZip creates a zip file holding the given files
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzeZipFiles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.zip")
+	writeZip(t, good, map[string]string{"main.go": "// Copyright 2024 Acme Corp.\n"})
+	missing := filepath.Join(dir, "missing.zip")
+
+	service := &MCPService{
+		scanner: NewScanner(),
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			return promptReply("Acme Corp. holds all copyrights"), nil
+		}},
+	}
+
+	results := service.AnalyzeZipFiles(context.Background(), []string{good, missing}, 2)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Path != good || results[0].Err != nil || !strings.Contains(results[0].Analysis, "Acme Corp. holds") {
+		t.Errorf("unexpected result for good archive: %+v", results[0])
+	}
+	if results[1].Path != missing || results[1].Err == nil {
+		t.Errorf("expected an error for the missing archive, got %+v", results[1])
+	}
+
+	// A cancelled batch doesn't start any analysis
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, result := range service.AnalyzeZipFiles(ctx, []string{good}, 1) {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", result.Err)
+		}
+	}
+}
+
+func TestAnalyzeZipFileAttributesEntryNames(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{"src/main.go": "// Copyright 2024 Acme Corp.\n"})
+
+	var files []string
+	s := NewScanner()
+	s.FileScanned = func(path string, copyrights []string) {
+		files = append(files, path)
+	}
+	service := &MCPService{
+		scanner: s,
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			return promptReply("Acme Corp. holds all copyrights"), nil
+		}},
+	}
+
+	if _, err := service.AnalyzeZipFile(context.Background(), zipPath); err != nil {
+		t.Fatalf("AnalyzeZipFile failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join("src", "main.go") {
+		t.Errorf("expected the in-archive name, got %v", files)
+	}
+}
+
+func TestAnalyzeZipFileComputedSummary(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{
+		"a.go": "// Copyright 2019 Acme Corp.\n",
+		"b.go": "// Copyright (c) 2024 acme corp\n",
+	})
+
+	service := &MCPService{
+		scanner: NewScanner(),
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			return promptReply("Acme Corp. holds copyrights from 1999 to 2030"), nil
+		}},
+	}
+
+	result, err := service.AnalyzeZipFile(context.Background(), zipPath)
+	if err != nil {
+		t.Fatalf("AnalyzeZipFile failed: %v", err)
+	}
+	if !strings.Contains(result, "Computed Summary:\n----------------\nAcme Corp: 2019-2024 (2 statements)\n") {
+		t.Errorf("result lacks the computed summary:\n%s", result)
+	}
+
+	summary, err := service.SummarizeZipFile(zipPath)
+	if err != nil {
+		t.Fatalf("SummarizeZipFile failed: %v", err)
+	}
+	want := Summary{Holders: []HolderSummary{{Holder: "Acme Corp", FirstYear: 2019, LastYear: 2024, Occurrences: 2}}}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("SummarizeZipFile() = %+v, want %+v", summary, want)
+	}
+
+	// The report and the summary come from a single scan
+	scanned := 0
+	service.scanner.FileScanned = func(path string, copyrights []string) {
+		scanned++
+	}
+	report, summary, err := service.AnalyzeZipFileSummary(context.Background(), zipPath)
+	if err != nil {
+		t.Fatalf("AnalyzeZipFileSummary failed: %v", err)
+	}
+	if report != result || !reflect.DeepEqual(summary, want) {
+		t.Errorf("AnalyzeZipFileSummary() = %q, %+v, want %q, %+v", report, summary, result, want)
+	}
+	if scanned != 2 {
+		t.Errorf("expected the archive's 2 files to be scanned once, got %d scans", scanned)
+	}
+}
+
+func TestAnalyzeZipFileStructured(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{
+		"a.go": "// Copyright 2019 Acme Corp.\n",
+	})
+
+	tests := []struct {
+		name           string
+		reply          string
+		wantStructured bool
+		wantAnalysis   string
+	}{
+		{"json", `{"holders": [{"name": "Acme Corp.", "first_year": 2019, "last_year": 2019}], "year_range": {"first": 2019, "last": 2019}, "conflicts": [], "recommendations": []}`,
+			true, "AI Analysis:\n-----------\nCopyright holders:\n- Acme Corp. (2019)\n\nYears covered: 2019\n"},
+		{"free text fallback", "Acme Corp. holds the copyright since 2019.", false, "AI Analysis:\n-----------\nAcme Corp. holds the copyright since 2019."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &MCPService{
+				scanner:    NewScanner(),
+				structured: true,
+				mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+					instructions := messages.([]*mcp.PromptMessage)[0].Content.TextContent.Text
+					if !strings.Contains(instructions, "single JSON object") {
+						t.Errorf("expected the JSON instructions, got %q", instructions)
+					}
+					return promptReply(tt.reply), nil
+				}},
+			}
+
+			report, result, err := service.AnalyzeZipFileResult(context.Background(), zipPath)
+			if err != nil {
+				t.Fatalf("AnalyzeZipFileResult failed: %v", err)
+			}
+			if result.Structured != tt.wantStructured || result.Raw != tt.reply {
+				t.Errorf("result = %+v, want structured %v", result, tt.wantStructured)
+			}
+			if tt.wantStructured && (len(result.Holders) != 1 || result.Holders[0].Name != "Acme Corp.") {
+				t.Errorf("holders = %+v, want Acme Corp.", result.Holders)
+			}
+			if !strings.Contains(report, tt.wantAnalysis) {
+				t.Errorf("report lacks %q:\n%s", tt.wantAnalysis, report)
+			}
+		})
+	}
+
+	// Free-text analyses keep the default instructions
+	service := &MCPService{
+		scanner: NewScanner(),
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			if instructions := messages.([]*mcp.PromptMessage)[0].Content.TextContent.Text; instructions != analysisInstructions {
+				t.Errorf("expected the free-text instructions, got %q", instructions)
+			}
+			return promptReply("Acme Corp. holds the copyright since 2019."), nil
+		}},
+	}
+	if _, result, err := service.AnalyzeZipFileResult(context.Background(), zipPath); err != nil || result.Structured {
+		t.Errorf("AnalyzeZipFileResult() = %+v, %v, want an unstructured result", result, err)
+	}
+}
+
+func TestValidateAnalysis(t *testing.T) {
+	input := "Copyright 2024 Acme Corp.\nCopyright (c) 2019 Jane Doe\n"
+
+	tests := []struct {
+		name     string
+		analysis string
+		input    string
+		wantErr  bool
+	}{
+		{"mentions holder", "1. Holders: Acme Corp and Jane Doe", input, false},
+		{"mentions year only", "All files were published in 2019.", input, false},
+		{"empty", "  \n", input, true},
+		{"refusal", "I'm sorry, but I can't help with that request about Acme Corp.", input, true},
+		{"error", "Error: upstream model timed out", input, true},
+		{"unrelated", "Here is a recipe for banana bread.", input, true},
+		{"no copyrights in input", "No copyright statements were provided.", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnalysis(tt.analysis, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAnalysis() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyzeZipFileRejectsRefusal(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{"main.go": "// Copyright 2024 Acme Corp.\n"})
+
+	service := &MCPService{
+		scanner: NewScanner(),
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			return promptReply("I can't help with that."), nil
+		}},
+	}
+
+	_, err := service.AnalyzeZipFile(context.Background(), zipPath)
+	if err == nil || !strings.Contains(err.Error(), "invalid MCP analysis") {
+		t.Errorf("expected the refusal to be rejected, got %v", err)
+	}
+}
+
+func TestAnalyzeZipFileMalformedResponse(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{"main.go": "// Copyright 2024 Acme Corp.\n"})
+
+	tests := []struct {
+		name     string
+		response *mcp.PromptResponse
+	}{
+		{"nil response", nil},
+		{"no messages", &mcp.PromptResponse{}},
+		{"nil message", &mcp.PromptResponse{Messages: []*mcp.PromptMessage{nil}}},
+		{"nil content", &mcp.PromptResponse{Messages: []*mcp.PromptMessage{{Role: mcp.RoleAssistant}}}},
+		{"text without text", &mcp.PromptResponse{Messages: []*mcp.PromptMessage{{Content: &mcp.Content{Type: mcp.ContentTypeText}}}}},
+		{"image", &mcp.PromptResponse{Messages: []*mcp.PromptMessage{{Content: &mcp.Content{Type: mcp.ContentTypeImage, ImageContent: &mcp.ImageContent{}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &MCPService{
+				scanner: NewScanner(),
+				mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+					return tt.response, nil
+				}},
+			}
+			_, err := service.AnalyzeZipFile(context.Background(), zipPath)
+			if !errors.Is(err, ErrUnexpectedContent) {
+				t.Errorf("AnalyzeZipFile() error = %v, want ErrUnexpectedContent", err)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCopyrightMalformedResponse(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{"main.go": "// Copyright 2024 Acme Corp.\n"})
+
+	tests := []struct {
+		name     string
+		response *mcp.ToolResponse
+		want     string
+		wantErr  error
+	}{
+		{"text", mcp.NewToolResponse(mcp.NewTextContent("Acme Corp. holds the copyright")), "Acme Corp. holds the copyright", nil},
+		{"nil content", &mcp.ToolResponse{Content: []*mcp.Content{nil}}, "", ErrUnexpectedContent},
+		{"text without text", &mcp.ToolResponse{Content: []*mcp.Content{{Type: mcp.ContentTypeText}}}, "", ErrUnexpectedContent},
+		{"empty", &mcp.ToolResponse{}, "", ErrUnexpectedContent},
+		{"nil response", nil, "", ErrUnexpectedContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &MCPService{
+				scanner: NewScanner(),
+				mcpClient: &mockMCPClient{callTool: func(ctx context.Context, tool string, params any) (*mcp.ToolResponse, error) {
+					return tt.response, nil
+				}},
+			}
+			got, err := service.AnalyzeCopyright(zipPath)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("AnalyzeCopyright() = %q, %v; want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// writeZipWithSymlink creates a zip file holding a symlink entry followed by a regular entry
+func writeZipWithSymlink(t *testing.T, path, link, target, through string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	header := &zip.FileHeader{Name: link}
+	header.SetMode(os.ModeSymlink | 0777)
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Write([]byte(target))
+
+	entry, err = writer.Create(through)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Write([]byte("// Copyright 2024 Acme Corp.\n"))
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractZipSymlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		wantErr bool
+	}{
+		{"escaping relative", "../../outside", true},
+		{"escaping absolute", "/tmp", true},
+		{"inside", "src", false},
+	}
+
+	service := &MCPService{scanner: NewScanner()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			zipPath := filepath.Join(dir, "archive.zip")
+			writeZipWithSymlink(t, zipPath, "link", tt.target, "link/evil.go")
+
+			destDir := filepath.Join(dir, "dest")
+			if err := os.MkdirAll(filepath.Join(destDir, "src"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			err := extractZip(zipPath, destDir, service.limits.budget())
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "illegal symlink") {
+					t.Fatalf("expected illegal symlink error, got %v", err)
+				}
+				if _, err := os.Lstat(filepath.Join(destDir, "link")); !os.IsNotExist(err) {
+					t.Errorf("escaping symlink was created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractZip failed: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(destDir, "src", "evil.go")); err != nil {
+				t.Errorf("entry written through an internal link is missing: %v", err)
+			}
+		})
+	}
+}
+
+// archiveEntry is an entry of a test archive, a symlink to content if link is set
+type archiveEntry struct {
+	name, content string
+	link          bool
+}
+
+// writeZipEntries creates a zip file holding the entries in order
+func writeZipEntries(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name}
+		header.SetMode(0644)
+		if e.link {
+			header.SetMode(os.ModeSymlink | 0777)
+		}
+		entry, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// chainedSymlinkEntries escapes through links that each look safe on their own
+var chainedSymlinkEntries = []archiveEntry{
+	{name: "a", content: ".", link: true},
+	{name: "a/b", content: "..", link: true},
+	{name: "b/evil.txt", content: "evil\n"},
+}
+
+func TestExtractZipChainedSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "archive.zip")
+	writeZipEntries(t, zipPath, chainedSymlinkEntries)
+
+	destDir := filepath.Join(dir, "dest")
+	service := &MCPService{scanner: NewScanner()}
+	if err := extractZip(zipPath, destDir, service.limits.budget()); err == nil {
+		t.Error("expected an error for chained escaping symlinks")
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(err) {
+		t.Error("entry was written outside the destination")
+	}
+}
+
+func TestExtractZipRejectsPathTraversal(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		wantErr bool
+	}{
+		{"parent", "../evil.go", true},
+		{"nested parent", "src/../../../etc/cron.d/evil", true},
+		{"destination itself", "src/..", true},
+		{"inside", "src/../main.go", false},
+	}
+
+	service := &MCPService{scanner: NewScanner()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			zipPath := filepath.Join(dir, "archive.zip")
+			writeZip(t, zipPath, map[string]string{tt.entry: "// Copyright 2024 Acme Corp.\n"})
+
+			destDir := filepath.Join(dir, "dest", "tree")
+			if err := os.MkdirAll(destDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			err := extractZip(zipPath, destDir, service.limits.budget())
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("extractZip failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "illegal file path in archive") {
+				t.Fatalf("expected illegal file path error, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "dest", "evil.go")); !os.IsNotExist(err) {
+				t.Errorf("entry was written outside the destination")
+			}
+		})
+	}
+}
+
+func TestAnalyzeZipFileRetries(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{"main.go": "// Copyright 2024 Acme Corp.\n"})
+
+	tests := []struct {
+		name      string
+		failures  []error
+		timeout   time.Duration
+		wantCalls int
+		wantErr   bool
+	}{
+		{"transient failures", []error{
+			errors.New("server returned error: unavailable (status: 503)"),
+			&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+		}, 0, 3, false},
+		{"rate limited", []error{errors.New("server returned error: slow down (status: 429)")}, 0, 2, false},
+		{"auth failure", []error{errors.New("server returned error: unauthorized (status: 401)")}, 0, 1, true},
+		{"retries exhausted", []error{
+			errors.New("server returned error: a (status: 502)"),
+			errors.New("server returned error: b (status: 502)"),
+			errors.New("server returned error: c (status: 502)"),
+			errors.New("server returned error: d (status: 502)"),
+		}, 0, 4, true},
+		{"deadline before retry", []error{errors.New("server returned error: a (status: 500)")}, time.Millisecond, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			service := &MCPService{
+				scanner: NewScanner(),
+				mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+					calls++
+					if calls <= len(tt.failures) {
+						return nil, tt.failures[calls-1]
+					}
+					return promptReply("Acme Corp. holds all copyrights"), nil
+				}},
+				maxRetries:     3,
+				retryBaseDelay: time.Millisecond,
+			}
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				// The backoff exceeds the deadline, so no retry is started
+				service.retryBaseDelay = time.Hour
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			_, err := service.AnalyzeZipFile(ctx, zipPath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AnalyzeZipFile() error = %v, want error %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("GetPrompt called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAnalyzeZipFileTimeout(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{"main.go": "// Copyright 2024 Acme Corp.\n"})
+
+	service := &MCPService{
+		scanner: NewScanner(),
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			// A hung endpoint only returns once the request is abandoned
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}},
+		timeout: 10 * time.Millisecond,
+	}
+
+	_, err := service.AnalyzeZipFile(context.Background(), zipPath)
+	if !errors.Is(err, ErrMCPTimeout) || !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+
+	// Cancelling the caller's context isn't reported as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.AnalyzeZipFile(ctx, zipPath); errors.Is(err, ErrMCPTimeout) {
+		t.Errorf("expected no timeout error for a cancelled context, got %v", err)
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxChars int
+		want     []string
+	}{
+		{"a\nb\n", 10, []string{"a\nb\n"}},
+		{"aaa\nbbb\nccc\n", 8, []string{"aaa\nbbb\n", "ccc\n"}},
+		{"aaaaaaaaaa\nb\n", 4, []string{"aaaa", "aaaa", "aa\n", "b\n"}},
+		{"ééé", 3, []string{"é", "é", "é"}},
+	}
+	for _, tt := range tests {
+		if got := splitChunks(tt.text, tt.maxChars); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitChunks(%q, %d) = %q, want %q", tt.text, tt.maxChars, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeZipFileChunks(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{
+		"a.go": "// Copyright 2021 Acme Corp.\n",
+		"b.go": "// Copyright 2022 Example Inc.\n",
+		"c.go": "// Copyright 2023 Acme Corp. <legal@acme.example>\n",
+		"d.go": "// Copyright 2024 Jane Doe\n",
+	})
+
+	var prompts []string
+	service := &MCPService{
+		scanner: NewScanner(),
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			prompt := messages.([]*mcp.PromptMessage)[1].Content.TextContent.Text
+			prompts = append(prompts, prompt)
+			return promptReply(fmt.Sprintf("Analysis %d: %s", len(prompts), prompt)), nil
+		}},
+		maxChunkChars: 60,
+	}
+
+	result, err := service.AnalyzeZipFile(context.Background(), zipPath)
+	if err != nil {
+		t.Fatalf("AnalyzeZipFile failed: %v", err)
+	}
+	if len(prompts) < 3 {
+		t.Fatalf("expected several chunks and a consolidation, got %d prompts", len(prompts))
+	}
+	chunks := len(prompts) - 1
+	if !strings.Contains(result, fmt.Sprintf("AI Analysis (consolidated from %d chunks):", chunks)) {
+		t.Errorf("result doesn't mention the chunks:\n%s", result)
+	}
+
+	consolidation := prompts[chunks]
+	if !strings.Contains(consolidation, "Distinct copyright holders across all parts:\nAcme Corp\nExample Inc\nJane Doe\n\n") {
+		t.Errorf("consolidation prompt lacks the deduplicated holders:\n%s", consolidation)
+	}
+	for i := 1; i <= chunks; i++ {
+		if !strings.Contains(consolidation, fmt.Sprintf("Analysis of part %d:\nAnalysis %d:", i, i)) {
+			t.Errorf("consolidation prompt lacks the analysis of part %d:\n%s", i, consolidation)
+		}
+	}
+}
+
+func TestAnalyzeZipFileCache(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{
+		"a.go": "// Copyright 2021 Acme Corp.\n",
+	})
+
+	calls := 0
+	client := &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+		calls++
+		return promptReply(fmt.Sprintf("Analysis %d: Acme Corp 2021", calls)), nil
+	}}
+	cacheDir := t.TempDir()
+	newService := func(model string) *MCPService {
+		return &MCPService{scanner: NewScanner(), mcpClient: client, model: model, cacheDir: cacheDir}
+	}
+
+	tests := []struct {
+		name     string
+		model    string
+		calls    int
+		analysis string
+	}{
+		{"first analysis", "gpt-4", 1, "Analysis 1:"},
+		{"cached", "gpt-4", 1, "Analysis 1:"},
+		{"other model", "llama3", 2, "Analysis 2:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newService(tt.model).AnalyzeZipFile(context.Background(), zipPath)
+			if err != nil {
+				t.Fatalf("AnalyzeZipFile failed: %v", err)
+			}
+			if calls != tt.calls {
+				t.Errorf("expected %d model calls, got %d", tt.calls, calls)
+			}
+			if !strings.Contains(result, tt.analysis) {
+				t.Errorf("expected %q in result:\n%s", tt.analysis, result)
+			}
+		})
+	}
+}
+
+func TestAnalysisCacheKey(t *testing.T) {
+	info := "Copyright 2021 Acme Corp.\nCopyright 2022 Example Inc.\n"
+	base := analysisCacheKey("openai", "https://api.openai.com/v1", "gpt-4", info, false)
+	if got := analysisCacheKey("openai", "https://api.openai.com/v1/", "gpt-4", "Copyright 2021 Acme Corp.  \r\nCopyright 2022 Example Inc.\r\n\r\n", false); got != base {
+		t.Errorf("normalized text should share the key")
+	}
+	if got := analysisCacheKey("openai", "https://api.openai.com/v1", "llama3", info, false); got == base {
+		t.Errorf("models should not share the key")
+	}
+	if got := analysisCacheKey("openai", "https://api.openai.com/v1", "gpt-4", info, true); got == base {
+		t.Errorf("structured and free-text analyses should not share the key")
+	}
+	if got := analysisCacheKey("openai", "http://localhost:11434/v1", "gpt-4", info, false); got == base {
+		t.Errorf("base URLs should not share the key")
+	}
+	if got := analysisCacheKey("mcp", "https://api.openai.com/v1", "gpt-4", info, false); got == base {
+		t.Errorf("providers should not share the key")
+	}
+}
+
+func TestAnalysisBackend(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  *MCPService
+		provider string
+		baseURL  string
+	}{
+		{"mcp", &MCPService{endpoint: "https://mcp.example.com"}, "mcp", "https://mcp.example.com"},
+		{"openai", &MCPService{endpoint: "ignored", analyzer: NewOpenAIAnalyzer(OpenAIConfig{Endpoint: "http://localhost:11434/v1/"})}, "openai", "http://localhost:11434/v1"},
+		{"openai default", &MCPService{analyzer: NewOpenAIAnalyzer(OpenAIConfig{})}, "openai", DefaultOpenAIEndpoint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, baseURL := tt.service.analysisBackend()
+			if provider != tt.provider || baseURL != tt.baseURL {
+				t.Errorf("analysisBackend() = %q, %q, want %q, %q", provider, baseURL, tt.provider, tt.baseURL)
+			}
+		})
+	}
+}
+
+func TestAnalyzeZipFileScanOnly(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{
+		"a.go": "// Copyright 2021 Acme Corp.\n",
+	})
+
+	service := &MCPService{
+		scanner: NewScanner(),
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			t.Fatal("scan-only analysis called the model")
+			return nil, nil
+		}},
+		scanOnly: true,
+	}
+
+	result, err := service.AnalyzeZipFile(context.Background(), zipPath)
+	if err != nil {
+		t.Fatalf("AnalyzeZipFile failed: %v", err)
+	}
+	scanned, err := service.ScanZipFile(zipPath)
+	if err != nil {
+		t.Fatalf("ScanZipFile failed: %v", err)
+	}
+	if result != scanned {
+		t.Errorf("expected the scanned information, got:\n%s", result)
+	}
+	if !strings.Contains(result, "Copyright 2021 Acme Corp.") || strings.Contains(result, "AI Analysis") {
+		t.Errorf("unexpected scan-only result:\n%s", result)
+	}
+}
+
+func TestAnalyzeZipReader(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "archive.zip")
+	writeZip(t, zipPath, map[string]string{"src/main.go": "// Copyright 2024 Acme Corp.\n"})
+	tarPath := filepath.Join(dir, "archive.tar.gz")
+	writeTar(t, tarPath, map[string]string{"src/util.c": "/* Copyright 2023 Example Inc. */\n"}, true)
+
+	service := &MCPService{
+		scanner: NewScanner(),
+		mcpClient: &mockMCPClient{getPrompt: func(ctx context.Context, tool string, messages any) (*mcp.PromptResponse, error) {
+			return promptReply("Acme Corp. and Example Inc. hold the copyrights"), nil
+		}},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{zipPath, "Copyright 2024 Acme Corp."},
+		{tarPath, "Copyright 2023 Example Inc."},
+	}
+
+	for _, tt := range tests {
+		data, err := os.ReadFile(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := service.AnalyzeZipReader(context.Background(), bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("AnalyzeZipReader(%s) failed: %v", filepath.Base(tt.path), err)
+		}
+		if !strings.Contains(result, tt.want) || !strings.Contains(result, "Acme Corp. and Example Inc. hold the copyrights") {
+			t.Errorf("unexpected analysis of %s:\n%s", filepath.Base(tt.path), result)
+		}
+	}
+
+	// Content that isn't an archive fails without calling the model
+	data := []byte("not an archive")
+	if _, err := service.AnalyzeZipReader(context.Background(), bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Error("expected an error for content that isn't an archive")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// sectionSeparator is the line below the header of each report section
+const sectionSeparator = "----------------------------------------"
+
+// yearSectionPattern matches the headers of the sections written by GroupByYear
+var yearSectionPattern = regexp.MustCompile(`^(?:[0-9]{4}|No year):$`)
+
+// MergeOutputs merges the copyright statements of existing text reports into
+// one deduplicated list written to w, as a report of all of them would list
+// them. The template headers and the license, warning and compatibility
+// sections of the reports are skipped, while third-party statements keep
+// their own section. Reports ending in .gz are decompressed, and byte order
+// marks and CRLF line endings are accepted
+func MergeOutputs(paths []string, w io.Writer) error {
+	s := NewScanner()
+	var entries []CopyrightEntry
+	for _, path := range paths {
+		reportEntries, err := s.readReportEntries(path)
+		if err != nil {
+			return fmt.Errorf("failed to read report %s: %v", path, err)
+		}
+		entries = append(entries, reportEntries...)
+	}
+
+	_, err := io.WriteString(w, s.formatEntries(entries))
+	return err
+}
+
+// readReportEntries reads the copyright statements of a text report
+func (s *Scanner) readReportEntries(path string) ([]CopyrightEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip stream: %v", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	// Reports may be written with a byte order mark and CRLF line endings
+	var lines []string
+	bufferedReader := bufio.NewReader(reader)
+	if bom, err := bufferedReader.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		bufferedReader.Discard(len(utf8BOM))
+	}
+	lineScanner := bufio.NewScanner(bufferedReader)
+	lineScanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineScanner.Scan() {
+		lines = append(lines, strings.TrimRight(lineScanner.Text(), " \t\r"))
+	}
+	if err := lineScanner.Err(); err != nil {
+		return nil, err
+	}
+	return s.reportEntries(lines, path)
+}
+
+// reportEntries picks the copyright statements out of the lines of a text
+// report. Statements are read one line at a time, so the template and the
+// skipped sections can't run into them
+func (s *Scanner) reportEntries(lines []string, source string) ([]CopyrightEntry, error) {
+	var entries []CopyrightEntry
+	keep, thirdParty := true, false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		// A section header is followed by the separator line
+		if i+1 < len(lines) && lines[i+1] == sectionSeparator && strings.HasSuffix(line, ":") {
+			if line == "License Text:" {
+				// The license text always ends the report
+				break
+			}
+			// Year groups are nested in the first-party or third-party list
+			if !yearSectionPattern.MatchString(line) {
+				thirdParty = line == "Third-Party Copyrights:"
+				keep = thirdParty
+			}
+			i++
+			continue
+		}
+		if !keep || line == "" {
+			continue
+		}
+
+		copyright, err := s.extractCopyrightFromReader(strings.NewReader(line))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, s.fileEntries(copyright, source, "", "", thirdParty)...)
+	}
+	return entries, nil
+}
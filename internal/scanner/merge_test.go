@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeOutputs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"copyright_core.txt": "OPEN SOURCE SOFTWARE NOTICE\n\n" +
+			"Copyright Notice and License Texts\n\n" +
+			"Software: core\nCopyright notice:\n" +
+			"Copyright 2024 Acme Corp.\n" +
+			"Copyright (c) 2019 Jane Doe\n\n" +
+			"Third-Party Copyrights:\n----------------------------------------\n\n" +
+			"Copyright 2020 Example Inc.\n\n" +
+			"Warnings:\n----------------------------------------\n\n" +
+			"Missing year: Copyright Unknown Holder\n\n" +
+			"License Text:\n----------------------------------------\n\n" +
+			"MIT License\n\nCopyright (c) 2010 License Author\n",
+		"copyright_web.txt": "Software: web\nCopyright notice:\n" +
+			"\n2019:\n----------------------------------------\n\n" +
+			"copyright 2024 acme corp\n" +
+			"Copyright 2019 Jane Doe\n" +
+			"\nDetected Licenses:\n----------------------------------------\n\nMIT\n" +
+			"\nThird-Party Copyrights:\n----------------------------------------\n\n" +
+			"\n2021:\n----------------------------------------\n\n" +
+			"Copyright 2021 Vendor GmbH\n",
+	})
+
+	var got strings.Builder
+	paths := []string{filepath.Join(dir, "copyright_core.txt"), filepath.Join(dir, "copyright_web.txt")}
+	if err := MergeOutputs(paths, &got); err != nil {
+		t.Fatalf("MergeOutputs failed: %v", err)
+	}
+
+	want := "Copyright 2024 Acme Corp.\nCopyright (c) 2019 Jane Doe\n" +
+		"\nThird-Party Copyrights:\n----------------------------------------\n\n" +
+		"Copyright 2020 Example Inc.\nCopyright 2021 Vendor GmbH\n"
+	if got.String() != want {
+		t.Errorf("MergeOutputs() = %q, want %q", got.String(), want)
+	}
+
+	if err := MergeOutputs([]string{filepath.Join(dir, "missing.txt")}, &got); err == nil {
+		t.Error("expected an error for a missing report")
+	}
+}
+
+func TestMergeOutputsCompressed(t *testing.T) {
+	dir := t.TempDir()
+	path, err := WriteOutputFile(filepath.Join(dir, "copyright.txt"), []byte("Copyright 2024 Acme Corp.\n"), true)
+	if err != nil {
+		t.Fatalf("WriteOutputFile failed: %v", err)
+	}
+
+	var got strings.Builder
+	if err := MergeOutputs([]string{path}, &got); err != nil {
+		t.Fatalf("MergeOutputs failed: %v", err)
+	}
+	if want := "Copyright 2024 Acme Corp.\n"; got.String() != want {
+		t.Errorf("MergeOutputs() = %q, want %q", got.String(), want)
+	}
+}
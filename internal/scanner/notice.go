@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import "strings"
+
+// NoticeType is the kind of copyright notice a statement makes
+type NoticeType int
+
+const (
+	// NoticeUnknown is a statement that can't be classified, such as a
+	// holder found without a copyright marker
+	NoticeUnknown NoticeType = iota
+	// NoticeProprietary reserves all rights, in a file without an SPDX
+	// license tag
+	NoticeProprietary
+	// NoticeSPDXTagged is a statement in a file declaring its license with
+	// an SPDX-License-Identifier tag
+	NoticeSPDXTagged
+	// NoticeBareCopyright is a copyright line without a license or rights phrase
+	NoticeBareCopyright
+)
+
+// proprietaryPhrases reserve all rights to the holder
+var proprietaryPhrases = []string{
+	"all rights reserved",
+	"alle rechte vorbehalten",
+	"tous droits réservés",
+	"todos los derechos reservados",
+}
+
+// String returns the name of a notice type
+func (t NoticeType) String() string {
+	switch t {
+	case NoticeProprietary:
+		return "Proprietary"
+	case NoticeSPDXTagged:
+		return "SPDXTagged"
+	case NoticeBareCopyright:
+		return "BareCopyright"
+	default:
+		return "Unknown"
+	}
+}
+
+// MarshalText encodes the notice type by its name, as in JSON reports
+func (t NoticeType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes a notice type from its name, so JSON reports can be
+// read back; unknown names decode as NoticeUnknown
+func (t *NoticeType) UnmarshalText(text []byte) error {
+	for _, candidate := range []NoticeType{NoticeProprietary, NoticeSPDXTagged, NoticeBareCopyright} {
+		if string(text) == candidate.String() {
+			*t = candidate
+			return nil
+		}
+	}
+	*t = NoticeUnknown
+	return nil
+}
+
+// classifyNotice classifies a statement, including the lines joined to it
+// such as a following "All rights reserved.", using the SPDX license
+// expression its file declares, if any. A license tag makes the file open
+// whatever rights its statements reserve
+func classifyNotice(statement, license string) NoticeType {
+	if license != "" {
+		return NoticeSPDXTagged
+	}
+	lower := strings.ToLower(statement)
+	for _, phrase := range proprietaryPhrases {
+		if strings.Contains(lower, phrase) {
+			return NoticeProprietary
+		}
+	}
+	for _, marker := range copyrightMarkers {
+		if strings.Contains(lower, marker) {
+			return NoticeBareCopyright
+		}
+	}
+	if coprPattern.MatchString(lower) {
+		return NoticeBareCopyright
+	}
+	return NoticeUnknown
+}
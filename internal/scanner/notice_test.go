@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import "testing"
+
+func TestClassifyNotice(t *testing.T) {
+	tests := []struct {
+		statement string
+		license   string
+		want      NoticeType
+	}{
+		{"Copyright 2024 Acme Corp. All rights reserved.", "", NoticeProprietary},
+		{"Copyright 2024 Acme GmbH. Alle Rechte vorbehalten.", "", NoticeProprietary},
+		{"Copyright 2024 Acme Corp. All rights reserved.", "MIT", NoticeSPDXTagged},
+		{"Copyright 2024 Acme Corp.", "Apache-2.0", NoticeSPDXTagged},
+		{"Copyright 2024 Acme Corp.", "", NoticeBareCopyright},
+		{"Portions copyright 2019 Jane Doe", "", NoticeBareCopyright},
+		{"Acme Corp", "", NoticeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := classifyNotice(tt.statement, tt.license); got != tt.want {
+			t.Errorf("classifyNotice(%q, %q) = %v, want %v", tt.statement, tt.license, got, tt.want)
+		}
+	}
+}
+
+func TestScanDirectoryNoticeTypes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go": "// Copyright 2024 Acme Corp.\n// All rights reserved.\npackage main\n",
+		"b.go": "// Copyright 2024 Acme Corp.\n// All rights reserved.\n\n// SPDX-License-Identifier: MIT\npackage main\n",
+		"c.go": "// Copyright 2024 Jane Doe\npackage main\n",
+	})
+
+	entries, err := NewScanner().ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []NoticeType{NoticeProprietary, NoticeSPDXTagged, NoticeBareCopyright}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.NoticeType != want[i] {
+			t.Errorf("%s: notice type %v, want %v", entry.SourceFile, entry.NoticeType, want[i])
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultOllamaEndpoint is the OpenAI-compatible API of a local Ollama instance
+const DefaultOllamaEndpoint = "http://localhost:11434/v1"
+
+// DefaultOpenAIEndpoint is the OpenAI API
+const DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+
+// OpenAIConfig holds the configuration of an OpenAI-compatible chat completions API
+type OpenAIConfig struct {
+	// Endpoint is the base URL of the API, DefaultOpenAIEndpoint if unset
+	Endpoint string
+	// APIKey is sent as a bearer token, if set. A local Ollama needs none
+	APIKey string
+	Model  string
+	// Timeout limits each request, DefaultMCPTimeout if unset
+	Timeout time.Duration
+}
+
+// OpenAIAnalyzer analyzes copyright information through an OpenAI-compatible
+// chat completions API, such as OpenAI or Ollama
+type OpenAIAnalyzer struct {
+	endpoint string
+	apiKey   string
+	model    string
+	client   *http.Client
+}
+
+// NewOpenAIAnalyzer creates an analyzer for an OpenAI-compatible API
+func NewOpenAIAnalyzer(config OpenAIConfig) *OpenAIAnalyzer {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultOpenAIEndpoint
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultMCPTimeout
+	}
+	return &OpenAIAnalyzer{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		apiKey:   config.APIKey,
+		model:    config.Model,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// chatMessage is a message of a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest is the body of a chat completions request
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+// chatResponse is the part of a chat completions response read by the analyzer
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Analyze requests an analysis of copyrightInfo from the chat completions
+// API in a single request. It implements Analyzer
+func (a *OpenAIAnalyzer) Analyze(ctx context.Context, copyrightInfo string) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: a.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: instructionsFrom(ctx)},
+			{Role: "user", Content: analysisPrompt(copyrightInfo)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	// The status is reported like the MCP transport does, so retries apply alike
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("server returned error: %s (status: %d)", strings.TrimSpace(string(data)), resp.StatusCode)
+	}
+
+	var response chatResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+	return response.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenAIAnalyzer(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/chat/completions" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want the bearer token", got)
+		}
+
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Model != "llama3" || len(req.Messages) != 2 || req.Messages[0].Role != "system" ||
+			!strings.Contains(req.Messages[1].Content, "Copyright 2024 Acme Corp.") {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+
+		// The first request fails transiently
+		if requests == 1 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": "Acme Corp. holds all copyrights"}}},
+		})
+	}))
+	defer server.Close()
+
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{"main.go": "// Copyright 2024 Acme Corp.\n"})
+
+	service := &MCPService{
+		scanner:        NewScanner(),
+		analyzer:       NewOpenAIAnalyzer(OpenAIConfig{Endpoint: server.URL + "/v1/", APIKey: "secret", Model: "llama3"}),
+		maxRetries:     1,
+		retryBaseDelay: time.Millisecond,
+	}
+	result, err := service.AnalyzeZipFile(context.Background(), zipPath)
+	if err != nil {
+		t.Fatalf("AnalyzeZipFile failed: %v", err)
+	}
+	if !strings.Contains(result, "AI Analysis:\n-----------\nAcme Corp. holds all copyrights") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+
+	// Client errors aren't retried
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+	})
+	analyzer := NewOpenAIAnalyzer(OpenAIConfig{Endpoint: server.URL + "/v1", Model: "gpt-4"})
+	if _, err := analyzer.Analyze(context.Background(), "Copyright 2024 Acme Corp."); err == nil || retryableError(err) {
+		t.Errorf("expected a non-retryable error, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+// Option configures a Scanner created by NewScannerWithOptions
+type Option func(*Scanner)
+
+// NewScannerWithOptions creates a new scanner instance configured by opts,
+// which are applied in order. Without options it equals NewScanner()
+func NewScannerWithOptions(opts ...Option) *Scanner {
+	s := NewScanner()
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithConcurrency sets the number of files scanned at once, see Scanner.Concurrency
+func WithConcurrency(n int) Option {
+	return func(s *Scanner) {
+		s.Concurrency = n
+	}
+}
+
+// WithMaxScanBytes sets the number of bytes read from each file, see Scanner.MaxScanBytes
+func WithMaxScanBytes(n int64) Option {
+	return func(s *Scanner) {
+		s.MaxScanBytes = n
+	}
+}
+
+// WithSkipFilesLargerThan skips files above a size, see Scanner.SkipFilesLargerThan
+func WithSkipFilesLargerThan(n int64) Option {
+	return func(s *Scanner) {
+		s.SkipFilesLargerThan = n
+	}
+}
+
+// WithHeaderLinesOnly stops reading each file after n lines, see Scanner.HeaderLinesOnly
+func WithHeaderLinesOnly(n int) Option {
+	return func(s *Scanner) {
+		s.HeaderLinesOnly = n
+	}
+}
+
+// WithExcludeDirs adds directory names to skip, see Scanner.ExcludeDirs
+func WithExcludeDirs(dirs ...string) Option {
+	return func(s *Scanner) {
+		s.ExcludeDirs = append(s.ExcludeDirs, dirs...)
+	}
+}
+
+// WithExcludePatterns adds glob patterns of paths to skip, see Scanner.ExcludePatterns
+func WithExcludePatterns(patterns ...string) Option {
+	return func(s *Scanner) {
+		s.ExcludePatterns = append(s.ExcludePatterns, patterns...)
+	}
+}
+
+// WithRespectGitignore skips the paths ignored by .gitignore files, see Scanner.RespectGitignore
+func WithRespectGitignore() Option {
+	return func(s *Scanner) {
+		s.RespectGitignore = true
+	}
+}
+
+// WithFollowSymlinks traverses symlinked directories, see Scanner.FollowSymlinks
+func WithFollowSymlinks() Option {
+	return func(s *Scanner) {
+		s.FollowSymlinks = true
+	}
+}
+
+// WithTextDetectionBytes sets the sample size of the text detection, see Scanner.TextDetectionBytes
+func WithTextDetectionBytes(n int) Option {
+	return func(s *Scanner) {
+		s.TextDetectionBytes = n
+	}
+}
+
+// WithIncludeExtensions adds file extensions to limit scans to, see Scanner.IncludeExtensions
+func WithIncludeExtensions(exts ...string) Option {
+	return func(s *Scanner) {
+		s.IncludeExtensions = append(s.IncludeExtensions, exts...)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewScannerWithOptions(t *testing.T) {
+	if !reflect.DeepEqual(NewScannerWithOptions(), NewScanner()) {
+		t.Error("NewScannerWithOptions() without options differs from NewScanner()")
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".gitignore":         "ignored.go\n",
+		"ignored.go":         "// Copyright 2024 Ignored Inc.\n",
+		"main.go":            "// Copyright 2024 Acme Corp.\n",
+		"late.go":            "package main\n\n// Copyright 2024 Late Ltd.\n",
+		"big.go":             "// Copyright 2024 Big Inc.\n" + strings.Repeat("//\n", 100),
+		"vendor/lib.go":      "// Copyright 2024 Vendor Inc.\n",
+		"gen/code.pb.go":     "// Copyright 2024 Generated Inc.\n",
+		"noise.txt":          "// Copyright 2024 Noise Inc.\n\n" + strings.Repeat("\x01", 10) + strings.Repeat("\ntext", 30),
+		"tail/after_pad.txt": strings.Repeat("x", 100) + "\n// Copyright 2024 Tail Inc.\n",
+	})
+
+	all := []string{"Big Inc", "Generated Inc", "Ignored Inc", "Late Ltd", "Acme Corp", "Noise Inc", "Tail Inc", "Vendor Inc"}
+	without := func(excluded string) []string {
+		var remaining []string
+		for _, holder := range all {
+			if holder != excluded {
+				remaining = append(remaining, holder)
+			}
+		}
+		return remaining
+	}
+
+	tests := []struct {
+		name string
+		opt  Option
+		want []string
+	}{
+		{"none", WithConcurrency(1), all},
+		{"max scan bytes", WithMaxScanBytes(50), without("Tail Inc")},
+		{"skip larger files", WithSkipFilesLargerThan(200), without("Big Inc")},
+		{"header lines", WithHeaderLinesOnly(2), without("Late Ltd")},
+		{"exclude dirs", WithExcludeDirs("vendor"), without("Vendor Inc")},
+		{"exclude patterns", WithExcludePatterns("gen/*.pb.go"), without("Generated Inc")},
+		{"gitignore", WithRespectGitignore(), without("Ignored Inc")},
+		{"text detection", WithTextDetectionBytes(40), without("Noise Inc")},
+		{"include extensions", WithIncludeExtensions(".txt"), []string{"Noise Inc", "Tail Inc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := NewScannerWithOptions(tt.opt).ScanDirectoryStructured(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var holders []string
+			for _, entry := range entries {
+				holders = append(holders, entry.Holder)
+			}
+			if !reflect.DeepEqual(holders, tt.want) {
+				t.Errorf("holders = %q, want %q", holders, tt.want)
+			}
+		})
+	}
+
+	s := NewScannerWithOptions(WithConcurrency(3), WithExcludeDirs("a"), WithExcludeDirs("b", "c"))
+	if s.Concurrency != 3 || !reflect.DeepEqual(s.ExcludeDirs, []string{"a", "b", "c"}) {
+		t.Errorf("options not applied in order: Concurrency=%d ExcludeDirs=%q", s.Concurrency, s.ExcludeDirs)
+	}
+}
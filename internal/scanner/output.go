@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Line endings of the files written, see Scanner.OutputLineEnding
+const (
+	LineEndingLF   = "lf"
+	LineEndingCRLF = "crlf"
+)
+
+// EncodeOutput converts a report built with "\n" line endings to the
+// OutputLineEnding and OutputBOM of the scanner, as written to output files
+func (s *Scanner) EncodeOutput(report []byte) ([]byte, error) {
+	switch s.OutputLineEnding {
+	case "", LineEndingLF:
+	case LineEndingCRLF:
+		// License texts may already use CRLF, which mustn't become CRCRLF
+		report = bytes.ReplaceAll(report, []byte("\r\n"), []byte("\n"))
+		report = bytes.ReplaceAll(report, []byte("\n"), []byte("\r\n"))
+	default:
+		return nil, fmt.Errorf("unknown line ending %q, expected %s or %s", s.OutputLineEnding, LineEndingLF, LineEndingCRLF)
+	}
+
+	if s.OutputBOM && !bytes.HasPrefix(report, utf8BOM) {
+		report = append(append([]byte{}, utf8BOM...), report...)
+	}
+	return report, nil
+}
+
+// WriteOutputFile atomically writes data to path by writing a temporary file
+// next to it and renaming it into place. The data is gzip-compressed when
+// compress is set or the path ends in .gz, and a missing .gz suffix is added
+// when compress is set. It returns the path actually written
+func WriteOutputFile(path string, data []byte, compress bool) (string, error) {
+	if compress && !strings.HasSuffix(path, ".gz") {
+		path += ".gz"
+	}
+	compress = strings.HasSuffix(path, ".gz")
+
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file for %s: %v", path, err)
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath)
+
+	if err := writeData(tempFile, data, compress); err != nil {
+		tempFile.Close()
+		return "", fmt.Errorf("failed to write file %s: %v", path, err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to write file %s: %v", path, err)
+	}
+
+	// Temp files are created private, use the usual output permissions
+	if err := os.Chmod(tempPath, 0644); err != nil {
+		return "", fmt.Errorf("failed to write file %s: %v", path, err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return "", fmt.Errorf("failed to write file %s: %v", path, err)
+	}
+
+	return path, nil
+}
+
+// writeData writes data to w, gzip-compressed if requested
+func writeData(w io.Writer, data []byte, compress bool) error {
+	if !compress {
+		_, err := w.Write(data)
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	if _, err := gzipWriter.Write(data); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteOutputFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		compress    bool
+		wantFile    string
+		wantGzipped bool
+	}{
+		{"plain", "out.txt", false, "out.txt", false},
+		{"gz name", "out.txt.gz", false, "out.txt.gz", true},
+		{"flag adds suffix", "out.txt", true, "out.txt.gz", true},
+	}
+
+	data := []byte("Copyright 2024 Acme Corp.\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			written, err := WriteOutputFile(filepath.Join(dir, tt.file), data, tt.compress)
+			if err != nil {
+				t.Fatalf("WriteOutputFile failed: %v", err)
+			}
+			if written != filepath.Join(dir, tt.wantFile) {
+				t.Errorf("written to %s, want %s", written, tt.wantFile)
+			}
+
+			file, err := os.Open(written)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			var reader io.Reader = file
+			if tt.wantGzipped {
+				gzipReader, err := gzip.NewReader(file)
+				if err != nil {
+					t.Fatalf("output is not gzip-compressed: %v", err)
+				}
+				reader = gzipReader
+			}
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(data) {
+				t.Errorf("content = %q, want %q", got, data)
+			}
+
+			// No temp files are left behind
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 {
+				t.Errorf("expected only the output file, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestEncodeOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		lineEnding string
+		bom        bool
+		report     string
+		want       string
+	}{
+		{"default", "", false, "a\nb\n", "a\nb\n"},
+		{"lf", LineEndingLF, false, "a\nb\n", "a\nb\n"},
+		{"crlf", LineEndingCRLF, false, "a\nb\n", "a\r\nb\r\n"},
+		{"crlf keeps existing crlf", LineEndingCRLF, false, "a\r\nb\n", "a\r\nb\r\n"},
+		{"bom", "", true, "a\n", "\xef\xbb\xbfa\n"},
+		{"crlf and bom", LineEndingCRLF, true, "a\n", "\xef\xbb\xbfa\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner()
+			s.OutputLineEnding = tt.lineEnding
+			s.OutputBOM = tt.bom
+			got, err := s.EncodeOutput([]byte(tt.report))
+			if err != nil {
+				t.Fatalf("EncodeOutput failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeOutput(%q) = %q, want %q", tt.report, got, tt.want)
+			}
+		})
+	}
+
+	s := NewScanner()
+	s.OutputLineEnding = "cr"
+	if _, err := s.EncodeOutput([]byte("a\n")); err == nil {
+		t.Error("expected an error for an unknown line ending")
+	}
+}
+
+func TestScanSubDirectoriesCRLFRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"core/main.go":         "// Copyright 2024 Acme Corp.\n// Copyright (c) 2019 Jane Doe\n",
+		"core/vendor/dep/a.go": "// Copyright 2020 Example Inc.\n",
+	})
+
+	scan := func(lineEnding string, bom bool) string {
+		t.Helper()
+		s := NewScanner()
+		s.NoTemplate = true
+		s.ThirdPartyDirs = []string{"vendor"}
+		s.OutputLineEnding = lineEnding
+		s.OutputBOM = bom
+		pattern := filepath.Join(t.TempDir(), "copyright_{name}.txt")
+		if err := s.ScanSubDirectories(root, pattern); err != nil {
+			t.Fatalf("ScanSubDirectories failed: %v", err)
+		}
+		return OutputFileName(pattern, "core")
+	}
+
+	lfFile, crlfFile := scan(LineEndingLF, false), scan(LineEndingCRLF, true)
+	lf, err := os.ReadFile(lfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crlf, err := os.ReadFile(crlfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(crlf, utf8BOM) {
+		t.Errorf("CRLF report lacks the byte order mark: %q", crlf)
+	}
+	if bytes.Count(crlf, []byte("\n")) != bytes.Count(crlf, []byte("\r\n")) {
+		t.Errorf("CRLF report has bare LF line endings: %q", crlf)
+	}
+	decoded := bytes.ReplaceAll(bytes.TrimPrefix(crlf, utf8BOM), []byte("\r\n"), []byte("\n"))
+	if !bytes.Equal(decoded, lf) {
+		t.Errorf("decoded CRLF report = %q, want the LF report %q", decoded, lf)
+	}
+
+	// Merging reads the statements of both reports alike
+	var fromLF, fromCRLF strings.Builder
+	if err := MergeOutputs([]string{lfFile}, &fromLF); err != nil {
+		t.Fatalf("MergeOutputs failed: %v", err)
+	}
+	if err := MergeOutputs([]string{crlfFile}, &fromCRLF); err != nil {
+		t.Fatalf("MergeOutputs failed: %v", err)
+	}
+	if fromCRLF.String() != fromLF.String() || !strings.Contains(fromLF.String(), "Copyright 2020 Example Inc.") {
+		t.Errorf("merged CRLF report = %q, want %q", fromCRLF.String(), fromLF.String())
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy lists the copyright holders and licenses a release may contain.
+// Patterns are globs as in path.Match, matched against the whole holder name
+// or SPDX license ID ignoring case, so "Acme*" allows "Acme Corp." and
+// "GPL-*" denies every GPL version. A denied pattern wins over an allowed one,
+// and empty allow lists allow everything that isn't denied
+type Policy struct {
+	AllowedHolders  []string `json:"allowedHolders,omitempty" yaml:"allowedHolders"`
+	DeniedHolders   []string `json:"deniedHolders,omitempty" yaml:"deniedHolders"`
+	AllowedLicenses []string `json:"allowedLicenses,omitempty" yaml:"allowedLicenses"`
+	DeniedLicenses  []string `json:"deniedLicenses,omitempty" yaml:"deniedLicenses"`
+}
+
+// Violation is an entry that breaks a Policy
+type Violation struct {
+	File string `json:"file"`
+	// Holder or License is the offending holder or license ID
+	Holder  string `json:"holder,omitempty"`
+	License string `json:"license,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// String describes the violation with its file
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.File, v.Reason)
+}
+
+// LoadPolicy reads a policy from a .yaml or .yml file, or from a JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open policy: %v", err)
+	}
+	defer file.Close()
+
+	format := "json"
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		format = "yaml"
+	}
+	return ParsePolicy(file, format)
+}
+
+// ParsePolicy reads a policy as "json" or "yaml". Unknown fields, such as a
+// misspelled list, are rejected rather than silently ignored
+func ParsePolicy(r io.Reader, format string) (*Policy, error) {
+	var policy Policy
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&policy); err != nil {
+			return nil, fmt.Errorf("failed to parse policy: %v", err)
+		}
+	case "yaml":
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&policy); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse policy: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported policy format: %s", format)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Validate checks that every pattern of the policy is a valid glob
+func (p *Policy) Validate() error {
+	for _, patterns := range [][]string{p.AllowedHolders, p.DeniedHolders, p.AllowedLicenses, p.DeniedLicenses} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid policy pattern %q: %v", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the violations of the policy by the given entries, in
+// entry order. Each holder of a holder list is checked on its own, and each
+// license ID of an entry's SPDX expression, so "MIT OR GPL-3.0" violates a
+// policy denying GPL-3.0. A license is reported once per file
+func (p *Policy) Evaluate(entries []CopyrightEntry) []Violation {
+	var violations []Violation
+	seenLicenses := make(map[[2]string]bool)
+
+	for _, entry := range entries {
+		for _, holder := range splitHolderList(entry.Holder) {
+			if reason := p.check("holder", holder, holderKey, p.AllowedHolders, p.DeniedHolders); reason != "" {
+				violations = append(violations, Violation{File: entry.SourceFile, Holder: holder, Reason: reason})
+			}
+		}
+
+		for _, id := range spdxLicenseIDs(entry.License) {
+			key := [2]string{entry.SourceFile, id}
+			if seenLicenses[key] {
+				continue
+			}
+			seenLicenses[key] = true
+			if reason := p.check("license", id, licenseKey, p.AllowedLicenses, p.DeniedLicenses); reason != "" {
+				violations = append(violations, Violation{File: entry.SourceFile, License: id, Reason: reason})
+			}
+		}
+	}
+	return violations
+}
+
+// check returns why the holder or license name breaks the allowed and
+// denied patterns, or "" if it doesn't. Both the name and the patterns are
+// compared in the form normalized by key
+func (p *Policy) check(kind, name string, key func(string) string, allowed, denied []string) string {
+	if pattern, ok := matchPolicyPattern(name, denied, key); ok {
+		return fmt.Sprintf("%s %s is denied by %q", kind, name, pattern)
+	}
+	if len(allowed) == 0 {
+		return ""
+	}
+	if _, ok := matchPolicyPattern(name, allowed, key); !ok {
+		return fmt.Sprintf("%s %s is not allowed", kind, name)
+	}
+	return ""
+}
+
+// matchPolicyPattern returns the first of the patterns matching name
+func matchPolicyPattern(name string, patterns []string, key func(string) string) (string, bool) {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(key(pattern), key(name)); matched {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// licenseKey normalizes an SPDX license ID, whose case doesn't matter
+func licenseKey(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
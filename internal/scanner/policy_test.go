@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+	want := &Policy{
+		AllowedHolders: []string{"Acme*"},
+		DeniedLicenses: []string{"GPL-*", "AGPL-3.0"},
+	}
+
+	jsonPolicy, err := ParsePolicy(strings.NewReader(`{"allowedHolders": ["Acme*"], "deniedLicenses": ["GPL-*", "AGPL-3.0"]}`), "json")
+	if err != nil {
+		t.Fatalf("ParsePolicy(json) failed: %v", err)
+	}
+	if !reflect.DeepEqual(jsonPolicy, want) {
+		t.Errorf("ParsePolicy(json) = %+v, want %+v", jsonPolicy, want)
+	}
+
+	yamlPolicy, err := ParsePolicy(strings.NewReader("allowedHolders:\n  - Acme*\ndeniedLicenses: [GPL-*, AGPL-3.0]\n"), "yaml")
+	if err != nil {
+		t.Fatalf("ParsePolicy(yaml) failed: %v", err)
+	}
+	if !reflect.DeepEqual(yamlPolicy, want) {
+		t.Errorf("ParsePolicy(yaml) = %+v, want %+v", yamlPolicy, want)
+	}
+
+	for _, tt := range []struct{ input, format string }{
+		{`{"deniedHolder": ["Acme"]}`, "json"},
+		{"deniedHolder: [Acme]\n", "yaml"},
+		{`{"deniedHolders": ["[Acme"]}`, "json"},
+		{"", "toml"},
+	} {
+		if _, err := ParsePolicy(strings.NewReader(tt.input), tt.format); err == nil {
+			t.Errorf("ParsePolicy(%q, %s) succeeded, expected an error", tt.input, tt.format)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"policy.yml":  "deniedHolders: [Evil Corp]\n",
+		"policy.json": `{"deniedHolders": ["Evil Corp"]}`,
+	})
+
+	for _, name := range []string{"policy.yml", "policy.json"} {
+		policy, err := LoadPolicy(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("LoadPolicy(%s) failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(policy.DeniedHolders, []string{"Evil Corp"}) {
+			t.Errorf("LoadPolicy(%s) = %+v", name, policy)
+		}
+	}
+
+	if _, err := LoadPolicy(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error for a missing policy")
+	}
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	entries := []CopyrightEntry{
+		{Holder: "Acme Corp.", SourceFile: "src/main.c", License: "MIT"},
+		{Holder: "Acme Corp.", SourceFile: "src/util.c", License: "MIT OR GPL-3.0"},
+		{Holder: "Jane Doe", SourceFile: "src/util.c", License: "MIT OR GPL-3.0"},
+		{Holder: "Alice Liddell, Evil Corp and Acme Inc.", SourceFile: "vendor/lib.c", License: "Apache-2.0 WITH LLVM-exception"},
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		want   []Violation
+	}{
+		{
+			name:   "empty policy",
+			policy: Policy{},
+		},
+		{
+			name:   "denied holder in a list",
+			policy: Policy{DeniedHolders: []string{"evil corp"}},
+			want: []Violation{
+				{File: "vendor/lib.c", Holder: "Evil Corp", Reason: `holder Evil Corp is denied by "evil corp"`},
+			},
+		},
+		{
+			name:   "allowed holders",
+			policy: Policy{AllowedHolders: []string{"Acme*", "Alice *"}},
+			want: []Violation{
+				{File: "src/util.c", Holder: "Jane Doe", Reason: "holder Jane Doe is not allowed"},
+				{File: "vendor/lib.c", Holder: "Evil Corp", Reason: "holder Evil Corp is not allowed"},
+			},
+		},
+		{
+			name:   "denied wins over allowed",
+			policy: Policy{AllowedHolders: []string{"*"}, DeniedHolders: []string{"Acme Corp."}},
+			want: []Violation{
+				{File: "src/main.c", Holder: "Acme Corp", Reason: `holder Acme Corp is denied by "Acme Corp."`},
+				{File: "src/util.c", Holder: "Acme Corp", Reason: `holder Acme Corp is denied by "Acme Corp."`},
+			},
+		},
+		{
+			name:   "denied license once per file",
+			policy: Policy{DeniedLicenses: []string{"gpl-*"}},
+			want: []Violation{
+				{File: "src/util.c", License: "GPL-3.0", Reason: `license GPL-3.0 is denied by "gpl-*"`},
+			},
+		},
+		{
+			name:   "allowed licenses ignore exceptions",
+			policy: Policy{AllowedLicenses: []string{"MIT", "Apache-2.0"}},
+			want: []Violation{
+				{File: "src/util.c", License: "GPL-3.0", Reason: "license GPL-3.0 is not allowed"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Evaluate(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// spdxNamespaceBase prefixes the unique namespace of generated SPDX documents
+const spdxNamespaceBase = "https://spdx.org/spdxdocs/"
+
+// spdxNamePattern matches the characters that are not allowed in a namespace URI segment
+var spdxNamePattern = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// WriteSPDX writes an SPDX 2.3 tag-value document describing the software as a
+// single package, with the aggregated copyright text and the declared
+// licenses of the entries as a normalized SPDX expression. Licenses outside
+// the SPDX License List are declared as LicenseRef IDs, each defined in an
+// extracted licensing info section holding its name
+func WriteSPDX(w io.Writer, software string, entries []CopyrightEntry) error {
+	uuid, err := newUUID()
+	if err != nil {
+		return err
+	}
+	if software == "" {
+		software = "NOASSERTION"
+	}
+	namespace := spdxNamespaceBase + strings.Trim(spdxNamePattern.ReplaceAllString(software, "-"), "-") + "-" + uuid
+
+	copyrightText := "NOASSERTION"
+	if text := aggregateCopyrights(entries); text != "" {
+		copyrightText = spdxText(text)
+	}
+	declared, refs := declaredLicense(entries)
+
+	_, err = fmt.Fprintf(w, `SPDXVersion: SPDX-2.3
+DataLicense: CC0-1.0
+SPDXID: SPDXRef-DOCUMENT
+DocumentName: %s
+DocumentNamespace: %s
+Creator: Tool: Nemesis
+Created: %s
+
+PackageName: %s
+SPDXID: SPDXRef-Package
+PackageDownloadLocation: NOASSERTION
+FilesAnalyzed: false
+PackageLicenseConcluded: NOASSERTION
+PackageLicenseDeclared: %s
+PackageCopyrightText: %s
+
+Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package
+`, software, namespace, time.Now().UTC().Format(time.RFC3339), software, declared, copyrightText)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if _, err := fmt.Fprintf(w, "\nLicenseID: %s\nExtractedText: %s\nLicenseName: %s\n", ref.id, spdxText(ref.name), ref.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spdxText wraps a multi-line tag-value in <text> tags
+func spdxText(text string) string {
+	// The text value can't contain its own closing tag
+	return "<text>" + strings.ReplaceAll(text, "</text>", "&lt;/text>") + "</text>"
+}
+
+// cycloneDXBOM is the subset of a CycloneDX 1.5 BOM written by WriteCycloneDX
+type cycloneDXBOM struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cycloneDXMetadata    `json:"metadata"`
+	Components   []cycloneDXComponent `json:"components"`
+}
+
+// cycloneDXMetadata describes when and by which tool a BOM was created
+type cycloneDXMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cycloneDXComponent `json:"components"`
+	} `json:"tools"`
+}
+
+// cycloneDXComponent is a software component of a BOM
+type cycloneDXComponent struct {
+	Type      string             `json:"type"`
+	BOMRef    string             `json:"bom-ref,omitempty"`
+	Name      string             `json:"name"`
+	Copyright string             `json:"copyright,omitempty"`
+	Licenses  []cycloneDXLicense `json:"licenses,omitempty"`
+}
+
+// cycloneDXLicense is a license choice, either a single license or an SPDX
+// expression
+type cycloneDXLicense struct {
+	License    *cycloneDXLicenseID `json:"license,omitempty"`
+	Expression string              `json:"expression,omitempty"`
+}
+
+// cycloneDXLicenseID names a license by its SPDX license ID or, for a
+// license outside the SPDX License List, by its name
+type cycloneDXLicenseID struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// WriteCycloneDX writes a CycloneDX 1.5 JSON BOM describing the software as a
+// single component, with the aggregated copyright text and the licenses of
+// the entries. Licenses on the SPDX License List are given by their id and
+// others by their name; if any file declares a compound expression, the
+// licenses are given as one SPDX expression instead, as CycloneDX doesn't
+// allow mixing both
+func WriteCycloneDX(w io.Writer, software string, entries []CopyrightEntry) error {
+	uuid, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	// A component must be named, even for a scanned file list
+	if software == "" {
+		software = "unnamed"
+	}
+
+	component := cycloneDXComponent{
+		Type:      "application",
+		BOMRef:    "component-1",
+		Name:      software,
+		Copyright: aggregateCopyrights(entries),
+		Licenses:  cycloneDXLicenses(entries),
+	}
+
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid,
+		Version:      1,
+		Components:   []cycloneDXComponent{component},
+	}
+	bom.Metadata.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	bom.Metadata.Tools.Components = []cycloneDXComponent{{Type: "application", Name: "Nemesis"}}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bom)
+}
+
+// cycloneDXLicenses returns the license choices of the entries, one per
+// distinct license or a single expression if any of them is compound, which
+// includes "or later" forms such as GPL-2.0+
+func cycloneDXLicenses(entries []CopyrightEntry) []cycloneDXLicense {
+	licenses := entryLicenses(entries)
+	for _, license := range licenses {
+		if strings.ContainsAny(license, " ()+") {
+			declared, _ := declaredLicense(entries)
+			return []cycloneDXLicense{{Expression: declared}}
+		}
+	}
+
+	var choices []cycloneDXLicense
+	seen := make(map[string]bool)
+	for _, license := range licenses {
+		// The id must be on the SPDX License List, LicenseRefs are names
+		id := &cycloneDXLicenseID{Name: license}
+		if canonical, ok := knownSPDXLicense(license); ok {
+			id = &cycloneDXLicenseID{ID: canonical}
+		}
+		if key := id.ID + "\x00" + id.Name; !seen[key] {
+			seen[key] = true
+			choices = append(choices, cycloneDXLicense{License: id})
+		}
+	}
+	return choices
+}
+
+// aggregateCopyrights joins the distinct statements of the entries into lines
+func aggregateCopyrights(entries []CopyrightEntry) string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if key := entry.dedupKey(); !seen[key] {
+			seen[key] = true
+			lines = append(lines, entry.RawText)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// entryLicenses returns the distinct license expressions of the entries, in order
+func entryLicenses(entries []CopyrightEntry) []string {
+	var licenses []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.License != "" && !seen[entry.License] {
+			seen[entry.License] = true
+			licenses = append(licenses, entry.License)
+		}
+	}
+	return licenses
+}
+
+// declaredLicense combines the license expressions of the entries into a
+// single normalized SPDX expression, NOASSERTION if there are none, and
+// returns the licenses it refers to by LicenseRef IDs
+func declaredLicense(entries []CopyrightEntry) (string, []licenseRef) {
+	var licenses []string
+	var refs []licenseRef
+	seen := make(map[string]bool)
+	for _, license := range entryLicenses(entries) {
+		normalized, licenseRefs := normalizeSPDXExpression(license)
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		licenses = append(licenses, normalized)
+		for _, ref := range licenseRefs {
+			refs = appendLicenseRef(refs, ref)
+		}
+	}
+
+	switch len(licenses) {
+	case 0:
+		return "NOASSERTION", nil
+	case 1:
+		return licenses[0], refs
+	}
+
+	// Every file's license applies, so compound expressions are grouped
+	for i, license := range licenses {
+		if strings.Contains(license, " ") {
+			licenses[i] = "(" + license + ")"
+		}
+	}
+	return strings.Join(licenses, " AND "), refs
+}
+
+// newUUID generates a random version 4 UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate UUID: %v", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
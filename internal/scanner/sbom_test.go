@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWriteSPDX(t *testing.T) {
+	entries := []CopyrightEntry{
+		{RawText: "Copyright 2024 Acme Corp.", License: "MIT"},
+		{RawText: "Copyright 2024 Acme Corp.", License: "MIT"},
+		{RawText: "Copyright 2023 Jane Doe", License: "Apache-2.0 OR MIT"},
+		{RawText: "Copyright 2022 Example Inc.", License: "apache-2.0 or mit"},
+		{RawText: "Copyright 2022 Example Inc.", License: "Acme Proprietary"},
+	}
+
+	var out bytes.Buffer
+	if err := WriteSPDX(&out, "my project", entries); err != nil {
+		t.Fatal(err)
+	}
+	doc := out.String()
+
+	for _, line := range []string{
+		"SPDXVersion: SPDX-2.3\n",
+		"DataLicense: CC0-1.0\n",
+		"SPDXID: SPDXRef-DOCUMENT\n",
+		"DocumentName: my project\n",
+		"PackageName: my project\n",
+		"SPDXID: SPDXRef-Package\n",
+		"PackageDownloadLocation: NOASSERTION\n",
+		"PackageLicenseDeclared: MIT AND (Apache-2.0 OR MIT) AND LicenseRef-Acme-Proprietary\n",
+		"PackageCopyrightText: <text>Copyright 2024 Acme Corp.\nCopyright 2023 Jane Doe\nCopyright 2022 Example Inc.</text>\n",
+		"Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package\n",
+		"\nLicenseID: LicenseRef-Acme-Proprietary\nExtractedText: <text>Acme Proprietary</text>\nLicenseName: Acme Proprietary\n",
+	} {
+		if !strings.Contains(doc, line) {
+			t.Errorf("document lacks %q:\n%s", line, doc)
+		}
+	}
+	if !strings.HasPrefix(doc, "SPDXVersion: SPDX-2.3\n") {
+		t.Errorf("document doesn't start with the SPDX version:\n%s", doc)
+	}
+
+	namespace := regexp.MustCompile(`(?m)^DocumentNamespace: https://spdx\.org/spdxdocs/my-project-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !namespace.MatchString(doc) {
+		t.Errorf("document lacks a unique namespace:\n%s", doc)
+	}
+	created := regexp.MustCompile(`(?m)^Created: \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`)
+	if !created.MatchString(doc) {
+		t.Errorf("document lacks a creation time:\n%s", doc)
+	}
+}
+
+func TestWriteSPDXWithoutFindings(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteSPDX(&out, "empty", nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"PackageLicenseDeclared: NOASSERTION\n", "PackageCopyrightText: NOASSERTION\n"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("document lacks %q:\n%s", line, out.String())
+		}
+	}
+}
+
+// cycloneDXTestBOM is the part of a CycloneDX BOM checked by the tests
+type cycloneDXTestBOM struct {
+	BOMFormat    string `json:"bomFormat"`
+	SpecVersion  string `json:"specVersion"`
+	SerialNumber string `json:"serialNumber"`
+	Version      int    `json:"version"`
+	Components   []struct {
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Copyright string `json:"copyright"`
+		Licenses  []struct {
+			License *struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"license"`
+			Expression string `json:"expression"`
+		} `json:"licenses"`
+	} `json:"components"`
+}
+
+// writeCycloneDXTestBOM writes and decodes the BOM of the entries
+func writeCycloneDXTestBOM(t *testing.T, entries []CopyrightEntry) cycloneDXTestBOM {
+	t.Helper()
+	var out bytes.Buffer
+	if err := WriteCycloneDX(&out, "project", entries); err != nil {
+		t.Fatal(err)
+	}
+	var bom cycloneDXTestBOM
+	if err := json.Unmarshal(out.Bytes(), &bom); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(bom.Components) != 1 {
+		t.Fatalf("got %d components, want 1", len(bom.Components))
+	}
+	return bom
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	entries := []CopyrightEntry{
+		{RawText: "Copyright 2024 Acme Corp.", License: "MIT"},
+		{RawText: "Copyright 2023 Jane Doe", License: "apache-2.0"},
+		{RawText: "Copyright 2023 Jane Doe", License: "Acme-Proprietary"},
+		{RawText: "Copyright 2023 Jane Doe", License: "Apache-2.0"},
+		{RawText: "Copyright 2023 Jane Doe"},
+	}
+	bom := writeCycloneDXTestBOM(t, entries)
+
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" || bom.Version != 1 {
+		t.Errorf("header = %q %q %d, want CycloneDX 1.5 version 1", bom.BOMFormat, bom.SpecVersion, bom.Version)
+	}
+	serial := regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !serial.MatchString(bom.SerialNumber) {
+		t.Errorf("serialNumber = %q, want a random urn:uuid", bom.SerialNumber)
+	}
+
+	component := bom.Components[0]
+	if component.Type != "application" || component.Name != "project" {
+		t.Errorf("component = %q %q, want application project", component.Type, component.Name)
+	}
+	if want := "Copyright 2024 Acme Corp.\nCopyright 2023 Jane Doe"; component.Copyright != want {
+		t.Errorf("copyright = %q, want %q", component.Copyright, want)
+	}
+
+	// Listed licenses have an id, others only a name
+	var licenses []string
+	for _, license := range component.Licenses {
+		if license.License == nil || license.Expression != "" {
+			t.Fatalf("expected single licenses, got %+v", component.Licenses)
+		}
+		licenses = append(licenses, "id="+license.License.ID+" name="+license.License.Name)
+	}
+	want := []string{"id=MIT name=", "id=Apache-2.0 name=", "id= name=Acme-Proprietary"}
+	if !reflect.DeepEqual(licenses, want) {
+		t.Errorf("licenses = %q, want %q", licenses, want)
+	}
+}
+
+func TestWriteCycloneDXExpression(t *testing.T) {
+	entries := []CopyrightEntry{
+		{RawText: "Copyright 2024 Acme Corp.", License: "MIT"},
+		{RawText: "Copyright 2023 Jane Doe", License: "(Apache-2.0 OR mit) AND GPL-2.0-only WITH Classpath-exception-2.0"},
+	}
+	component := writeCycloneDXTestBOM(t, entries).Components[0]
+
+	// Compound expressions can't be split into licenses, so all of them form one expression
+	want := "MIT AND ((Apache-2.0 OR MIT) AND GPL-2.0-only WITH Classpath-exception-2.0)"
+	if len(component.Licenses) != 1 || component.Licenses[0].License != nil || component.Licenses[0].Expression != want {
+		t.Errorf("licenses = %+v, want the expression %q", component.Licenses, want)
+	}
+}
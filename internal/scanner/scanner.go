@@ -6,19 +6,210 @@ package scanner
 
 import (
 	"bufio"
-	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
-// Scanner is a struct for handling copyright information scanning
+// coprPattern matches the "Copr." abbreviation of copyright as a whole word
+var coprPattern = regexp.MustCompile(`\bcopr\b`)
+
+// Scanner is a struct for handling copyright information scanning. It only
+// holds the configuration, every scan keeps its state to itself, so one
+// Scanner may run any number of scans from several goroutines at once as
+// long as its fields aren't changed meanwhile. FileScanned, EntriesScanned,
+// ProgressFunc and OutputWritten are then called by the concurrent scans
+// independently
 type Scanner struct {
 	// Removed codeExtensions as we now scan all text files
+
+	// AnonymizePersonalNames replaces holders that look like individuals
+	// (personal names or email addresses) with a placeholder, in both the
+	// extracted statements and the copyright lines of the license text.
+	// The check is heuristic: two to four capitalized words without a known
+	// organization word count as a personal name, so a company name without
+	// a legal suffix or organization word (e.g. "Acme Rockets") is redacted too
+	AnonymizePersonalNames bool
+	// HashPersonalNames appends a pseudonym, an HMAC-SHA256 of the name, to
+	// the placeholder so entries of the same individual still group together
+	HashPersonalNames bool
+	// PseudonymKey is the key of the pseudonyms of HashPersonalNames. When
+	// empty, a random key is drawn once per process, so pseudonyms are only
+	// comparable within one run and guessed names can't be hashed to match
+	PseudonymKey []byte
+	// SplitHolders turns a statement naming several holders, such as
+	// "Copyright 2024 Alice, Bob and Carol", into one structured entry per
+	// holder. Company names such as "Smith, Johnson & Co" or "Acme, Inc."
+	// stay whole, and the text report still lists the statement once
+	SplitHolders bool
+	// ValidateYears appends a warnings section listing statements whose
+	// years lie in the future or before 1970, which usually indicates a typo,
+	// and describes those years in the Warnings of structured entries, which
+	// are marked SuspiciousYear. Both read the years like ParseCopyrightYears
+	ValidateYears bool
+	// FlagMissingYears lists statements without any year in the warnings
+	// section, as many license policies require a year in every header, and
+	// sets MissingYear on such structured entries
+	FlagMissingYears bool
+	// CheckCompatibility appends a license compatibility verdict for the
+	// license files detected in the scanned tree or file list to text
+	// reports, and adds it to the JSON reports of ScanSubDirectories
+	CheckCompatibility bool
+	// ScanImageMetadata reads copyright notices from the EXIF, PNG text and
+	// XMP metadata of images, which are otherwise skipped as binary files
+	ScanImageMetadata bool
+	// RequireRightsPhrase only accepts a copyright statement that also
+	// contains a rights phrase, a year or a legal entity suffix. It filters
+	// stray "(c)" matches at the cost of missing bare holder statements
+	RequireRightsPhrase bool
+	// DefaultEncoding names the legacy encoding of text that is neither
+	// UTF-8 nor has a byte order mark: windows-1252, iso-8859-1 or
+	// iso-8859-15. DefaultEncoding is used when empty
+	DefaultEncoding string
+	// MinConfidence drops the statements whose confidence, from 0 to 1, is
+	// below it, such as prose mentioning copyright. Each entry reports its
+	// Confidence, so the threshold can be tuned; nothing is dropped if unset
+	MinConfidence float64
+	// RightsPhrases are the phrases accepted by RequireRightsPhrase,
+	// DefaultRightsPhrases is used when empty
+	RightsPhrases []string
+	// CommentPrefixes are the comment markers stripped from the start of
+	// each line, DefaultCommentPrefixes is used when empty
+	CommentPrefixes []string
+	// CompressOutput gzip-compresses the output files of ScanSubDirectories,
+	// adding a .gz suffix. Output names ending in .gz are always compressed
+	CompressOutput bool
+	// OutputLineEnding is the line ending of the files written, LineEndingLF
+	// when empty or LineEndingCRLF. Reports are built with "\n" and only
+	// converted when written
+	OutputLineEnding string
+	// OutputBOM starts the files written with a UTF-8 byte order mark
+	OutputBOM bool
+	// InlineLicenses appends a section listing the license texts found in
+	// the comment blocks of source files, with the file and line of each
+	InlineLicenses bool
+	// MergeRepeatedLicenseBlocks reports identical inline license blocks of
+	// one file once with a count, as in amalgamated single-file distributions
+	MergeRepeatedLicenseBlocks bool
+	// MergeYears merges statements of a holder that differ only in their
+	// years into one statement listing all years, with consecutive years
+	// merged into ranges, e.g. "Copyright 2019 Acme" and "Copyright 2020 Acme"
+	// into "Copyright 2019-2020 Acme". Otherwise each statement is reported
+	// with its years as written
+	MergeYears bool
+	// GroupByYear lists the copyrights in one section per year, each under
+	// the earliest year its holder claims, for a timeline of the project
+	GroupByYear bool
+	// SortOutput lists the copyrights of text reports sorted case-insensitively
+	// instead of in the order of the files they were found in, so reports of
+	// the same statements are identical however the files were arranged
+	SortOutput bool
+	// HolderMap maps holder name variants to canonical names, which replace
+	// them in the output. Keys are lowercase with collapsed whitespace and no
+	// trailing punctuation, as produced by LoadHolderMap
+	HolderMap map[string]string
+	// ThirdPartyDirs are directories, such as "vendor" or "third_party",
+	// whose copyrights are grouped in a separate third-party section. Each
+	// matches as whole path segments anywhere below the scanned directory
+	ThirdPartyDirs []string
+	// ExcludePatterns skips the files and directories whose path relative
+	// to the scanned directory matches one of the patterns, with the syntax
+	// of filepath.Match. "*.min.js" only matches at the top level, while
+	// "*/*.min.js" matches one level below
+	ExcludePatterns []string
+	// ExcludeDirs skips the directories with one of the names, such as
+	// "node_modules" or "testdata", at any depth
+	ExcludeDirs []string
+	// IncludeExtensions, if not empty, limits directory and file list scans
+	// to the files with one of the extensions, such as ".go" or ".c", which
+	// still have to be text files. The match ignores case and the leading dot
+	IncludeExtensions []string
+	// OutputWritten, if set, is called after each report is written with
+	// the output path and the name of the scanned project. An error fails
+	// the scan, ScanSubDirectories still scans the other subdirectories
+	OutputWritten func(outputFile, name string) error
+	// ParallelSubDirectories is the number of subdirectories scanned at once
+	// by ScanSubDirectories, one if unset. Above one, FileScanned,
+	// EntriesScanned and OutputWritten are called concurrently
+	ParallelSubDirectories int
+	// RespectGitignore skips the paths ignored by the .gitignore files of
+	// the scanned directory and its subdirectories, and the .git directory
+	RespectGitignore bool
+	// FollowSymlinks traverses symlinked directories when scanning a
+	// directory, entering each directory only once so a symlink loop can't
+	// make the scan run forever. Symlinked files are always scanned, while
+	// symlinked directories are skipped unless it is set
+	FollowSymlinks bool
+	// StrictErrors aborts a directory scan at the first file or directory
+	// that can't be read. By default such entries, e.g. ones without read
+	// permission, are skipped and returned as FileErrors with the result
+	StrictErrors bool
+	// Logger receives the progress and error messages of the scanner, such
+	// as the reports written and the files that couldn't be read. Nothing is
+	// logged if it is unset
+	Logger *slog.Logger
+	// Concurrency is the number of files ScanDirectory scans at once, one
+	// per CPU if unset. The report is merged in path order regardless
+	Concurrency int
+	// OutputFormat selects the format of the reports written by
+	// ScanSubDirectories, FormatText if unset
+	OutputFormat string
+	// CompactJSON writes JSON reports without indentation
+	CompactJSON bool
+	// JSONFiles adds a "files" object to JSON reports, listing the
+	// copyrights found in each file
+	JSONFiles bool
+	// MaxScanBytes is the number of bytes of text read from each file,
+	// DefaultMaxScanBytes if unset and no limit if negative. Copyright
+	// headers sit at the top, so the rest of a huge file is ignored
+	MaxScanBytes int64
+	// HeaderLinesOnly stops reading each file after that many lines, as
+	// notices sit in the header and code below may mention "copyright" in
+	// strings. Zero reads the whole file
+	HeaderLinesOnly int
+	// TextDetectionBytes is the number of bytes sampled from the start of
+	// each file to tell text from binary files, DefaultTextDetectionBytes if
+	// unset. A file is binary if over a tenth of the sample isn't printable
+	TextDetectionBytes int
+	// SkipFilesLargerThan skips the files of a scanned directory whose size
+	// exceeds it in bytes, without opening them. Zero scans files of any size
+	SkipFilesLargerThan int64
+	// TemplatePath is the prefix template written before each text report,
+	// DefaultTemplatePath if unset. A missing template adds no prefix
+	TemplatePath string
+	// NoTemplate writes text reports without a prefix template
+	NoTemplate bool
+	// PreserveOriginal reports each statement as written in the source,
+	// without its comment markers, instead of with its whitespace
+	// normalized. Entries carry the normalized form as CleanText, and
+	// statements are still deduplicated by their normalized form
+	PreserveOriginal bool
+	// FileScanned, if set, is called for every scanned file with all
+	// copyright statements found in it, including duplicates
+	FileScanned func(path string, copyrights []string)
+	// EntriesScanned, if set, is called once per scanned directory or file
+	// list with the entries its report is formatted from, so structured
+	// checks such as a Policy can run on every report without a second scan
+	EntriesScanned func(entries []CopyrightEntry)
+	// ProgressFunc, if set, is called after each file of a directory scan
+	// with the number of files scanned so far, the number of files to scan
+	// and the path of the file just scanned. Calls for one directory are
+	// never concurrent, but ParallelSubDirectories scans several at once
+	ProgressFunc func(scanned, total int, currentPath string)
 }
 
 // NewScanner creates a new scanner instance
@@ -26,75 +217,216 @@ func NewScanner() *Scanner {
 	return &Scanner{}
 }
 
-// isTextFile checks if a file is a text file
+// randomPseudonymKey is the pseudonym key used when none is configured
+var randomPseudonymKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("failed to generate pseudonym key: %v", err))
+	}
+	return key
+})
+
+// pseudonymKey returns the key for anonymized holders, nil when names are not hashed
+func (s *Scanner) pseudonymKey() []byte {
+	if !s.HashPersonalNames {
+		return nil
+	}
+	if len(s.PseudonymKey) > 0 {
+		return s.PseudonymKey
+	}
+	return randomPseudonymKey()
+}
+
+// DefaultMaxScanBytes is the number of bytes read from each file when
+// MaxScanBytes is unset
+const DefaultMaxScanBytes = 4 << 20
+
+// limitScan limits r to the configured number of bytes scanned per file
+func (s *Scanner) limitScan(r io.Reader) io.Reader {
+	switch {
+	case s.MaxScanBytes < 0:
+		return r
+	case s.MaxScanBytes == 0:
+		return io.LimitReader(r, DefaultMaxScanBytes)
+	}
+	return io.LimitReader(r, s.MaxScanBytes)
+}
+
+// rightsPhrases returns the configured rights phrases or the defaults
+func (s *Scanner) rightsPhrases() []string {
+	if len(s.RightsPhrases) > 0 {
+		return s.RightsPhrases
+	}
+	return DefaultRightsPhrases
+}
+
+// DefaultTextDetectionBytes is the number of bytes sampled by the text
+// detection when TextDetectionBytes is unset
+const DefaultTextDetectionBytes = 8192
+
+// maxBinaryRatio is the share of non-printable bytes above which a sample is
+// considered binary, so a few stray control characters don't exclude a file
+const maxBinaryRatio = 0.1
+
+// isTextFile checks if a file of the OS filesystem is a text file like isTextFileFS
 func (s *Scanner) isTextFile(path string) bool {
-	// Open the file
-	file, err := os.Open(path)
+	return s.isTextFileFS(osFile(path))
+}
+
+// isTextFileFS checks if the file name of fsys is a text file, judging a sample of its first
+// TextDetectionBytes bytes by the share of non-printable bytes
+func (s *Scanner) isTextFileFS(fsys fs.FS, name string) bool {
+	// Open the file, compressed files are judged by their content
+	file, err := openFileFS(fsys, name)
 	if err != nil {
 		return false
 	}
 	defer file.Close()
 
-	// Read the first 512 bytes of the file
-	buf := make([]byte, 512)
-	n, err := file.Read(buf)
-	if err != nil && err != io.EOF {
+	buf := make([]byte, s.textDetectionBytes())
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return false
 	}
-	buf = buf[:n]
+	// UTF-16 text is full of null bytes, so judge its decoded content
+	return isTextSample(decodeBOMPrefix(buf[:n]))
+}
 
-	// Check if it contains null bytes (characteristic of binary files)
-	if bytes.Contains(buf, []byte{0}) {
-		return false
+// textDetectionBytes returns the size of the sample judged by isTextFile
+func (s *Scanner) textDetectionBytes() int {
+	if s.TextDetectionBytes <= 0 {
+		return DefaultTextDetectionBytes
 	}
+	return s.TextDetectionBytes
+}
 
-	// Check if the file content consists of printable ASCII characters or common Unicode characters
-	for _, b := range buf {
-		if b < 32 && !isAllowedControlChar(b) {
-			return false
-		}
+// isTextSample checks if at most maxBinaryRatio of a sample are null bytes,
+// disallowed control characters or bytes that aren't valid UTF-8
+func isTextSample(buf []byte) bool {
+	if len(buf) == 0 {
+		return true
 	}
 
-	return true
+	nonPrintable := 0
+	for i := 0; i < len(buf); {
+		b := buf[i]
+		if b < utf8.RuneSelf {
+			if (b < 32 && !isAllowedControlChar(b)) || b == 127 {
+				nonPrintable++
+			}
+			i++
+			continue
+		}
+
+		// The sample may end in the middle of a character
+		if !utf8.FullRune(buf[i:]) {
+			break
+		}
+		r, size := utf8.DecodeRune(buf[i:])
+		if r == utf8.RuneError && size == 1 {
+			nonPrintable++
+		}
+		i += size
+	}
+	return float64(nonPrintable) <= maxBinaryRatio*float64(len(buf))
 }
 
 // isAllowedControlChar checks if a character is allowed as a control character
 func isAllowedControlChar(b byte) bool {
-	// Allowed control characters: newline, carriage return, tab
-	return b == '\n' || b == '\r' || b == '\t'
+	// Allowed control characters: newline, carriage return, tab, form feed
+	return b == '\n' || b == '\r' || b == '\t' || b == '\f'
+}
+
+// DefaultCommentPrefixes are the comment markers stripped from the start of a
+// line when Scanner.CommentPrefixes is empty
+var DefaultCommentPrefixes = []string{
+	"//", "/*", "*/", "#", "*", "+", "-", "<!--", "-->",
+	";", "%", "REM", "--",
+}
+
+// commentSuffixes are the comment markers stripped from the end of a line
+var commentSuffixes = []string{"*/", "-->", "*", "#"}
+
+// commentPrefixes returns the configured comment prefixes or the defaults
+func (s *Scanner) commentPrefixes() []string {
+	if len(s.CommentPrefixes) > 0 {
+		return s.CommentPrefixes
+	}
+	return DefaultCommentPrefixes
+}
+
+// cleanLine strips the scanner's comment markers from a line
+func (s *Scanner) cleanLine(line string) string {
+	return cleanLine(line, s.commentPrefixes())
 }
 
-// cleanLine cleans up comments and other markings in a line
-func cleanLine(line string) string {
-	// Remove leading comment markings and other markings
-	prefixes := []string{"//", "/*", "*/", "#", "*", "+", "-", "<!--", "-->"}
-	trimmed := line
+// cleanLine strips comment markers from the start and end of a line and
+// normalizes its whitespace. Markers inside the line, such as the "//" of a
+// URL, are kept
+func cleanLine(line string, prefixes []string) string {
+	// Normalize whitespace characters
+	return strings.Join(strings.Fields(normalizePunctuation(trimCommentMarkers(line, prefixes))), " ")
+}
 
-	// Repeat cleaning until no more prefixes can be removed
+// punctuationReplacer folds the typographic dashes and quotes of notices
+// copied from PDFs or documents into ASCII, and repairs a © glyph whose UTF-8
+// bytes were decoded as Latin-1
+var punctuationReplacer = strings.NewReplacer(
+	"\u2010", "-", "\u2011", "-", "\u2012", "-", "\u2013", "-", "\u2014", "-", "\u2015", "-", "\u2212", "-",
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u201b", "'",
+	"\u201c", `"`, "\u201d", `"`, "\u201e", `"`, "\u201f", `"`,
+	"\u00c2\u00a9", "\u00a9",
+)
+
+// normalizePunctuation replaces dash and quote variants with their ASCII
+// forms, so that "2019–2023" and "2019-2023" are the same statement
+func normalizePunctuation(s string) string {
+	return punctuationReplacer.Replace(s)
+}
+
+// trimCommentMarkers strips comment markers and surrounding whitespace from
+// the start and end of a line, keeping the rest of it as is
+func trimCommentMarkers(line string, prefixes []string) string {
+	trimmed := strings.TrimSpace(line)
+
+	// Repeat cleaning until no more markers can be removed
 	for {
 		original := trimmed
-		trimmed = strings.TrimSpace(trimmed)
-
-		// Remove all comment markings, no matter where they are
 		for _, prefix := range prefixes {
-			trimmed = strings.ReplaceAll(trimmed, prefix, " ")
+			if hasCommentPrefix(trimmed, prefix) {
+				trimmed = strings.TrimSpace(trimmed[len(prefix):])
+			}
+		}
+		for _, suffix := range commentSuffixes {
+			if strings.HasSuffix(trimmed, suffix) {
+				trimmed = strings.TrimSpace(strings.TrimSuffix(trimmed, suffix))
+			}
 		}
-
-		// Normalize whitespace characters
-		trimmed = strings.Join(strings.Fields(trimmed), " ")
-
 		if original == trimmed {
 			break
 		}
 	}
-
 	return trimmed
 }
 
+// hasCommentPrefix checks if line starts with a comment marker. Word markers
+// such as "REM" match regardless of case and only as a whole word
+func hasCommentPrefix(line, prefix string) bool {
+	if prefix == "" || len(line) < len(prefix) {
+		return false
+	}
+	last := prefix[len(prefix)-1]
+	if !unicode.IsLetter(rune(last)) {
+		return strings.HasPrefix(line, prefix)
+	}
+	return strings.EqualFold(line[:len(prefix)], prefix) &&
+		(len(line) == len(prefix) || line[len(prefix)] == ' ' || line[len(prefix)] == '\t')
+}
+
 // normalizeForComparison normalizes a string for comparison
 func normalizeForComparison(s string) string {
-	// Convert to lowercase
-	s = strings.ToLower(s)
+	// Fold compatibility characters and convert to lowercase
+	s = strings.ToLower(norm.NFKC.String(normalizePunctuation(s)))
 
 	// Remove all punctuation (including periods) and special characters
 	s = strings.Map(func(r rune) rune {
@@ -113,7 +445,7 @@ func normalizeForComparison(s string) string {
 		field := fields[i]
 
 		// Skip common prefixes
-		if field == "copyright" || field == "c" || field == "by" ||
+		if field == "copyright" || field == "copr" || field == "c" || field == "by" ||
 			field == "corp" || field == "corporation" || field == "inc" ||
 			field == "affiliates" || field == "all" || field == "rights" ||
 			field == "reserved" || field == "and" || field == "the" ||
@@ -150,279 +482,737 @@ func normalizeForComparison(s string) string {
 
 // extractCopyright extracts copyright information from a file
 func (s *Scanner) extractCopyright(filePath string) (string, error) {
-	file, err := os.Open(filePath)
+	file, err := openFile(filePath)
 	if err != nil {
 		return "", err
 	}
 	defer file.Close()
+	return s.extractCopyrightWith(file, headerParserFor(filePath))
+}
 
-	// Set a larger buffer
-	reader := bufio.NewReaderSize(file, 1024*1024) // 1MB buffer
+// ExtractFromReader extracts the copyright information of text read from r,
+// such as content already in memory, like ScanFile does for a file but
+// without the detected licenses. UTF-16 text with a byte order mark is decoded
+func (s *Scanner) ExtractFromReader(r io.Reader) (string, error) {
+	return s.extractCopyrightFromReader(r)
+}
+
+// ScanReader scans text read from r like ScanFile scans a file, such as a
+// file piped to stdin, failing like it if the content isn't text
+func (s *Scanner) ScanReader(r io.Reader) (string, error) {
+	text, err := s.textReader(r)
+	if err != nil {
+		return "", err
+	}
+	return s.extractCopyrightFromReader(text)
+}
+
+// ScanReaderStructured scans text read from r like ScanReader, returning
+// every copyright statement as an entry attributed to name
+func (s *Scanner) ScanReaderStructured(r io.Reader, name string) ([]CopyrightEntry, error) {
+	text, err := s.textReader(r)
+	if err != nil {
+		return nil, err
+	}
+	statements, licenses, err := s.readStatementsAndLicenses(context.Background(), s.decodeText(text), headerParserFor(name))
+	if err != nil {
+		return nil, err
+	}
+	return s.fileEntries(s.formatStatements(statements), name, name, firstLicense(licenses), false), nil
+}
+
+// textReader checks that r holds text by the same sample isTextFile judges.
+// The sample is peeked rather than read, so the returned reader still
+// yields every byte of r
+func (s *Scanner) textReader(r io.Reader) (io.Reader, error) {
+	size := s.textDetectionBytes()
+	buffered := bufio.NewReaderSize(r, size)
+	sample, err := buffered.Peek(size)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read input: %v", err)
+	}
+	if !isTextSample(decodeBOMPrefix(sample)) {
+		return nil, errors.New("input is not text")
+	}
+	return buffered, nil
+}
+
+// extractCopyrightFromReader implements extractCopyright for text read from r
+func (s *Scanner) extractCopyrightFromReader(r io.Reader) (string, error) {
+	return s.extractCopyrightWith(r, defaultHeaderParser)
+}
+
+// extractCopyrightWith extracts the copyright information of text read from r
+// with the header parser of its file type
+func (s *Scanner) extractCopyrightWith(r io.Reader, parser headerParser) (string, error) {
+	statements, _, err := s.readStatementsAndLicenses(context.Background(), s.decodeText(r), parser)
+	if err != nil {
+		return "", err
+	}
+	return s.formatStatements(statements), nil
+}
+
+// formatStatements filters and deduplicates the statements of one file and joins them into lines
+func (s *Scanner) formatStatements(statements []string) string {
 	var copyright strings.Builder
 	seenCopyrights := make(map[string]bool)
 
-	// For storing multi-line copyright information
-	var currentCopyright strings.Builder
+	for _, statement := range statements {
+		// Drop unconfirmed matches such as a stray "(c)"
+		if s.RequireRightsPhrase && !isConfirmedCopyright(statement, s.rightsPhrases()) {
+			continue
+		}
+		if s.MinConfidence > 0 && copyrightConfidence(statement) < s.MinConfidence {
+			continue
+		}
+
+		// The dedup key ignores years, unless they are merged below
+		key := normalizeForComparison(statement)
+		if s.MergeYears {
+			key = statement
+		}
+		if !seenCopyrights[key] {
+			seenCopyrights[key] = true
+			copyright.WriteString(statement + "\n")
+		}
+	}
+
+	if s.MergeYears {
+		return mergeYears(copyright.String())
+	}
+	return copyright.String()
+}
+
+// extractStatements extracts every copyright statement of a file, including duplicates
+func (s *Scanner) extractStatements(filePath string) ([]string, error) {
+	fsys, name := osFile(filePath)
+	statements, _, err := s.extractStatementsAndLicenses(context.Background(), fsys, name)
+	return statements, err
+}
+
+// extractStatementsAndLicenses extracts every copyright statement of a file like
+// extractStatements, together with the expressions of its SPDX-License-Identifier
+// tags. Reading stops with ctx.Err() once ctx is done
+func (s *Scanner) extractStatementsAndLicenses(ctx context.Context, fsys fs.FS, name string) ([]string, []string, error) {
+	file, err := s.openText(fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	return s.readStatementsAndLicenses(ctx, file, headerParserFor(name))
+}
+
+// readStatementsAndLicenses implements extractStatementsAndLicenses for
+// UTF-8 text read from r, finding comment blocks with parser
+func (s *Scanner) readStatementsAndLicenses(ctx context.Context, r io.Reader, parser headerParser) ([]string, []string, error) {
+	// Set a larger buffer
+	reader := bufio.NewReaderSize(s.limitScan(r), 1024*1024) // 1MB buffer
+	var copyrights, licenses []string
+
+	// For storing multi-line copyright information, along with its lines as
+	// written for PreserveOriginal
+	var currentCopyright, originalCopyright strings.Builder
 	var isCollectingCopyright bool
 
+	// Index of the last emitted statement that still lacks a year, or -1
+	awaitingYear := -1
+	lineCount := 0
+
+	// Whether the current line is inside a comment block, such as a /* ... */
+	// comment or a Python docstring
+	inBlockComment := false
+
+	// flushCopyright handles collected copyright information
+	flushCopyright := func() {
+		if isCollectingCopyright && currentCopyright.Len() > 0 {
+			cleanedCopyright := s.cleanLine(currentCopyright.String())
+			if s.PreserveOriginal {
+				copyrights = append(copyrights, originalCopyright.String())
+			} else {
+				copyrights = append(copyrights, cleanedCopyright)
+			}
+			if !yearTokenPattern.MatchString(cleanedCopyright) {
+				awaitingYear = len(copyrights) - 1
+			}
+			currentCopyright.Reset()
+		}
+		originalCopyright.Reset()
+		isCollectingCopyright = false
+	}
+
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
 		line, err := reader.ReadString('\n')
 		if err != nil && err != io.EOF {
-			return "", err
+			return nil, nil, err
+		}
+
+		// The last header line is handled like the end of the file. An
+		// unterminated final line counts, an empty read after a newline doesn't
+		if line != "" {
+			lineCount++
+		}
+		if s.HeaderLinesOnly > 0 && lineCount >= s.HeaderLinesOnly {
+			err = io.EOF
 		}
 
-		// Remove leading and trailing whitespace
-		trimmedLine := strings.TrimSpace(line)
+		// Fold full-width letters, parentheses and ideographic spaces to their
+		// ASCII forms, then remove leading and trailing whitespace
+		trimmedLine := strings.TrimSpace(norm.NFKC.String(line))
 
-		// Handle empty lines
-		if trimmedLine == "" {
-			if isCollectingCopyright {
-				// Handle collected copyright information
-				if currentCopyright.Len() > 0 {
-					cleanedCopyright := cleanLine(currentCopyright.String())
-					normalizedCopyright := normalizeForComparison(cleanedCopyright)
-					if !seenCopyrights[normalizedCopyright] {
-						seenCopyrights[normalizedCopyright] = true
-						copyright.WriteString(cleanedCopyright + "\n")
-					}
-					currentCopyright.Reset()
-				}
-				isCollectingCopyright = false
+		// The interpreter line of a script is no part of its header
+		if parser.isShebang(trimmedLine, lineCount) {
+			if err == io.EOF {
+				break
 			}
+			continue
+		}
+
+		// A block comment is a unit of its own: a statement doesn't continue
+		// into it, and ends at its end or at an empty line within it, like
+		// the lone " *" of a C header
+		opensBlock, closesBlock, inBlockAfter := parser.bounds(trimmedLine, inBlockComment)
+		if opensBlock {
+			flushCopyright()
+		}
+		if parser.strip != nil {
+			trimmedLine, line = parser.strip(trimmedLine), parser.strip(line)
+		}
+		emptyInBlock := (inBlockComment || opensBlock) && s.cleanLine(trimmedLine) == ""
+		inBlockComment = inBlockAfter
+
+		// Handle empty lines
+		if trimmedLine == "" || emptyInBlock {
+			flushCopyright()
 			if err == io.EOF {
 				break
 			}
 			continue
 		}
 
-		// Skip possible code lines and test-related content
-		lowercaseLine := strings.ToLower(trimmedLine)
-		if strings.Contains(lowercaseLine, "func ") ||
-			strings.Contains(lowercaseLine, "type ") ||
-			strings.Contains(lowercaseLine, "var ") ||
-			strings.Contains(lowercaseLine, "const ") ||
-			strings.Contains(lowercaseLine, "package ") ||
-			strings.Contains(lowercaseLine, "import ") ||
-			strings.Contains(lowercaseLine, "return ") ||
-			strings.Contains(lowercaseLine, ":=") ||
-			strings.Contains(lowercaseLine, "if ") ||
-			strings.Contains(lowercaseLine, "test") ||
-			strings.Contains(lowercaseLine, "echo") ||
-			strings.Contains(lowercaseLine, "find_") ||
-			strings.Contains(lowercaseLine, "append") ||
-			strings.Contains(lowercaseLine, "error:") ||
-			strings.Contains(lowercaseLine, "grep") ||
-			strings.Contains(lowercaseLine, "egrep") ||
-			strings.Contains(lowercaseLine, "while ") ||
-			strings.Contains(lowercaseLine, "read ") ||
-			strings.Contains(lowercaseLine, "|") ||
-			strings.Contains(lowercaseLine, "grant of") ||
-			strings.Contains(lowercaseLine, "license") ||
-			strings.Contains(lowercaseLine, "permission") ||
-			strings.Contains(lowercaseLine, "permitted") ||
-			strings.Contains(lowercaseLine, "distribute") ||
-			strings.Contains(lowercaseLine, "notice") ||
-			strings.Contains(lowercaseLine, "provided") ||
-			strings.Contains(lowercaseLine, "conditions") ||
-			strings.Contains(lowercaseLine, "subject to") ||
-			strings.Contains(lowercaseLine, "you may") ||
-			strings.Contains(lowercaseLine, "you must") ||
-			strings.Contains(lowercaseLine, "shall") ||
-			strings.Contains(lowercaseLine, "retain") ||
-			strings.Contains(lowercaseLine, "reproduce") {
-			if isCollectingCopyright {
-				// Handle collected copyright information
-				if currentCopyright.Len() > 0 {
-					cleanedCopyright := cleanLine(currentCopyright.String())
-					normalizedCopyright := normalizeForComparison(cleanedCopyright)
-					if !seenCopyrights[normalizedCopyright] {
-						seenCopyrights[normalizedCopyright] = true
-						copyright.WriteString(cleanedCopyright + "\n")
-					}
-					currentCopyright.Reset()
+		// A year on its own line after a yearless statement belongs to that statement
+		if awaitingYear >= 0 && !isCollectingCopyright {
+			if cleanedYear := s.cleanLine(trimmedLine); yearOnlyPattern.MatchString(cleanedYear) {
+				copyrights[awaitingYear] += " " + cleanedYear
+				awaitingYear = -1
+				if err == io.EOF {
+					break
 				}
-				isCollectingCopyright = false
+				continue
+			}
+			awaitingYear = -1
+		}
+
+		// A license tag ends a statement and is recorded instead
+		if expression, ok := spdxExpression(trimmedLine); ok {
+			flushCopyright()
+			licenses = append(licenses, expression)
+			if err == io.EOF {
+				break
 			}
+			continue
+		}
+
+		// Skip possible code lines, test-related content and license terms
+		kind := ClassifyLine(trimmedLine)
+		if kind == LineCode || kind == LineLicenseBoilerplate {
+			flushCopyright()
 			if err == io.EOF {
 				break
 			}
 			continue
 		}
 
-		// Check if it contains copyright-related text and ensure it's a real copyright statement
-		if (strings.Contains(lowercaseLine, "copyright") ||
-			strings.Contains(lowercaseLine, "©") ||
-			strings.Contains(lowercaseLine, "(c)") ||
-			strings.Contains(trimmedLine, "(C)")) &&
-			!strings.Contains(lowercaseLine, "copyrightadder") &&
-			!strings.Contains(lowercaseLine, "copyrighttext") &&
-			!strings.Contains(lowercaseLine, "addcopyright") &&
-			!strings.Contains(lowercaseLine, "extractcopyright") &&
-			!strings.Contains(lowercaseLine, "hascopyright") &&
-			!strings.Contains(lowercaseLine, "copyright.sh") &&
-			!strings.Contains(lowercaseLine, "copyright notice") &&
-			!strings.Contains(lowercaseLine, "copyright owner") &&
-			!strings.Contains(lowercaseLine, "copyright holder") &&
-			!strings.Contains(lowercaseLine, "above copyright") &&
-			!strings.Contains(lowercaseLine, "retain") &&
-			!strings.Contains(lowercaseLine, "reproduce") {
-
-			// Start collecting copyright information
+		// Check if it's a real copyright statement
+		if kind == LineCopyright {
+			// Start collecting copyright information, a statement ends where
+			// the next one starts
+			flushCopyright()
 			isCollectingCopyright = true
-			currentCopyright.WriteString(trimmedLine)
+			currentCopyright.WriteString(s.cleanLine(normalizeSPDXCopyright(trimmedLine)))
+			originalCopyright.WriteString(trimCommentMarkers(normalizeSPDXCopyright(strings.TrimSpace(line)), s.commentPrefixes()))
 		} else if isCollectingCopyright {
-			// Continue collecting copyright information
-			currentCopyright.WriteString(" " + trimmedLine)
+			// Continue collecting copyright information, without the comment markers of the line
+			currentCopyright.WriteString(" " + s.cleanLine(trimmedLine))
+			if original := trimCommentMarkers(line, s.commentPrefixes()); original != "" {
+				originalCopyright.WriteString(" " + original)
+			}
+		}
+		if closesBlock {
+			flushCopyright()
 		}
 
 		if err == io.EOF {
 			// Handle last copyright information
-			if isCollectingCopyright && currentCopyright.Len() > 0 {
-				cleanedCopyright := cleanLine(currentCopyright.String())
-				normalizedCopyright := normalizeForComparison(cleanedCopyright)
-				if !seenCopyrights[normalizedCopyright] {
-					seenCopyrights[normalizedCopyright] = true
-					copyright.WriteString(cleanedCopyright + "\n")
-				}
-			}
+			flushCopyright()
 			break
 		}
 	}
 
-	return copyright.String(), nil
+	return copyrights, licenses, nil
+}
+
+// blockCommentBounds finds the /* and */ markers of a line. It reports
+// whether the line opens a block comment, whether it closes one, and whether
+// a block comment is still open after it. A "/*" after a "//" line comment
+// doesn't open a block
+func blockCommentBounds(line string, inBlock bool) (opens, closes, inBlockAfter bool) {
+	for i := 0; i+1 < len(line); i++ {
+		switch {
+		case inBlock && line[i] == '*' && line[i+1] == '/':
+			inBlock, closes = false, true
+			i++
+		case !inBlock && line[i] == '/' && line[i+1] == '/':
+			return opens, closes, false
+		case !inBlock && line[i] == '/' && line[i+1] == '*':
+			inBlock, opens = true, true
+			i++
+		}
+	}
+	return opens, closes, inBlock
+}
+
+// OutputFileName generates an output file name by replacing {name} and
+// {date} in the pattern. A pattern without {name} gets the name inserted
+// between its file name and extension
+func OutputFileName(outputPattern, name string) string {
+	return renderTemplate(namedPattern(outputPattern), reportVars(name, "", -1))
 }
 
 // ScanSubDirectories scans all subdirectories under a specified directory
 func (s *Scanner) ScanSubDirectories(rootDir string, outputPattern string) error {
+	return s.ScanSubDirectoriesContext(context.Background(), rootDir, outputPattern, nil)
+}
+
+// ScanSubDirectoriesContext scans all subdirectories under a specified
+// directory, up to ParallelSubDirectories at once. progress, if set, is called
+// after each completed subdirectory, never concurrently, and each written
+// report and unreadable file is logged to Logger. A failing subdirectory
+// doesn't stop the others, the failures of all of them are returned joined.
+// Once ctx is cancelled, scans in progress are aborted and no new scans
+// start; files already written remain
+func (s *Scanner) ScanSubDirectoriesContext(ctx context.Context, rootDir, outputPattern string, progress func(done, total int)) error {
 	// Get all subdirectories
 	entries, err := os.ReadDir(rootDir)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %v", err)
 	}
 
+	var subDirs []string
 	for _, entry := range entries {
-		if entry.IsDir() {
-			subDir := filepath.Join(rootDir, entry.Name())
-
-			// Generate output file name
-			outputFile := strings.ReplaceAll(outputPattern, "{name}", entry.Name())
-			if !strings.Contains(outputPattern, "{name}") {
-				// If pattern does not contain {name}, insert directory name between file name and extension
-				ext := filepath.Ext(outputFile)
-				base := strings.TrimSuffix(outputFile, ext)
-				base = strings.TrimSuffix(base, "_")
-				outputFile = base + "_" + entry.Name() + ext
-			}
+		if entry.IsDir() && !s.excluded(entry.Name(), true) {
+			subDirs = append(subDirs, entry.Name())
+		}
+	}
 
-			// Scan subdirectory
-			copyrightText, err := s.ScanDirectory(subDir)
-			if err != nil {
-				return fmt.Errorf("failed to scan directory %s: %v", subDir, err)
-			}
+	workers := s.ParallelSubDirectories
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Every report shares the prefix template, so read it once
+	template := s.readTemplate()
 
-			// Read prefix.txt content from template folder
-			prefixContent := ""
-			if prefixBytes, err := os.ReadFile("template/prefix.txt"); err == nil {
-				prefixContent = string(prefixBytes)
-
-				// Find and replace Software: line in prefix.txt
-				lines := strings.Split(prefixContent, "\n")
-				for i, line := range lines {
-					if strings.TrimSpace(line) == "Software:" {
-						lines[i] = "Software: " + entry.Name()
-						break
+	var mu sync.Mutex
+	var errs []error
+	done := 0
+
+	names := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				err := s.scanSubDirectory(ctx, rootDir, name, outputPattern, template)
+
+				mu.Lock()
+				// Scans aborted by the cancellation aren't failures of their own
+				aborted := ctx.Err() != nil && errors.Is(err, ctx.Err())
+				if err != nil && !aborted {
+					errs = append(errs, err)
+				} else if err == nil {
+					done++
+					if progress != nil {
+						progress(done, len(subDirs))
 					}
 				}
-				prefixContent = strings.Join(lines, "\n")
+				mu.Unlock()
+			}
+		}()
+	}
 
-				// Ensure prefix content ends with a newline
-				if !strings.HasSuffix(prefixContent, "\n") {
-					prefixContent += "\n"
-				}
+feed:
+	for _, name := range subDirs {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case names <- name:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(names)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return ctx.Err()
+}
+
+// scanSubDirectory scans one subdirectory of rootDir and writes its report,
+// prefixed with template
+func (s *Scanner) scanSubDirectory(ctx context.Context, rootDir, name, outputPattern, template string) error {
+	subDir := filepath.Join(rootDir, name)
+	outputPattern = namedPattern(outputPattern)
+	log := s.logger().With("dir", subDir)
+	log.Debug("Scanning directory")
 
-				// Combine prefix and copyright information
-				copyrightText = prefixContent + copyrightText
+	// Scan subdirectory and write result, the output file name may use the
+	// number of statements found
+	var outputFile string
+	if IsStructuredFormat(s.OutputFormat) {
+		entries, err := s.scanDirectoryStructured(ctx, subDir)
+		if err = logFileErrors(log, err); err != nil {
+			return fmt.Errorf("failed to scan directory %s: %w", subDir, err)
+		}
+		var compatibility *LicenseCompatibility
+		if s.CheckCompatibility {
+			licenses, err := s.DetectLicenses(subDir)
+			if err != nil {
+				return fmt.Errorf("failed to detect licenses: %v", err)
 			}
+			verdict := CheckLicenseCompatibility(licenses)
+			compatibility = &verdict
+		}
+		vars := reportVars(name, rootDir, countCopyrights(entries))
+		if outputFile, err = s.WriteCheckedEntriesReport(renderTemplate(outputPattern, vars), name, entries, compatibility); err != nil {
+			return err
+		}
+	} else {
+		copyrightText, entries, err := s.scanDirectoryReport(ctx, os.DirFS(subDir), subDir, subDir)
+		if err = logFileErrors(log, err); err != nil {
+			return fmt.Errorf("failed to scan directory %s: %w", subDir, err)
+		}
+		vars := reportVars(name, rootDir, countCopyrights(entries))
+		if outputFile, err = s.writeReport(renderTemplate(outputPattern, vars), template, vars, copyrightText); err != nil {
+			return err
+		}
+	}
 
-			// Write result
-			if err := os.WriteFile(outputFile, []byte(copyrightText), 0644); err != nil {
-				return fmt.Errorf("failed to write file %s: %v", outputFile, err)
+	log.Info("Completed scanning directory", "output", outputFile)
+	return nil
+}
+
+// logFileErrors logs the files of a scan that couldn't be read as warnings
+// and returns nil for them, other errors are returned unchanged
+func logFileErrors(log *slog.Logger, err error) error {
+	var fileErrors FileErrors
+	if !errors.As(err, &fileErrors) {
+		return err
+	}
+	for _, fileError := range fileErrors {
+		log.Warn("Error processing file", "path", fileError.Path, "error", fileError.Err)
+	}
+	log.Warn("Skipped unreadable files or directories", "count", len(fileErrors))
+	return nil
+}
+
+// discardLogger drops every message, it is used while Logger is unset
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+
+// logger returns Logger, or a logger dropping every message if it is unset
+func (s *Scanner) logger() *slog.Logger {
+	if s.Logger == nil {
+		return discardLogger
+	}
+	return s.Logger
+}
+
+// DefaultTemplatePath is the prefix template used when TemplatePath is
+// unset, relative to the working directory
+const DefaultTemplatePath = "template/prefix.txt"
+
+// templatePath returns the prefix template to read, or "" if disabled
+func (s *Scanner) templatePath() string {
+	switch {
+	case s.NoTemplate:
+		return ""
+	case s.TemplatePath == "":
+		return DefaultTemplatePath
+	}
+	return s.TemplatePath
+}
+
+// WriteReport prefixes copyrightText with the prefix template, naming the
+// software in its "Software:" line and replacing the {name} and {date}
+// placeholders, writes it to outputFile and calls OutputWritten
+func (s *Scanner) WriteReport(outputFile, name, copyrightText string) (string, error) {
+	return s.writeReport(outputFile, s.readTemplate(), reportVars(name, "", -1), copyrightText)
+}
+
+// readTemplate returns the prefix template, or "" if it is disabled or can't be read
+func (s *Scanner) readTemplate() string {
+	path := s.templatePath()
+	if path == "" {
+		return ""
+	}
+	prefixBytes, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(prefixBytes)
+}
+
+// writeReport implements WriteReport, replacing the placeholders of the
+// prefix template with vars
+func (s *Scanner) writeReport(outputFile, template string, vars map[string]string, copyrightText string) (string, error) {
+	if template != "" {
+		prefixContent := renderTemplate(template, vars)
+
+		// Find and replace Software: line in the template
+		lines := strings.Split(prefixContent, "\n")
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "Software:" {
+				lines[i] = "Software: " + vars["name"]
+				break
 			}
+		}
+		prefixContent = strings.Join(lines, "\n")
 
-			fmt.Printf("Completed scanning %s, result saved to: %s\n", subDir, outputFile)
+		// Ensure prefix content ends with a newline
+		if !strings.HasSuffix(prefixContent, "\n") {
+			prefixContent += "\n"
 		}
+
+		// Combine prefix and copyright information
+		copyrightText = prefixContent + copyrightText
 	}
 
-	return nil
+	return s.writeOutput(outputFile, vars["name"], []byte(copyrightText))
 }
 
-// ScanDirectory scans a single directory
-func (s *Scanner) ScanDirectory(dir string) (string, error) {
-	var result strings.Builder
-	seenCopyrights := make(map[string]bool)
+// writeOutput writes a report to outputFile and calls OutputWritten
+func (s *Scanner) writeOutput(outputFile, name string, report []byte) (string, error) {
+	report, err := s.EncodeOutput(report)
+	if err != nil {
+		return "", err
+	}
+	outputFile, err = WriteOutputFile(outputFile, report, s.CompressOutput)
+	if err != nil {
+		return "", err
+	}
 
-	// First find and read LICENSE file
-	var licenseContent string
-	licenseFiles := []string{"LICENSE", "LICENSE.txt", "LICENSE.md", "license", "license.txt", "license.md"}
-	for _, licenseFile := range licenseFiles {
-		content, err := os.ReadFile(filepath.Join(dir, licenseFile))
-		if err == nil {
-			licenseContent = string(content)
-			break
+	if s.OutputWritten != nil {
+		if err := s.OutputWritten(outputFile, name); err != nil {
+			return outputFile, fmt.Errorf("post-processing of %s failed: %v", outputFile, err)
 		}
 	}
+	return outputFile, nil
+}
+
+// scanTextFile extracts the copyright lines and SPDX license expressions of a
+// text file, reporting its raw statements to FileScanned as found in source
+func (s *Scanner) scanTextFile(path, source string) (string, []string, error) {
+	fsys, name := osFile(path)
+	statements, licenses, err := s.extractStatementsAndLicenses(context.Background(), fsys, name)
+	if err != nil {
+		return "", nil, err
+	}
+	s.reportFile(source, statements)
+	return s.formatStatements(statements), licenses, nil
+}
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+// reportFile passes the statements found in a file to FileScanned, if set
+func (s *Scanner) reportFile(path string, statements []string) {
+	if s.FileScanned == nil {
+		return
+	}
+
+	copyrights := make([]string, len(statements))
+	for i, statement := range statements {
+		if s.AnonymizePersonalNames {
+			statement = anonymizeCopyright(statement, s.pseudonymKey())
+		}
+		copyrights[i] = statement
+	}
+	s.FileScanned(path, copyrights)
+}
+
+// ScanFiles scans exactly the given files, skipping the directory walk
+func (s *Scanner) ScanFiles(paths []string) (string, error) {
+	result, err := s.scanFileEntries(paths)
+	if err != nil {
+		return "", err
+	}
+	report := s.formatResult(result)
+
+	// Judge whether the listed license files can be distributed together
+	if s.CheckCompatibility {
+		licenses, err := DetectFileLicenses(paths)
 		if err != nil {
-			return err
+			return "", fmt.Errorf("failed to detect licenses: %v", err)
+		}
+		report += formatCompatibility(CheckLicenseCompatibility(licenses))
+	}
+	return report, nil
+}
+
+// ScanFilesStructured scans exactly the given files like ScanFiles, returning
+// every copyright statement as an entry
+func (s *Scanner) ScanFilesStructured(paths []string) ([]CopyrightEntry, error) {
+	result, err := s.scanFileEntries(paths)
+	if err != nil {
+		return nil, err
+	}
+	return result.entries, nil
+}
+
+// scanFileEntries collects the copyright entries, license expressions and
+// inline licenses of the given files
+func (s *Scanner) scanFileEntries(paths []string) (*scanResult, error) {
+	result := &scanResult{}
+
+	for _, path := range paths {
+		// Listed paths are expected to exist, so a missing file is an error
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat file %s: %v", path, err)
 		}
 
-		// Skip directories and non-text files
-		if info.IsDir() {
-			return nil
+		if info.IsDir() || !s.includedExtension(path) {
+			continue
 		}
+
+		// Images carry their copyright in metadata, attributed to the image itself
+		if s.ScanImageMetadata && isImageFile(path) {
+			copyright, err := s.extractImageCopyright(path, path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to process file %s: %v", path, err)
+			}
+			result.entries = append(result.entries, s.fileEntries(copyright, path, path, "", s.IsThirdParty(path))...)
+			continue
+		}
+
+		// Skip non-text files
 		if !s.isTextFile(path) {
-			return nil
+			continue
 		}
 
 		// Extract copyright information
-		copyright, err := s.extractCopyright(path)
+		copyright, licenses, err := s.scanTextFile(path, path)
 		if err != nil {
-			fmt.Printf("Error processing file %s: %v\n", path, err)
-			return nil
-		}
-
-		// If copyright information is found, add to result (avoid duplicates)
-		if copyright != "" {
-			// Split multi-line copyright information
-			copyrights := strings.Split(copyright, "\n")
-			for _, c := range copyrights {
-				if c != "" && !seenCopyrights[c] {
-					seenCopyrights[c] = true
-					result.WriteString(c + "\n")
-				}
+			return nil, fmt.Errorf("failed to process file %s: %v", path, err)
+		}
+		result.addFile(s.fileEntries(copyright, path, "", firstLicense(licenses), s.IsThirdParty(path)), licenses)
+
+		if s.InlineLicenses {
+			licenses, err := s.inlineLicenses(osFile(path))
+			if err != nil {
+				return nil, fmt.Errorf("failed to process file %s: %v", path, err)
 			}
+			result.inlineLicenses.WriteString(formatInlineLicenses(path, licenses))
 		}
+	}
 
-		return nil
-	})
+	s.entriesScanned(result.entries)
+	return result, nil
+}
+
+// entriesScanned passes the entries of a scan to EntriesScanned, if set
+func (s *Scanner) entriesScanned(entries []CopyrightEntry) {
+	if s.EntriesScanned != nil {
+		s.EntriesScanned(entries)
+	}
+}
+
+// ScanFile scans a single file
+func (s *Scanner) ScanFile(path string) (string, error) {
+	if err := s.checkSingleFile(path); err != nil {
+		return "", err
+	}
+	return s.ScanFiles([]string{path})
+}
+
+// ScanFileStructured scans a single file like ScanFile, returning every
+// copyright statement as an entry
+func (s *Scanner) ScanFileStructured(path string) ([]CopyrightEntry, error) {
+	if err := s.checkSingleFile(path); err != nil {
+		return nil, err
+	}
+	return s.ScanFilesStructured([]string{path})
+}
+
+// checkSingleFile checks that path is a file ScanFile can scan
+func (s *Scanner) checkSingleFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat file %s: %v", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if !(s.ScanImageMetadata && isImageFile(path)) && !s.isTextFile(path) {
+		return fmt.Errorf("%s is not a text file", path)
+	}
+	return nil
+}
+
+// ScanDirectory scans a single directory. Files that couldn't be read are
+// skipped and returned as FileErrors, along with the report of the others
+func (s *Scanner) ScanDirectory(dir string) (string, error) {
+	return s.ScanDirectoryContext(context.Background(), dir)
+}
+
+// ScanDirectoryContext scans a single directory like ScanDirectory, aborting
+// with ctx.Err() as soon as ctx is cancelled or its deadline passes
+func (s *Scanner) ScanDirectoryContext(ctx context.Context, dir string) (string, error) {
+	return s.scanDirectoryAs(ctx, dir, dir)
+}
+
+// ScanDirectoryAs scans a single directory like ScanDirectory, but reports its
+// files to FileScanned by their path relative to dir joined to base. An empty
+// base reports the relative paths, e.g. the entry names of an extracted archive
+func (s *Scanner) ScanDirectoryAs(dir, base string) (string, error) {
+	return s.scanDirectoryAs(context.Background(), dir, base)
+}
+
+// scanDirectoryAs implements ScanDirectoryAs, aborting once ctx is done
+func (s *Scanner) scanDirectoryAs(ctx context.Context, dir, base string) (string, error) {
+	report, _, err := s.scanDirectoryReport(ctx, os.DirFS(dir), dir, base)
+	return report, err
+}
 
+// scanDirectoryReport implements scanDirectoryAs for fsys, the tree of dir,
+// also returning the entries the report was formatted from
+func (s *Scanner) scanDirectoryReport(ctx context.Context, fsys fs.FS, dir, base string) (string, []CopyrightEntry, error) {
+	// First find and read the root license and notice files
+	licenseTexts := rootLicenseTexts(fsys)
+
+	scanned, err := s.scanDirectoryEntries(ctx, fsys, dir, base)
 	if err != nil {
-		return "", fmt.Errorf("Error scanning directory: %v", err)
+		return "", nil, err
 	}
 
-	// If LICENSE file is found, add to result at the end
-	if licenseContent != "" {
-		// Add a separator line
-		result.WriteString("\nLicense Text:\n")
-		result.WriteString("----------------------------------------\n\n")
-		result.WriteString(licenseContent)
+	var result strings.Builder
+	result.WriteString(s.formatResult(scanned))
 
-		// Ensure file ends with a newline
-		if !strings.HasSuffix(licenseContent, "\n") {
-			result.WriteString("\n")
+	// Judge whether the detected licenses can be distributed together
+	if s.CheckCompatibility {
+		licenses, err := s.detectLicenses(fsys)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to detect licenses: %v", err)
 		}
+		result.WriteString(formatCompatibility(CheckLicenseCompatibility(licenses)))
 	}
 
-	return result.String(), nil
+	// If license files are found, add their texts to result at the end
+	result.WriteString(s.licenseTextSection(licenseTexts))
+
+	return result.String(), scanned.entries, scanned.err()
 }
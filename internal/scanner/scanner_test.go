@@ -0,0 +1,700 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExtractCopyrightFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{"year_next_line.go", "Copyright The Acme Project 2024\n"},
+		{"year_after_blank.py", "Copyright The Acme Project 2019-2024\n"},
+		{"copr.c", "Copr. 2001 Acme Widgets Inc.\n(Copr) 1998 Legacy Systems Ltd.\n"},
+		{"prose.md", "Copyright 2024 Acme Corp.\nCopyright The Acme Project\n"},
+		{"adjacent_license.c", "Copyright 2024 The Test Authors\nCopyright 2023 Acme Corp.\n"},
+		{"url_comment.sql", "Copyright 2024 Acme Corp. https://acme.example/legal\n"},
+		{"utf16le.ps1", "Copyright (c) 2022 Acme Windows Tools Ltd.\n"},
+		{"utf16be.rc", "Copyright © 2021 Contoso GmbH\n"},
+		{"utf8_bom.c", "Copyright 2020 BOM Prefixed Inc.\n"},
+		{"latin1_author.c", "Copyright 2019 José García All rights reserved.\n"},
+		{"cjk_fullwidth.c", "Copyright (C) 2024 株式会社サンプル\n著作権表示 (c) 2023 示例有限公司\n"},
+	}
+
+	s := NewScanner()
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := s.extractCopyright(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("extractCopyright failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("extractCopyright() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultEncoding(t *testing.T) {
+	// 0xA4 is the euro sign in ISO-8859-15 and the currency sign in Windows-1252
+	input := "Copyright 2021 Caf\xe9 \xa4 Ltd.\n"
+	tests := []struct {
+		encoding string
+		want     string
+	}{
+		{"", "Copyright 2021 Café ¤ Ltd.\n"},
+		{"iso-8859-15", "Copyright 2021 Café € Ltd.\n"},
+		{"Latin1", "Copyright 2021 Café ¤ Ltd.\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			s := NewScanner()
+			s.DefaultEncoding = tt.encoding
+			got, err := s.extractCopyrightFromReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("extractCopyrightFromReader failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("extractCopyrightFromReader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Valid UTF-8 is never transcoded, even if a sample cuts a character in two
+	s := NewScanner()
+	s.TextDetectionBytes = len("Copyright 2021 Caf") + 1
+	got, err := s.extractCopyrightFromReader(strings.NewReader("Copyright 2021 Café Ltd.\n"))
+	if err != nil {
+		t.Fatalf("extractCopyrightFromReader failed: %v", err)
+	}
+	if want := "Copyright 2021 Café Ltd.\n"; got != want {
+		t.Errorf("extractCopyrightFromReader() = %q, want %q", got, want)
+	}
+}
+
+func TestRequireRightsPhrase(t *testing.T) {
+	fixture := filepath.Join("testdata", "borderline_c.c")
+
+	lenient := NewScanner()
+	got, err := lenient.extractCopyright(fixture)
+	if err != nil {
+		t.Fatalf("extractCopyright failed: %v", err)
+	}
+	if !strings.Contains(got, "see note (c) above") {
+		t.Errorf("expected the borderline match without RequireRightsPhrase, got %q", got)
+	}
+
+	strict := NewScanner()
+	strict.RequireRightsPhrase = true
+	got, err = strict.extractCopyright(fixture)
+	if err != nil {
+		t.Fatalf("extractCopyright failed: %v", err)
+	}
+	want := "Copyright Acme Widgets GmbH\nCopyright Jane Doe. All rights reserved.\nCopyright 2021 Example Project\n"
+	if got != want {
+		t.Errorf("extractCopyright() = %q, want %q", got, want)
+	}
+
+	strict.RightsPhrases = []string{"Alle Rechte vorbehalten"}
+	got, err = strict.extractCopyright(fixture)
+	if err != nil {
+		t.Fatalf("extractCopyright failed: %v", err)
+	}
+	if strings.Contains(got, "Jane Doe") {
+		t.Errorf("custom phrases should replace the defaults, got %q", got)
+	}
+}
+
+func TestScanFile(t *testing.T) {
+	s := NewScanner()
+
+	got, err := s.ScanFile(filepath.Join("testdata", "copr.c"))
+	if err != nil {
+		t.Fatalf("ScanFile failed: %v", err)
+	}
+	want, err := s.extractCopyright(filepath.Join("testdata", "copr.c"))
+	if err != nil {
+		t.Fatalf("extractCopyright failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("ScanFile() = %q, want %q", got, want)
+	}
+
+	if _, err := s.ScanFile("testdata"); err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Errorf("expected a directory error, got %v", err)
+	}
+	if _, err := s.ScanFile(filepath.Join("testdata", "missing.go")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestScanSubDirectoriesOutputWritten(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"alpha/main.go": "// Copyright 2024 Acme Corp.\n",
+		"beta/main.go":  "// Copyright 2024 Example Inc.\n",
+	})
+	pattern := filepath.Join(t.TempDir(), "copyright_{name}.txt")
+
+	var written []string
+	s := NewScanner()
+	s.OutputWritten = func(outputFile, name string) error {
+		written = append(written, name+"="+filepath.Base(outputFile))
+		return nil
+	}
+	if err := s.ScanSubDirectories(root, pattern); err != nil {
+		t.Fatalf("ScanSubDirectories failed: %v", err)
+	}
+	if strings.Join(written, ",") != "alpha=copyright_alpha.txt,beta=copyright_beta.txt" {
+		t.Errorf("unexpected hook calls: %v", written)
+	}
+
+	// A failing hook fails the scan, but every subdirectory is still scanned
+	written = nil
+	s.OutputWritten = func(outputFile, name string) error {
+		written = append(written, name)
+		return errors.New("upload failed")
+	}
+	err := s.ScanSubDirectories(root, pattern)
+	if err == nil || !strings.Contains(err.Error(), "upload failed") {
+		t.Errorf("expected the hook error, got %v", err)
+	}
+	if strings.Join(written, ",") != "alpha,beta" {
+		t.Errorf("expected every subdirectory to be scanned, got %v", written)
+	}
+}
+
+func TestScanSubDirectoriesLogger(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"alpha/main.go": "// Copyright 2024 Acme Corp.\n",
+	})
+	pattern := filepath.Join(t.TempDir(), "copyright_{name}.txt")
+
+	var logs bytes.Buffer
+	s := NewScanner()
+	s.NoTemplate = true
+	s.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	if err := s.ScanSubDirectories(root, pattern); err != nil {
+		t.Fatalf("ScanSubDirectories failed: %v", err)
+	}
+
+	got := logs.String()
+	want := fmt.Sprintf("level=INFO msg=\"Completed scanning directory\" dir=%s output=%s", filepath.Join(root, "alpha"), filepath.Join(filepath.Dir(pattern), "copyright_alpha.txt"))
+	if !strings.Contains(got, want) {
+		t.Errorf("log %q lacks %q", got, want)
+	}
+	if strings.Contains(got, "level=DEBUG") {
+		t.Errorf("log %q has debug records below the handler level", got)
+	}
+
+	// Without a logger nothing is logged and the scan still succeeds
+	s.Logger = nil
+	if err := s.ScanSubDirectories(root, pattern); err != nil {
+		t.Fatalf("ScanSubDirectories without a logger failed: %v", err)
+	}
+}
+
+func TestScanSubDirectoriesJoinsErrors(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"alpha/main.go": "// Copyright 2024 Acme Corp.\n",
+		"beta/main.go":  "// Copyright 2024 Beta Inc.\n",
+		"gamma/main.go": "// Copyright 2024 Gamma Ltd.\n",
+	})
+	outDir := t.TempDir()
+
+	// The first failure doesn't abort the other workers or stop new scans
+	s := NewScanner()
+	s.NoTemplate = true
+	s.ParallelSubDirectories = 2
+	s.OutputWritten = func(outputFile, name string) error {
+		if name == "gamma" {
+			return nil
+		}
+		return errors.New("upload of " + name + " failed")
+	}
+
+	err := s.ScanSubDirectories(root, filepath.Join(outDir, "copyright_{name}.txt"))
+	if err == nil {
+		t.Fatal("expected the hook errors")
+	}
+	for _, want := range []string{"upload of alpha failed", "upload of beta failed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q lacks %q", err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "copyright_gamma.txt")); err != nil {
+		t.Errorf("expected the report after the failures to be written: %v", err)
+	}
+}
+
+func TestScanSubDirectoriesContext(t *testing.T) {
+	root := t.TempDir()
+	files := make(map[string]string)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		files[name+"/main.go"] = "// Copyright 2024 Acme Corp.\n"
+	}
+	writeFiles(t, root, files)
+	outDir := t.TempDir()
+	pattern := filepath.Join(outDir, "copyright_{name}.txt")
+
+	s := NewScanner()
+	s.ParallelSubDirectories = 3
+	var reported []int
+	err := s.ScanSubDirectoriesContext(context.Background(), root, pattern, func(done, total int) {
+		if total != 5 {
+			t.Errorf("total = %d, want 5", total)
+		}
+		reported = append(reported, done)
+	})
+	if err != nil {
+		t.Fatalf("ScanSubDirectoriesContext failed: %v", err)
+	}
+	if fmt.Sprint(reported) != "[1 2 3 4 5]" {
+		t.Errorf("unexpected progress: %v", reported)
+	}
+
+	// Cancelling stops new scans but keeps the written files
+	outDir = t.TempDir()
+	pattern = filepath.Join(outDir, "copyright_{name}.txt")
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ParallelSubDirectories = 1
+	err = s.ScanSubDirectoriesContext(ctx, root, pattern, func(done, total int) {
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	written, _ := filepath.Glob(filepath.Join(outDir, "*.txt"))
+	if len(written) == 0 || len(written) >= 5 {
+		t.Errorf("expected the scan to stop early, %d files written", len(written))
+	}
+}
+
+func TestScanDirectoryContext(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go": "// Copyright 2024 Acme Corp.\npackage main\n",
+	})
+	s := NewScanner()
+
+	report, err := s.ScanDirectoryContext(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("ScanDirectoryContext failed: %v", err)
+	}
+	if !strings.Contains(report, "Copyright 2024 Acme Corp.") {
+		t.Errorf("report lacks the copyright:\n%s", report)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.ScanDirectoryContext(ctx, dir); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if _, err := s.ScanDirectoryContext(ctx, dir); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestScanFileUnicodePunctuation(t *testing.T) {
+	// The exact dedup of MergeYears sees the ASCII and typographic forms as one
+	for _, mergeYears := range []bool{false, true} {
+		s := NewScanner()
+		s.MergeYears = mergeYears
+		got, err := s.ScanFile(filepath.Join("testdata", "unicode_punctuation.txt"))
+		if err != nil {
+			t.Fatalf("ScanFile failed: %v", err)
+		}
+		want := "Copyright © 2019-2023 Acme Corp.\n" +
+			"Copyright (c) 2015-2017 \"Widget\" Project\n" +
+			"Copyright 2021 O'Reilly Media, Inc.\n" +
+			"Copyright © 2020 Example Inc.\n"
+		if got != want {
+			t.Errorf("ScanFile() with MergeYears=%v = %q, want %q", mergeYears, got, want)
+		}
+	}
+}
+
+func TestHeaderLinesOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"unterminated.go": "// Copyright 2024 Acme Corp.\npackage main\n// Copyright 2023 Jane Doe",
+		"terminated.go":   "// Copyright 2024 Acme Corp.\npackage main\n// Copyright 2023 Jane Doe\n",
+	})
+
+	tests := []struct {
+		file  string
+		lines int
+		want  string
+	}{
+		{"unterminated.go", 0, "Copyright 2024 Acme Corp.\nCopyright 2023 Jane Doe\n"},
+		{"unterminated.go", 2, "Copyright 2024 Acme Corp.\n"},
+		{"unterminated.go", 3, "Copyright 2024 Acme Corp.\nCopyright 2023 Jane Doe\n"},
+		{"terminated.go", 3, "Copyright 2024 Acme Corp.\nCopyright 2023 Jane Doe\n"},
+		{"terminated.go", 1, "Copyright 2024 Acme Corp.\n"},
+	}
+	for _, tt := range tests {
+		s := NewScanner()
+		s.HeaderLinesOnly = tt.lines
+		got, err := s.extractCopyright(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatalf("extractCopyright failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("%s with HeaderLinesOnly=%d = %q, want %q", tt.file, tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestIsTextFile(t *testing.T) {
+	binary := make([]byte, 4096)
+	for i := range binary {
+		binary[i] = byte(i * 7)
+	}
+	header := "// Copyright 2024 Acme Corp.\n" + strings.Repeat("// padding\n", 20)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"plain.go":      "// Copyright 2024 Acme Corp.\npackage main\n",
+		"empty.txt":     "",
+		"stray_null.c":  "/* Copyright 2024 Acme Corp. */\x00\nint main(void) { return 0; }\n",
+		"form_feed.c":   "/* Copyright 2024 Acme Corp. */\n\f\nint x;\n",
+		"utf8.txt":      "Copyright © 2024 Société Générale — 株式会社\n",
+		"binary.bin":    string(binary),
+		"header.bin":    header + string(binary),
+		"null_heavy.db": strings.Repeat("ab\x00\x00", 100),
+	})
+
+	tests := []struct {
+		file      string
+		sampleLen int
+		want      bool
+	}{
+		{"plain.go", 0, true},
+		{"empty.txt", 0, true},
+		{"stray_null.c", 0, true},
+		{"form_feed.c", 0, true},
+		{"utf8.txt", 0, true},
+		{"binary.bin", 0, false},
+		{"header.bin", 0, false},
+		{"header.bin", len(header), true},
+		{"null_heavy.db", 0, false},
+	}
+	for _, tt := range tests {
+		s := NewScanner()
+		s.TextDetectionBytes = tt.sampleLen
+		if got := s.isTextFile(filepath.Join(dir, tt.file)); got != tt.want {
+			t.Errorf("isTextFile(%s) with TextDetectionBytes=%d = %v, want %v", tt.file, tt.sampleLen, got, tt.want)
+		}
+	}
+}
+
+func TestCleanLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"// Copyright 2024 Acme Corp. https://acme.example/legal", "Copyright 2024 Acme Corp. https://acme.example/legal"},
+		{"/* Copyright (c) 2019-2024 Acme Corp. */", "Copyright (c) 2019-2024 Acme Corp."},
+		{"<!-- Copyright 2024 Acme Corp. -->", "Copyright 2024 Acme Corp."},
+		{";; Copyright 2024 Jane Doe", "Copyright 2024 Jane Doe"},
+		{"% Copyright 2024 Jane Doe", "Copyright 2024 Jane Doe"},
+		{"REM Copyright 2024 Acme Corp.", "Copyright 2024 Acme Corp."},
+		{"rem Copyright 2024 Acme Corp.", "Copyright 2024 Acme Corp."},
+		{"REMARKABLE Corp.", "REMARKABLE Corp."},
+		{"-- Copyright 2024 Acme Corp.", "Copyright 2024 Acme Corp."},
+		{"# Copyright 2024 C++ Tools Ltd. #", "Copyright 2024 C++ Tools Ltd."},
+		{" *   Copyright   2024   Acme ", "Copyright 2024 Acme"},
+		{"// Copyright © 2019–2023 “Acme” Corp.", `Copyright © 2019-2023 "Acme" Corp.`},
+		{"# Copyright Â© 2021 O’Reilly Media", "Copyright © 2021 O'Reilly Media"},
+	}
+
+	for _, tt := range tests {
+		if got := cleanLine(tt.line, DefaultCommentPrefixes); got != tt.want {
+			t.Errorf("cleanLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	// Custom prefixes replace the defaults
+	s := NewScanner()
+	s.CommentPrefixes = []string{"!"}
+	if got := s.cleanLine("! Copyright 2024 Acme Corp."); got != "Copyright 2024 Acme Corp." {
+		t.Errorf("cleanLine with custom prefixes = %q", got)
+	}
+	if got := s.cleanLine("// Copyright 2024 Acme Corp."); got != "// Copyright 2024 Acme Corp." {
+		t.Errorf("cleanLine with custom prefixes stripped a default prefix: %q", got)
+	}
+}
+
+func TestBlockCommentBounds(t *testing.T) {
+	tests := []struct {
+		line                        string
+		inBlock                     bool
+		opens, closes, inBlockAfter bool
+	}{
+		{"/*", false, true, false, true},
+		{"* Copyright 2025 Acme Corp", true, false, false, true},
+		{"*/", true, false, true, false},
+		{"/* Copyright 2023 Jane Doe */", false, true, true, false},
+		{"// see src/*", false, false, false, false},
+		{"int x; /* start", false, true, false, true},
+		{"end */ /* again", true, true, true, true},
+	}
+
+	for _, tt := range tests {
+		opens, closes, inBlockAfter := blockCommentBounds(tt.line, tt.inBlock)
+		if opens != tt.opens || closes != tt.closes || inBlockAfter != tt.inBlockAfter {
+			t.Errorf("blockCommentBounds(%q, %v) = %v, %v, %v, want %v, %v, %v",
+				tt.line, tt.inBlock, opens, closes, inBlockAfter, tt.opens, tt.closes, tt.inBlockAfter)
+		}
+	}
+}
+
+func TestWriteReportTemplate(t *testing.T) {
+	dir := t.TempDir()
+	template := filepath.Join(dir, "prefix.txt")
+	if err := os.WriteFile(template, []byte("NOTICE\nSoftware:\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		scanner *Scanner
+		want    string
+	}{
+		{"custom template", &Scanner{TemplatePath: template}, "NOTICE\nSoftware: demo\nCopyright 2024 Acme Corp.\n"},
+		{"disabled", &Scanner{TemplatePath: template, NoTemplate: true}, "Copyright 2024 Acme Corp.\n"},
+		{"missing template", &Scanner{TemplatePath: filepath.Join(dir, "missing.txt")}, "Copyright 2024 Acme Corp.\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile, err := tt.scanner.WriteReport(filepath.Join(dir, "report.txt"), "demo", "Copyright 2024 Acme Corp.\n")
+			if err != nil {
+				t.Fatalf("WriteReport failed: %v", err)
+			}
+			got, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("WriteReport wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreserveOriginal(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.c": "/*\n * Copyright (c)  2024   Acme Corp.\n *   All rights reserved.\n */\n",
+		"b.c": "// Copyright (c) 2024 Acme Corp. All rights reserved.\n",
+	})
+
+	s := NewScanner()
+	s.PreserveOriginal = true
+	entries, err := s.ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectoryStructured failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	raw := "Copyright (c)  2024   Acme Corp. All rights reserved."
+	if got := entries[0]; got.RawText != raw || got.CleanText != "Copyright (c) 2024 Acme Corp. All rights reserved." || got.Holder != "Acme Corp" {
+		t.Errorf("unexpected entry %+v", got)
+	}
+
+	// The verbatim forms of one statement are reported once
+	result, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if !strings.HasPrefix(result, raw+"\n") || strings.Count(result, "Acme Corp") != 1 {
+		t.Errorf("expected the verbatim statement once, got:\n%s", result)
+	}
+}
+
+func TestExtractFromReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"comment header", "// Copyright 2024 Acme Corp.\npackage main\n", "Copyright 2024 Acme Corp.\n"},
+		{"duplicates", "# Copyright 2024 Acme Corp.\n\n# copyright 2024 acme corp\n", "Copyright 2024 Acme Corp.\n"},
+		{"utf-16 with bom", "\xff\xfeC\x00o\x00p\x00y\x00r\x00i\x00g\x00h\x00t\x00 \x002\x000\x002\x004\x00 \x00A\x00c\x00m\x00e\x00\n\x00", "Copyright 2024 Acme\n"},
+		{"no statement", "package main\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewScanner().ExtractFromReader(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("ExtractFromReader failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractFromReader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanReader(t *testing.T) {
+	binary := make([]byte, 4096)
+	for i := range binary {
+		binary[i] = byte(i * 7)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"short text", "// Copyright 2024 Acme Corp.\n", "Copyright 2024 Acme Corp.\n", false},
+		// The statements inside the sample and after it must both survive the peek
+		{"longer than the sample", "// Copyright 2024 Acme Corp.\n\n" + strings.Repeat("x = 1\n", 2000) + "// Copyright 2023 Jane Doe\n", "Copyright 2024 Acme Corp.\nCopyright 2023 Jane Doe\n", false},
+		{"empty", "", "", false},
+		{"binary", string(binary), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewScanner().ScanReader(strings.NewReader(tt.content))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "not text") {
+					t.Fatalf("expected a not text error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ScanReader failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ScanReader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	entries, err := NewScanner().ScanReaderStructured(strings.NewReader("// SPDX-License-Identifier: MIT\n// Copyright 2024 Acme Corp.\n"), "stdin")
+	if err != nil {
+		t.Fatalf("ScanReaderStructured failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].SourceFile != "stdin" || entries[0].Holder != "Acme Corp" || entries[0].License != "MIT" {
+		t.Errorf("ScanReaderStructured() = %+v", entries)
+	}
+}
+
+func TestScannerConcurrentScans(t *testing.T) {
+	projects := t.TempDir()
+	writeFiles(t, projects, map[string]string{
+		"alpha/LICENSE":        "MIT License\n\nCopyright (c) 2024 Acme Corp.\n",
+		"alpha/.gitignore":     "build/\n",
+		"alpha/main.go":        "// Copyright 2024 Acme Corp.\n// SPDX-License-Identifier: MIT\n",
+		"alpha/build/gen.go":   "// Copyright 2020 Generated Inc.\n",
+		"alpha/vendor/dep.go":  "// Copyright 2019 Example Inc.\n",
+		"beta/lib/util.c":      "/* Copyright (c) 2021 Jane Doe, John Smith and Acme Corp. */\n",
+		"beta/lib/util.h":      "/* Copyright 2021-2023 Jane Doe */\n",
+		"beta/docs/README.txt": "Copyright 2022 Beta Team\n",
+	})
+	dirs := []string{filepath.Join(projects, "alpha"), filepath.Join(projects, "beta")}
+
+	s := NewScanner()
+	s.RespectGitignore = true
+	s.ThirdPartyDirs = []string{"vendor"}
+	s.MergeYears = true
+	s.SplitHolders = true
+	s.ValidateYears = true
+	s.Concurrency = 2
+	var mu sync.Mutex
+	scanned := 0
+	s.FileScanned = func(path string, copyrights []string) {
+		mu.Lock()
+		scanned++
+		mu.Unlock()
+	}
+
+	// The results of scans run one at a time
+	want := make(map[string]string)
+	wantEntries := make(map[string]int)
+	for _, dir := range dirs {
+		report, err := s.ScanDirectory(dir)
+		if err != nil {
+			t.Fatalf("ScanDirectory failed: %v", err)
+		}
+		entries, err := s.ScanDirectoryStructured(dir)
+		if err != nil {
+			t.Fatalf("ScanDirectoryStructured failed: %v", err)
+		}
+		want[dir], wantEntries[dir] = report, len(entries)
+	}
+
+	// The same scans on the same Scanner at once, run with -race to check
+	// that they share no state
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for i := 0; i < 8; i++ {
+		for _, dir := range dirs {
+			wg.Add(1)
+			go func(dir string) {
+				defer wg.Done()
+				report, err := s.ScanDirectory(dir)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if report != want[dir] {
+					errs <- fmt.Errorf("concurrent report of %s = %q, want %q", dir, report, want[dir])
+				}
+				entries, err := s.ScanDirectoryStructured(dir)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(entries) != wantEntries[dir] {
+					errs <- fmt.Errorf("concurrent scan of %s found %d entries, want %d", dir, len(entries), wantEntries[dir])
+				}
+			}(dir)
+		}
+	}
+
+	// Whole subdirectory runs with their own outputs
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.ScanSubDirectories(projects, filepath.Join(t.TempDir(), "copyright_{name}.txt")); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if scanned == 0 {
+		t.Error("FileScanned was never called")
+	}
+}
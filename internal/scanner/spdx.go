@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// spdxLicensePattern matches an SPDX-License-Identifier tag and captures its expression
+var spdxLicensePattern = regexp.MustCompile(`(?i)\bSPDX-License-Identifier:\s*(.*)$`)
+
+// spdxCommentClosers end a comment on the same line as the tag
+var spdxCommentClosers = []string{"*/", "-->", "*)", "#}", "%>", "--%>"}
+
+// spdxOperators are the operators combining the IDs of a license expression
+var spdxOperators = map[string]bool{"OR": true, "AND": true, "WITH": true}
+
+// spdxLicenseList holds the IDs of the SPDX License List in common use,
+// keyed by their lowercase form
+var spdxLicenseList = spdxIDSet(
+	"0BSD", "AFL-3.0", "AGPL-1.0", "AGPL-3.0", "AGPL-3.0-only", "AGPL-3.0-or-later",
+	"Apache-1.0", "Apache-1.1", "Apache-2.0", "APSL-2.0", "Artistic-1.0", "Artistic-2.0",
+	"BlueOak-1.0.0", "BSD-1-Clause", "BSD-2-Clause", "BSD-2-Clause-Patent", "BSD-3-Clause",
+	"BSD-3-Clause-Clear", "BSD-4-Clause", "BSL-1.0", "CC-BY-3.0", "CC-BY-4.0",
+	"CC-BY-NC-4.0", "CC-BY-SA-3.0", "CC-BY-SA-4.0", "CC0-1.0", "CDDL-1.0", "CDDL-1.1",
+	"CECILL-2.1", "CPL-1.0", "ECL-2.0", "EPL-1.0", "EPL-2.0", "EUPL-1.1", "EUPL-1.2",
+	"GFDL-1.3", "GFDL-1.3-only", "GFDL-1.3-or-later", "GPL-1.0", "GPL-2.0", "GPL-2.0-only",
+	"GPL-2.0-or-later", "GPL-3.0", "GPL-3.0-only", "GPL-3.0-or-later", "HPND", "ISC",
+	"LGPL-2.0", "LGPL-2.0-only", "LGPL-2.0-or-later", "LGPL-2.1", "LGPL-2.1-only",
+	"LGPL-2.1-or-later", "LGPL-3.0", "LGPL-3.0-only", "LGPL-3.0-or-later", "LPPL-1.3c",
+	"MIT", "MIT-0", "MPL-1.1", "MPL-2.0", "MS-PL", "MS-RL", "MulanPSL-2.0", "NCSA",
+	"ODbL-1.0", "OFL-1.1", "OpenSSL", "OSL-3.0", "PHP-3.01", "PostgreSQL", "PSF-2.0",
+	"Python-2.0", "Ruby", "Unicode-DFS-2016", "Unlicense", "UPL-1.0", "Vim", "W3C",
+	"WTFPL", "X11", "Zlib", "ZPL-2.1",
+)
+
+// spdxIDSet keys the given IDs by their lowercase form
+func spdxIDSet(ids ...string) map[string]string {
+	set := make(map[string]string, len(ids))
+	for _, id := range ids {
+		set[strings.ToLower(id)] = id
+	}
+	return set
+}
+
+// knownSPDXLicense returns the canonical form of an SPDX license ID, matched
+// ignoring case, and whether it is on the SPDX License List
+func knownSPDXLicense(id string) (string, bool) {
+	canonical, ok := spdxLicenseList[strings.ToLower(id)]
+	return canonical, ok
+}
+
+// licenseRefPrefixes start the IDs of licenses defined outside the SPDX License List
+var licenseRefPrefixes = []string{"LicenseRef-", "DocumentRef-"}
+
+// licenseRefInvalidPattern matches the characters not allowed in a LicenseRef ID
+var licenseRefInvalidPattern = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// licenseRef is a license outside the SPDX License List, referred to by its
+// LicenseRef ID in a normalized expression
+type licenseRef struct {
+	id, name string
+}
+
+// normalizeSPDXExpression rewrites a license expression as a valid SPDX
+// expression: operators are upper case, listed IDs take their canonical case,
+// and other license names become LicenseRef IDs, which are returned in order
+// of appearance. Exceptions following WITH are kept as written
+func normalizeSPDXExpression(expression string) (string, []licenseRef) {
+	// Consecutive words without an operator between them name one license,
+	// as in "Acme Proprietary"
+	var tokens []string
+	joinable := false
+	for _, token := range strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)) {
+		operator := strings.ToUpper(token)
+		isName := token != "(" && token != ")" && !spdxOperators[operator]
+		if isName && joinable {
+			tokens[len(tokens)-1] += " " + token
+			continue
+		}
+		tokens = append(tokens, token)
+		joinable = isName && (len(tokens) < 2 || strings.ToUpper(tokens[len(tokens)-2]) != "WITH")
+	}
+
+	var normalized strings.Builder
+	var refs []licenseRef
+	exception := false
+	for i, token := range tokens {
+		if i > 0 && tokens[i-1] != "(" && token != ")" {
+			normalized.WriteByte(' ')
+		}
+
+		operator := strings.ToUpper(token)
+		switch {
+		case token == "(" || token == ")" || exception:
+		case spdxOperators[operator]:
+			token = operator
+		default:
+			// A trailing + means "or any later version" of a listed license
+			id := strings.TrimSuffix(token, "+")
+			if canonical, ok := knownSPDXLicense(id); ok {
+				token = canonical + token[len(id):]
+				break
+			}
+			if hasLicenseRefPrefix(token) {
+				break
+			}
+			name := token
+			idString := strings.Trim(licenseRefInvalidPattern.ReplaceAllString(name, "-"), "-")
+			if idString == "" {
+				idString = "unknown"
+			}
+			token = "LicenseRef-" + idString
+			refs = appendLicenseRef(refs, licenseRef{id: token, name: name})
+		}
+		exception = operator == "WITH"
+		normalized.WriteString(token)
+	}
+	return normalized.String(), refs
+}
+
+// hasLicenseRefPrefix checks if an ID refers to a license outside the SPDX License List
+func hasLicenseRefPrefix(id string) bool {
+	for _, prefix := range licenseRefPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendLicenseRef appends ref to refs unless its ID is already there
+func appendLicenseRef(refs []licenseRef, ref licenseRef) []licenseRef {
+	for _, existing := range refs {
+		if existing.id == ref.id {
+			return refs
+		}
+	}
+	return append(refs, ref)
+}
+
+// spdxExpression returns the license expression of an SPDX-License-Identifier line
+func spdxExpression(line string) (string, bool) {
+	match := spdxLicensePattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+
+	expression := match[1]
+	for _, closer := range spdxCommentClosers {
+		if i := strings.Index(expression, closer); i >= 0 {
+			expression = expression[:i]
+		}
+	}
+	expression = strings.Join(strings.Fields(expression), " ")
+	return expression, expression != ""
+}
+
+// ParseSPDXExpression splits a license expression such as "Apache-2.0 OR MIT"
+// into its license and exception IDs, in order of appearance
+func ParseSPDXExpression(expression string) []string {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, token := range strings.Fields(expression) {
+		if spdxOperators[strings.ToUpper(token)] || seen[token] {
+			continue
+		}
+		seen[token] = true
+		ids = append(ids, token)
+	}
+	return ids
+}
+
+// spdxLicenseIDs returns the license IDs of an expression, without the
+// exceptions following WITH
+func spdxLicenseIDs(expression string) []string {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+
+	var ids []string
+	exception := false
+	for _, token := range strings.Fields(expression) {
+		operator := strings.ToUpper(token)
+		if spdxOperators[operator] {
+			exception = operator == "WITH"
+			continue
+		}
+		if !exception {
+			ids = append(ids, token)
+		}
+		exception = false
+	}
+	return ids
+}
+
+// detectedLicensesSection lists the distinct IDs of the given license expressions
+func detectedLicensesSection(expressions []string) string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, expression := range expressions {
+		for _, id := range ParseSPDXExpression(expression) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+
+	sort.Strings(ids)
+	return "\nDetected Licenses:\n----------------------------------------\n\n" + strings.Join(ids, "\n") + "\n"
+}
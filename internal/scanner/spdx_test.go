@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSPDXExpression(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"// SPDX-License-Identifier: MIT", []string{"MIT"}},
+		{"/* SPDX-License-Identifier: Apache-2.0 OR MIT */", []string{"Apache-2.0", "MIT"}},
+		{"# SPDX-License-Identifier: (MIT AND BSD-3-Clause) or GPL-2.0-only", []string{"MIT", "BSD-3-Clause", "GPL-2.0-only"}},
+		{"// SPDX-License-Identifier: GPL-2.0-or-later WITH Classpath-exception-2.0", []string{"GPL-2.0-or-later", "Classpath-exception-2.0"}},
+		{"<!-- SPDX-License-Identifier: CC-BY-4.0 -->", []string{"CC-BY-4.0"}},
+		{"// SPDX-License-Identifier:", nil},
+		{"// Licensed under the MIT license", nil},
+	}
+
+	for _, tt := range tests {
+		expression, _ := spdxExpression(tt.line)
+		if got := ParseSPDXExpression(expression); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSPDXExpression(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestDetectedLicenses(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go": "// SPDX-License-Identifier: Apache-2.0 OR MIT\n// Copyright 2024 Acme Corp.\npackage a\n",
+		"b.go": "// SPDX-License-Identifier: MIT\npackage b\n",
+	})
+
+	s := NewScanner()
+	result, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Copyright 2024 Acme Corp.\n" +
+		"\nDetected Licenses:\n----------------------------------------\n\n" +
+		"Apache-2.0\nMIT\n"
+	if result != want {
+		t.Errorf("ScanDirectory() = %q, want %q", result, want)
+	}
+
+	// Entries carry the license expression of their file
+	entries, err := s.ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].License != "Apache-2.0 OR MIT" {
+		t.Errorf("ScanDirectoryStructured() = %#v, want one entry with license %q", entries, "Apache-2.0 OR MIT")
+	}
+
+	// A single file reports its licenses too
+	single, err := s.ScanFile(filepath.Join(dir, "b.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(single, "Detected Licenses:") || !strings.HasSuffix(single, "MIT\n") {
+		t.Errorf("ScanFile() = %q, want a Detected Licenses section with MIT", single)
+	}
+}
+
+func TestNormalizeSPDXExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+		refs       []licenseRef
+	}{
+		{"MIT", "MIT", nil},
+		{"apache-2.0 or mit", "Apache-2.0 OR MIT", nil},
+		{"( MIT and bsd-3-clause )  Or GPL-2.0+", "(MIT AND BSD-3-Clause) OR GPL-2.0+", nil},
+		{"gpl-2.0-or-later with Classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0", nil},
+		{"LicenseRef-Acme OR MIT", "LicenseRef-Acme OR MIT", nil},
+		{"Acme Proprietary", "LicenseRef-Acme-Proprietary", []licenseRef{{"LicenseRef-Acme-Proprietary", "Acme Proprietary"}}},
+		{"Foo/Bar OR Foo/Bar", "LicenseRef-Foo-Bar OR LicenseRef-Foo-Bar", []licenseRef{{"LicenseRef-Foo-Bar", "Foo/Bar"}}},
+	}
+
+	for _, tt := range tests {
+		got, refs := normalizeSPDXExpression(tt.expression)
+		if got != tt.want || !reflect.DeepEqual(refs, tt.refs) {
+			t.Errorf("normalizeSPDXExpression(%q) = %q, %v, want %q, %v", tt.expression, got, refs, tt.want, tt.refs)
+		}
+	}
+}
+
+func TestKnownSPDXLicense(t *testing.T) {
+	tests := []struct {
+		id, want string
+		ok       bool
+	}{
+		{"MIT", "MIT", true},
+		{"apache-2.0", "Apache-2.0", true},
+		{"GPL-3.0-or-later", "GPL-3.0-or-later", true},
+		{"Proprietary", "", false},
+		{"MIT OR Apache-2.0", "", false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := knownSPDXLicense(tt.id); got != tt.want || ok != tt.ok {
+			t.Errorf("knownSPDXLicense(%q) = %q, %v, want %q, %v", tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxTopHolders is the number of holders listed in the portfolio statistics
+const maxTopHolders = 10
+
+// unknownLicense counts projects whose license file matches no known license
+const unknownLicense = "unknown"
+
+// ProjectStats summarizes the licenses and copyright header coverage of one project
+type ProjectStats struct {
+	Name               string   `json:"name"`
+	Licenses           []string `json:"licenses"`
+	HasLicenseFile     bool     `json:"has_license_file"`
+	SourceFiles        int      `json:"source_files"`
+	FilesWithCopyright int      `json:"files_with_copyright"`
+	HeaderCoverage     float64  `json:"header_coverage"`
+}
+
+// HolderCount is a copyright holder and the number of projects naming it
+type HolderCount struct {
+	Holder   string `json:"holder"`
+	Projects int    `json:"projects"`
+}
+
+// PortfolioStats aggregates the statistics of all projects under a root directory
+type PortfolioStats struct {
+	Projects               int            `json:"projects"`
+	LicenseDistribution    map[string]int `json:"license_distribution"`
+	ProjectsWithoutLicense int            `json:"projects_without_license"`
+	TopHolders             []HolderCount  `json:"top_holders"`
+	ProjectDetails         []ProjectStats `json:"project_details"`
+}
+
+// PortfolioStats scans every immediate subdirectory of rootDir as a project
+// and rolls the results up into portfolio-wide statistics
+func (s *Scanner) PortfolioStats(rootDir string) (*PortfolioStats, error) {
+	entries, err := os.ReadDir(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	stats := &PortfolioStats{
+		LicenseDistribution: make(map[string]int),
+		TopHolders:          []HolderCount{},
+		ProjectDetails:      []ProjectStats{},
+	}
+	holderProjects := make(map[string]int)
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		project, holders, err := s.projectStats(filepath.Join(rootDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan directory %s: %v", entry.Name(), err)
+		}
+		project.Name = entry.Name()
+
+		stats.Projects++
+		stats.ProjectDetails = append(stats.ProjectDetails, project)
+		if !project.HasLicenseFile {
+			stats.ProjectsWithoutLicense++
+		}
+		for _, license := range project.Licenses {
+			stats.LicenseDistribution[license]++
+		}
+		for holder := range holders {
+			holderProjects[holder]++
+		}
+	}
+
+	// Rank holders by the number of projects naming them, then by name
+	for holder, projects := range holderProjects {
+		stats.TopHolders = append(stats.TopHolders, HolderCount{Holder: holder, Projects: projects})
+	}
+	sort.Slice(stats.TopHolders, func(i, j int) bool {
+		if stats.TopHolders[i].Projects != stats.TopHolders[j].Projects {
+			return stats.TopHolders[i].Projects > stats.TopHolders[j].Projects
+		}
+		return stats.TopHolders[i].Holder < stats.TopHolders[j].Holder
+	})
+	if len(stats.TopHolders) > maxTopHolders {
+		stats.TopHolders = stats.TopHolders[:maxTopHolders]
+	}
+
+	return stats, nil
+}
+
+// projectStats collects the statistics of one project and the distinct holders it names
+func (s *Scanner) projectStats(dir string) (ProjectStats, map[string]bool, error) {
+	project := ProjectStats{Licenses: []string{}}
+	holders := make(map[string]bool)
+
+	// Only a license file at the project root counts as the project's LICENSE
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return project, nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isLicenseTextFileName(entry.Name()) && !isNoticeFileName(entry.Name()) {
+			project.HasLicenseFile = true
+			break
+		}
+	}
+
+	licenses, err := s.DetectLicenses(dir)
+	if err != nil {
+		return project, nil, err
+	}
+	if len(licenses) == 0 && project.HasLicenseFile {
+		licenses = []string{unknownLicense}
+	}
+	project.Licenses = append(project.Licenses, licenses...)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// License files themselves aren't expected to carry a header
+		if info.IsDir() || isLicenseTextFileName(info.Name()) || !s.isTextFile(path) {
+			return nil
+		}
+
+		statements, err := s.extractStatements(path)
+		if err != nil {
+			return nil
+		}
+		project.SourceFiles++
+
+		copyright := s.formatStatements(statements)
+		if copyright == "" {
+			return nil
+		}
+		project.FilesWithCopyright++
+		for _, statement := range strings.Split(strings.TrimSuffix(copyright, "\n"), "\n") {
+			if s.AnonymizePersonalNames {
+				statement = anonymizeCopyright(statement, s.pseudonymKey())
+			}
+			for _, holder := range statementHolders(statement) {
+				holders[holder] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return project, nil, err
+	}
+
+	if project.SourceFiles > 0 {
+		project.HeaderCoverage = float64(project.FilesWithCopyright) / float64(project.SourceFiles)
+	}
+	return project, holders, nil
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates the given files below dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPortfolioStats(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"alpha/LICENSE":     "Permission is hereby granted, free of charge, to any person obtaining a copy\nThe above copyright notice and this permission notice shall be included\n",
+		"alpha/main.go":     "// Copyright 2024 Acme Corp.\npackage main\n",
+		"alpha/util.go":     "package main\n",
+		"beta/src/lib.c":    "/* Copyright (c) 2023 Acme Corp. and Example Inc. */\n",
+		"beta/LICENSE.txt":  "Some homegrown terms\n",
+		"gamma/README.md":   "No header here\n",
+		"not-a-project.txt": "// Copyright 2024 Other Corp.\n",
+	})
+
+	stats, err := NewScanner().PortfolioStats(root)
+	if err != nil {
+		t.Fatalf("PortfolioStats failed: %v", err)
+	}
+
+	if stats.Projects != 3 {
+		t.Errorf("Projects = %d, want 3", stats.Projects)
+	}
+	if stats.ProjectsWithoutLicense != 1 {
+		t.Errorf("ProjectsWithoutLicense = %d, want 1", stats.ProjectsWithoutLicense)
+	}
+	if stats.LicenseDistribution["MIT"] != 1 || stats.LicenseDistribution[unknownLicense] != 1 {
+		t.Errorf("unexpected license distribution: %v", stats.LicenseDistribution)
+	}
+	if len(stats.TopHolders) != 2 || stats.TopHolders[0] != (HolderCount{Holder: "Acme Corp", Projects: 2}) ||
+		stats.TopHolders[1] != (HolderCount{Holder: "Example Inc", Projects: 1}) {
+		t.Errorf("unexpected top holders: %+v", stats.TopHolders)
+	}
+
+	alpha := stats.ProjectDetails[0]
+	if alpha.Name != "alpha" || alpha.SourceFiles != 2 || alpha.FilesWithCopyright != 1 || alpha.HeaderCoverage != 0.5 {
+		t.Errorf("unexpected alpha stats: %+v", alpha)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HolderSummary is a copyright holder with the years its statements cover
+// and the number of statements naming it
+type HolderSummary struct {
+	Holder string `json:"holder"`
+	// FirstYear and LastYear are zero if no statement of the holder has a year
+	FirstYear   int `json:"first_year,omitempty"`
+	LastYear    int `json:"last_year,omitempty"`
+	Occurrences int `json:"occurrences"`
+}
+
+// Summary is a deterministic summary of scanned copyright entries
+type Summary struct {
+	Holders []HolderSummary `json:"holders"`
+}
+
+// SummarizeEntries lists the distinct holders of entries, sorted by name,
+// with their year coverage and occurrence count. Holder variants differing
+// only in case, punctuation or legal suffixes are counted together under
+// their nicest spelling
+func SummarizeEntries(entries []CopyrightEntry) Summary {
+	holders := make(map[string]*HolderSummary)
+	for _, entry := range entries {
+		key := normalizeForComparison(entry.Holder)
+		if key == "" {
+			continue
+		}
+
+		holder, ok := holders[key]
+		if !ok {
+			holder = &HolderSummary{Holder: entry.Holder}
+			holders[key] = holder
+		} else if nicerStatement(entry.Holder, holder.Holder) {
+			holder.Holder = entry.Holder
+		}
+		holder.Occurrences++
+
+		for _, year := range entry.Years {
+			if holder.FirstYear == 0 || year < holder.FirstYear {
+				holder.FirstYear = year
+			}
+			if year > holder.LastYear {
+				holder.LastYear = year
+			}
+		}
+	}
+
+	summary := Summary{Holders: make([]HolderSummary, 0, len(holders))}
+	for _, holder := range holders {
+		summary.Holders = append(summary.Holders, *holder)
+	}
+	sort.Slice(summary.Holders, func(i, j int) bool {
+		a, b := summary.Holders[i].Holder, summary.Holders[j].Holder
+		if !strings.EqualFold(a, b) {
+			return strings.ToLower(a) < strings.ToLower(b)
+		}
+		return a < b
+	})
+	return summary
+}
+
+// String formats the summary as one line per holder, such as
+// "Acme Corp.: 2019-2024 (3 statements)"
+func (s Summary) String() string {
+	var result strings.Builder
+	for _, holder := range s.Holders {
+		years := "no year"
+		switch {
+		case holder.FirstYear == 0:
+		case holder.FirstYear == holder.LastYear:
+			years = fmt.Sprint(holder.FirstYear)
+		default:
+			years = fmt.Sprintf("%d-%d", holder.FirstYear, holder.LastYear)
+		}
+
+		statements := "statements"
+		if holder.Occurrences == 1 {
+			statements = "statement"
+		}
+		fmt.Fprintf(&result, "%s: %s (%d %s)\n", holder.Holder, years, holder.Occurrences, statements)
+	}
+	return result.String()
+}
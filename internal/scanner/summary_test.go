@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeEntries(t *testing.T) {
+	entries := []CopyrightEntry{
+		{Holder: "Example Inc", Years: []int{2021}},
+		{Holder: "acme corp", Years: []int{2020, 2022}},
+		{Holder: "Jane Doe"},
+		{Holder: "Acme Corp.", Years: []int{2018}},
+		{Holder: "Example Inc.", Years: []int{2021}},
+		{Holder: ""},
+	}
+
+	got := SummarizeEntries(entries)
+	want := Summary{Holders: []HolderSummary{
+		{Holder: "Acme Corp.", FirstYear: 2018, LastYear: 2022, Occurrences: 2},
+		{Holder: "Example Inc.", FirstYear: 2021, LastYear: 2021, Occurrences: 2},
+		{Holder: "Jane Doe", Occurrences: 1},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SummarizeEntries() = %+v, want %+v", got, want)
+	}
+
+	wantText := "Acme Corp.: 2018-2022 (2 statements)\nExample Inc.: 2021 (2 statements)\nJane Doe: no year (1 statement)\n"
+	if text := got.String(); text != wantText {
+		t.Errorf("String() = %q, want %q", text, wantText)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	wantJSON := `{"holders":[{"holder":"Acme Corp.","first_year":2018,"last_year":2022,"occurrences":2},` +
+		`{"holder":"Example Inc.","first_year":2021,"last_year":2021,"occurrences":2},{"holder":"Jane Doe","occurrences":1}]}`
+	if string(data) != wantJSON {
+		t.Errorf("json.Marshal() = %s, want %s", data, wantJSON)
+	}
+}
+
+func TestSummarizeEntriesEmpty(t *testing.T) {
+	data, err := json.Marshal(SummarizeEntries(nil))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"holders":[]}` {
+		t.Errorf("json.Marshal() = %s, want an empty holder list", data)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanDirectorySymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":        "// Copyright 2024 Acme Corp.\n",
+		"pkg/lib.go":     "// Copyright 2024 Library Inc.\n",
+		"pkg/sub/sub.go": "// Copyright 2024 Nested Inc.\n",
+	})
+	writeFiles(t, outside, map[string]string{
+		"file.go":      "// Copyright 2024 Linked File Inc.\n",
+		"dir/other.go": "// Copyright 2024 Linked Dir Inc.\n",
+	})
+
+	links := map[string]string{
+		"file_link.go":    filepath.Join(outside, "file.go"),
+		"linked":          filepath.Join(outside, "dir"),
+		"pkg/sub/parent":  filepath.Join(root, "pkg"),
+		"pkg/sub/self":    ".",
+		"dangling_link.c": filepath.Join(outside, "missing.c"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		follow bool
+		want   []string
+	}{
+		{"skip directories", false, []string{"Linked File Inc", "Acme Corp", "Library Inc", "Nested Inc"}},
+		{"follow with loops", true, []string{"Linked File Inc", "Linked Dir Inc", "Acme Corp", "Library Inc", "Nested Inc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner()
+			s.FollowSymlinks = tt.follow
+			entries, err := s.ScanDirectoryStructured(root)
+			if err != nil {
+				t.Fatalf("ScanDirectoryStructured failed: %v", err)
+			}
+			var holders []string
+			for _, entry := range entries {
+				holders = append(holders, entry.Holder)
+			}
+			if !reflect.DeepEqual(holders, tt.want) {
+				t.Errorf("holders = %q, want %q", holders, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateTokenPattern matches a placeholder like {name}
+var templateTokenPattern = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// renderTemplate replaces the placeholders of s with their values in vars.
+// Placeholders without a value are left as they are
+func renderTemplate(s string, vars map[string]string) string {
+	return templateTokenPattern.ReplaceAllStringFunc(s, func(token string) string {
+		if value, ok := vars[token[1:len(token)-1]]; ok {
+			return value
+		}
+		return token
+	})
+}
+
+// reportVars returns the placeholder values of a project report: {name},
+// {date} (today as YYYY-MM-DD), {root} (the base name of the scanned root
+// directory) and, unless count is negative, {count}, the number of
+// distinct copyright statements found
+func reportVars(name, root string, count int) map[string]string {
+	vars := map[string]string{
+		"name": name,
+		"date": time.Now().Format("2006-01-02"),
+	}
+	if root != "" {
+		vars["root"] = filepath.Base(root)
+	}
+	if count >= 0 {
+		vars["count"] = strconv.Itoa(count)
+	}
+	return vars
+}
+
+// namedPattern inserts {name} between the file name and extension of an
+// output pattern that lacks it, so each subdirectory gets its own file
+func namedPattern(outputPattern string) string {
+	if strings.Contains(outputPattern, "{name}") {
+		return outputPattern
+	}
+	ext := filepath.Ext(outputPattern)
+	base := strings.TrimSuffix(strings.TrimSuffix(outputPattern, ext), "_")
+	return base + "_{name}" + ext
+}
+
+// countCopyrights returns the number of distinct statements among entries,
+// as listed in a text report
+func countCopyrights(entries []CopyrightEntry) int {
+	type entryKey struct {
+		line       string
+		thirdParty bool
+	}
+	seen := make(map[entryKey]bool)
+	for _, entry := range entries {
+		seen[entryKey{entry.dedupKey(), entry.ThirdParty}] = true
+	}
+	return len(seen)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	vars := map[string]string{"name": "demo", "count": "3"}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"copyright_{name}.txt", "copyright_demo.txt"},
+		{"{name}: {count} statements", "demo: 3 statements"},
+		{"{unknown} and {NAME} stay", "{unknown} and {NAME} stay"},
+		{"no placeholders", "no placeholders"},
+	}
+
+	for _, tt := range tests {
+		if got := renderTemplate(tt.input, vars); got != tt.want {
+			t.Errorf("renderTemplate(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestOutputFileName(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"copyright_{name}.txt", "copyright_demo.txt"},
+		{"copyright.txt", "copyright_demo.txt"},
+		{"copyright_.txt", "copyright_demo.txt"},
+		{"{name}-{date}.txt", "demo-" + today + ".txt"},
+	}
+
+	for _, tt := range tests {
+		if got := OutputFileName(tt.pattern, "demo"); got != tt.want {
+			t.Errorf("OutputFileName(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestScanSubDirectoriesPlaceholders(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "projects")
+	writeFiles(t, root, map[string]string{
+		"alpha/a.go": "// Copyright 2021 Acme Corp.\n\n// Copyright 2022 Example Inc.\n",
+	})
+	template := filepath.Join(t.TempDir(), "prefix.txt")
+	if err := os.WriteFile(template, []byte("Notice for {name} in {root}, {count} statements {unknown}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := t.TempDir()
+	s := &Scanner{TemplatePath: template}
+	if err := s.ScanSubDirectories(root, filepath.Join(outDir, "{root}_{name}_{count}.txt")); err != nil {
+		t.Fatalf("ScanSubDirectories failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outDir, "projects_alpha_2.txt"))
+	if err != nil {
+		t.Fatalf("expected the rendered output file name: %v", err)
+	}
+	want := "Notice for alpha in projects, 2 statements {unknown}\n"
+	if !strings.HasPrefix(string(got), want) {
+		t.Errorf("expected the rendered prefix %q, got:\n%s", want, got)
+	}
+}
@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2024 The Test Authors
+
+/*
+ * License: Apache-2.0
+ * Copyright 2023 Acme Corp.
+ */
+
+int main(void) { return 0; }
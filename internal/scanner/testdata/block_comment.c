@@ -0,0 +1,18 @@
+/*
+ * Copyright (c) 2025
+ * Acme Corp
+ */
+#include <stdio.h>
+
+/*
+ * Copyright 2024 Example Inc.
+ *
+ * This file implements the parser.
+ */
+
+/* Copyright 2023 Jane Doe */
+static int x;
+
+// Copyright 2022 Line Comment Ltd.
+/* Unrelated block */
+static int y;
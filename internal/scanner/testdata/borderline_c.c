@@ -0,0 +1,9 @@
+/* Copyright Acme Widgets GmbH */
+
+/* Copyright Jane Doe. All rights reserved. */
+
+/* Copyright 2021 Example Project */
+
+/* see note (c) above */
+
+/* Rule (C) applies to every entry */
@@ -0,0 +1,7 @@
+/*
+ * Ｃｏｐｙｒｉｇｈｔ　（Ｃ）　２０２４　株式会社サンプル
+ */
+
+// 著作権表示 （ｃ） 2023 示例有限公司
+
+int main(void) { return 0; }
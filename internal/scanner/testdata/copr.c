@@ -0,0 +1,7 @@
+/*
+ * Copr. 2001 Acme Widgets Inc.
+ */
+
+/* (Copr) 1998 Legacy Systems Ltd. */
+
+/* coprocessor support */
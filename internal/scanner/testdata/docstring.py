@@ -0,0 +1,15 @@
+#!/usr/bin/env python3
+"""Command line tools for the Acme project.
+
+Copyright (c) 2019-2024 Acme Corp.
+All rights reserved.
+"""
+
+'''Copyright 2023 Jane Doe'''
+
+import os
+
+
+def main():
+    r"""Print the working directory."""
+    print(os.getcwd())
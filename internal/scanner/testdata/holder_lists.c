@@ -0,0 +1,10 @@
+/*
+ * Copyright 2024 Alice Liddell, Bob Marley and Carol King
+ * Copyright (c) 2020 Smith, Johnson & Co
+ * Copyright 2019 Free Software Foundation, Inc. and Jane Doe
+ * Copyright 2018 Procter & Gamble
+ * Copyright 2021 Acme Corp. and its affiliates
+ * Copyright 2022 Dewey, Cheatem, and Howe
+ */
+
+int main(void) { return 0; }
@@ -0,0 +1,5 @@
+/*
+ * Copyright 2019 Jos Garca
+ * All rights reserved.
+ */
+int main(void) { return 0; }
@@ -0,0 +1,5 @@
+#!/bin/sh -e
+# Copyright 2022 Shell Tools Inc.
+# SPDX-License-Identifier: MIT
+
+echo "building"
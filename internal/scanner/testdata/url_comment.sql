@@ -0,0 +1,4 @@
+-- Schema for the Acme billing service
+-- Copyright 2024 Acme Corp. https://acme.example/legal
+
+CREATE TABLE invoices (id INTEGER PRIMARY KEY);
@@ -0,0 +1,2 @@
+﻿// Copyright 2020 BOM Prefixed Inc.
+int main(void) { return 0; }
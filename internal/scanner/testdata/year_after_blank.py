@@ -0,0 +1,6 @@
+# Copyright
+# The Acme Project
+#
+# 2019-2024
+
+print("hello")
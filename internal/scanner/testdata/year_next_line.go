@@ -0,0 +1,7 @@
+/*
+ * Copyright
+ * The Acme Project
+ * 2024
+ */
+
+package acme
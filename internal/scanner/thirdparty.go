@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// IsThirdParty checks if a path lies in one of the ThirdPartyDirs
+func (s *Scanner) IsThirdParty(path string) bool {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	// The last segment is the file itself
+	dirs := segments[:len(segments)-1]
+
+	for _, thirdPartyDir := range s.ThirdPartyDirs {
+		pattern := strings.Split(strings.Trim(filepath.ToSlash(filepath.Clean(thirdPartyDir)), "/"), "/")
+		if pattern[0] == "" || pattern[0] == "." {
+			continue
+		}
+		for i := 0; i+len(pattern) <= len(dirs); i++ {
+			if equalSegments(dirs[i:i+len(pattern)], pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equalSegments checks if two path segment lists are equal
+func equalSegments(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// thirdPartySection wraps third-party copyright lines in an output section
+func thirdPartySection(lines string) string {
+	if lines == "" {
+		return ""
+	}
+	return "\nThird-Party Copyrights:\n----------------------------------------\n\n" + lines
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"testing"
+)
+
+func TestIsThirdParty(t *testing.T) {
+	s := NewScanner()
+	s.ThirdPartyDirs = []string{"vendor", "third_party/", "libs/external"}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"vendor/github.com/acme/lib.go", true},
+		{"src/third_party/zlib/inflate.c", true},
+		{"libs/external/json.hpp", true},
+		{"libs/internal/json.hpp", false},
+		{"vendored/lib.go", false},
+		{"vendor", false},
+		{"main.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := s.IsThirdParty(tt.path); got != tt.want {
+			t.Errorf("IsThirdParty(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestScanDirectoryThirdPartySection(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go":                "// Copyright 2024 Acme Corp.\n",
+		"vendor/lib/lib.go":      "// Copyright 2020 Example Inc.\n",
+		"vendor/lib/acme_fix.go": "// Copyright 2024 Acme Corp.\n",
+	})
+
+	s := NewScanner()
+	s.ThirdPartyDirs = []string{"vendor"}
+	got, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	want := "Copyright 2024 Acme Corp.\n\nThird-Party Copyrights:\n----------------------------------------\n\nCopyright 2024 Acme Corp.\nCopyright 2020 Example Inc.\n"
+	if got != want {
+		t.Errorf("ScanDirectory() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,331 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minPlausibleYear is the earliest year accepted as a real copyright year
+const minPlausibleYear = 1970
+
+// yearTokenPattern matches standalone 4-digit numbers in a statement
+var yearTokenPattern = regexp.MustCompile(`\b[0-9]{4}\b`)
+
+// yearOnlyPattern matches a line holding nothing but a year, year range or year list
+var yearOnlyPattern = regexp.MustCompile(`^[0-9]{4}((\s*([-‐‑‒–—―,&]|and)\s*|\s+)([0-9]{4}|[0-9]{2}))*\.?$`)
+
+// maxYearRangeSpan is the longest span accepted as a year range, longer ones are read as two separate years
+const maxYearRangeSpan = 100
+
+// yearRangePattern matches a year, optionally followed by a dash and the end of a
+// range. Any hyphen or dash variant is accepted and the end may be abbreviated to two digits
+var yearRangePattern = regexp.MustCompile(`\b([0-9]{4})(?:\s*[-‐‑‒–—―]\s*([0-9]{4}|[0-9]{2}))?\b`)
+
+// shortYearListPattern matches the year list directly after the copyright
+// markers of a statement, where two-digit years like "98, 99" are read as years
+var shortYearListPattern = regexp.MustCompile(`(?i)^\s*(?:(?:copyright|\(copr\)|copr\b\.?|\(c\)|©)\s*)+((?:'?[0-9]{2}(?:[0-9]{2})?\b(?:\s*(?:[-‐‑‒–—―,&]|and\b)\s*|\s+)?)+)`)
+
+// shortYearTokenPattern matches the numbers of a year list, two-digit ones
+// with an optional apostrophe
+var shortYearTokenPattern = regexp.MustCompile(`'?\b[0-9]{2}(?:[0-9]{2})?\b`)
+
+// apostropheYearPattern matches an apostrophe year like '99 anywhere in a statement
+var apostropheYearPattern = regexp.MustCompile(`(^|[\s,(\-‐‑‒–—―])'([0-9]{2})\b`)
+
+// expandYear expands a two-digit year to the first matching year not before
+// minPlausibleYear, so 98 is 1998 and 03 is 2003. ok is false if the result
+// lies in the future, as then the number is unlikely to be a year
+func expandYear(twoDigits string) (string, bool) {
+	year, err := strconv.Atoi(twoDigits)
+	if err != nil {
+		return "", false
+	}
+	year += 1900
+	if year < minPlausibleYear {
+		year += 100
+	}
+	return strconv.Itoa(year), year <= time.Now().Year()
+}
+
+// expandShortYears rewrites the two-digit years of a statement as four-digit
+// years: apostrophe forms like '99 anywhere, and bare ones in the year list
+// after the copyright markers, so "Copyright (c) 98-03" reads as 1998-2003
+func expandShortYears(statement string) string {
+	statement = apostropheYearPattern.ReplaceAllStringFunc(statement, func(match string) string {
+		sub := apostropheYearPattern.FindStringSubmatch(match)
+		if year, ok := expandYear(sub[2]); ok {
+			return sub[1] + year
+		}
+		return match
+	})
+
+	loc := shortYearListPattern.FindStringSubmatchIndex(statement)
+	if loc == nil {
+		return statement
+	}
+	list := shortYearTokenPattern.ReplaceAllStringFunc(statement[loc[2]:loc[3]], func(token string) string {
+		digits := strings.TrimPrefix(token, "'")
+		if len(digits) != 2 {
+			return token
+		}
+		if year, ok := expandYear(digits); ok {
+			return year
+		}
+		return token
+	})
+	return statement[:loc[2]] + list + statement[loc[3]:]
+}
+
+// ParseCopyrightYears returns the sorted set of years covered by the year
+// expressions of a statement. Lists may mix commas, "and", "&" and ranges,
+// so "2018, 2019 and 2021-2023" covers 2018, 2019, 2021, 2022 and 2023.
+// Two-digit years are accepted in the apostrophe form '99 and in the year
+// list following the copyright markers, e.g. "Copyright (c) 98, 99"
+func ParseCopyrightYears(statement string) []int {
+	statement = expandShortYears(statement)
+	seen := make(map[int]bool)
+	for _, match := range yearRangePattern.FindAllStringSubmatch(statement, -1) {
+		start, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		seen[start] = true
+		if match[2] == "" {
+			continue
+		}
+
+		end, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		// An abbreviated end like 2021-23 shares the century of the start
+		if len(match[2]) == 2 {
+			end += start - start%100
+		}
+
+		if end < start || end-start > maxYearRangeSpan {
+			// Not a plausible range, keep a complete end year on its own
+			if len(match[2]) == 4 {
+				seen[end] = true
+			}
+			continue
+		}
+		for year := start + 1; year <= end; year++ {
+			seen[year] = true
+		}
+	}
+
+	years := make([]int, 0, len(seen))
+	for year := range seen {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	return years
+}
+
+// implausibleYears splits off the years after the current year and those
+// before minPlausibleYear, each in the order given
+func implausibleYears(years []int) (future, old []int) {
+	currentYear := time.Now().Year()
+	for _, year := range years {
+		switch {
+		case year > currentYear:
+			future = append(future, year)
+		case year < minPlausibleYear:
+			old = append(old, year)
+		}
+	}
+	return future, old
+}
+
+// ValidateCopyrightYears checks the years parsed from a statement, as by
+// ParseCopyrightYears, and describes those after the current year or before
+// minPlausibleYear, which usually indicate a typo or a template bug. It
+// returns nil if all years are plausible
+func ValidateCopyrightYears(years []int) []string {
+	future, old := implausibleYears(years)
+	var warnings []string
+	if len(future) > 0 {
+		warnings = append(warnings, fmt.Sprintf("future year %s", formatYearRanges(future)))
+	}
+	if len(old) > 0 {
+		warnings = append(warnings, fmt.Sprintf("year %s before %d", formatYearRanges(old), minPlausibleYear))
+	}
+	return warnings
+}
+
+// MissingYear checks if a copyright statement names no year at all
+func MissingYear(statement string) bool {
+	return len(ParseCopyrightYears(statement)) == 0
+}
+
+// yearWarnings builds a warnings section listing statements with suspicious
+// years, read as by ParseCopyrightYears and judged as by
+// ValidateCopyrightYears, and, if missing is set, statements without any year
+func yearWarnings(copyrightText string, suspicious, missing bool) string {
+	var warnings strings.Builder
+	for _, line := range strings.Split(copyrightText, "\n") {
+		if missing && copyrightMarkerPattern.MatchString(line) && MissingYear(line) {
+			warnings.WriteString(fmt.Sprintf("Missing year: %s\n", line))
+			continue
+		}
+		// Section headers such as the year groups aren't statements
+		if !suspicious || strings.HasSuffix(line, ":") {
+			continue
+		}
+
+		// The years are sorted, so the old ones come first
+		future, old := implausibleYears(ParseCopyrightYears(line))
+		if len(future) == 0 && len(old) == 0 {
+			continue
+		}
+		warnings.WriteString(fmt.Sprintf("Suspicious year %s: %s\n", formatYearRanges(append(old, future...)), line))
+	}
+
+	if warnings.Len() == 0 {
+		return ""
+	}
+	return "\nWarnings:\n----------------------------------------\n\n" + warnings.String()
+}
+
+// groupByYear regroups copyright lines into one section per year. Each line is
+// listed under the earliest year claimed by its holder in any line, so a
+// holder appears in the year it was first claimed. Lines without a year follow
+// in a final section
+func groupByYear(copyrightText string) string {
+	lines := strings.Split(strings.TrimSuffix(copyrightText, "\n"), "\n")
+	if copyrightText == "" {
+		return ""
+	}
+
+	// Find the earliest year of every holder
+	earliest := make(map[string]int)
+	holderKeys := make([]string, len(lines))
+	for i, line := range lines {
+		_, holder, _ := splitHolder(line)
+		holderKeys[i] = normalizeForComparison(holder)
+		if years := ParseCopyrightYears(line); len(years) > 0 {
+			if year, ok := earliest[holderKeys[i]]; !ok || years[0] < year {
+				earliest[holderKeys[i]] = years[0]
+			}
+		}
+	}
+
+	buckets := make(map[int][]string)
+	var yearless []string
+	for i, line := range lines {
+		year, ok := earliest[holderKeys[i]]
+		if !ok {
+			yearless = append(yearless, line)
+			continue
+		}
+		buckets[year] = append(buckets[year], line)
+	}
+
+	years := make([]int, 0, len(buckets))
+	for year := range buckets {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	var result strings.Builder
+	for _, year := range years {
+		result.WriteString(fmt.Sprintf("\n%d:\n----------------------------------------\n\n", year))
+		result.WriteString(strings.Join(buckets[year], "\n") + "\n")
+	}
+	if len(yearless) > 0 {
+		result.WriteString("\nNo year:\n----------------------------------------\n\n")
+		result.WriteString(strings.Join(yearless, "\n") + "\n")
+	}
+	return result.String()
+}
+
+// yearListPattern matches the first year expression of a statement, such as
+// "2019", "2018-2020" or "2015, 2017 and 2019"
+var yearListPattern = regexp.MustCompile(`\b[0-9]{4}(?:\s*(?:[-‐‑‒–—―,&]|and\b)\s*(?:[0-9]{4}|[0-9]{2})\b)*`)
+
+// formatYearRanges formats sorted years as a list, with consecutive years
+// merged into ranges, so 2015, 2017, 2018 and 2019 become "2015, 2017-2019"
+func formatYearRanges(years []int) string {
+	var parts []string
+	for i := 0; i < len(years); {
+		j := i
+		for j+1 < len(years) && years[j+1] == years[j]+1 {
+			j++
+		}
+		if j == i {
+			parts = append(parts, strconv.Itoa(years[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", years[i], years[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mergeYears merges copyright lines that differ only in their years into one
+// line listing all of their years as ranges. The merged line takes the place
+// of the first line with a year expression, which keeps its wording; lines
+// without a year are dropped once another line of the holder has one
+func mergeYears(copyrightText string) string {
+	if copyrightText == "" {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSuffix(copyrightText, "\n"), "\n")
+
+	// Collect the years of each statement, ignoring its years and email addresses
+	type yearGroup struct {
+		template int
+		years    map[int]bool
+	}
+	groups := make(map[string]*yearGroup)
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		keys[i] = normalizeForComparison(withoutEmails(line))
+		group, ok := groups[keys[i]]
+		if !ok {
+			group = &yearGroup{template: -1, years: make(map[int]bool)}
+			groups[keys[i]] = group
+		}
+		if group.template == -1 && yearListPattern.MatchString(line) {
+			group.template = i
+		}
+		for _, year := range ParseCopyrightYears(line) {
+			group.years[year] = true
+		}
+	}
+
+	var result strings.Builder
+	written := make(map[string]bool)
+	for i, line := range lines {
+		group := groups[keys[i]]
+		switch {
+		case group.template == -1:
+			// Without a year expression the lines are only deduplicated
+			if written[line] {
+				continue
+			}
+			written[line] = true
+		case i != group.template:
+			continue
+		default:
+			years := make([]int, 0, len(group.years))
+			for year := range group.years {
+				years = append(years, year)
+			}
+			sort.Ints(years)
+			loc := yearListPattern.FindStringIndex(line)
+			line = line[:loc[0]] + formatYearRanges(years) + line[loc[1]:]
+		}
+		result.WriteString(line + "\n")
+	}
+	return result.String()
+}
@@ -0,0 +1,299 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYearWarnings(t *testing.T) {
+	nextYear := strconv.Itoa(time.Now().Year() + 1)
+	text := "Copyright 2204 Acme Corp.\nCopyright 2019-2023 Example Inc.\nCopyright 1899, " + nextYear + " Old Corp.\n"
+
+	got := yearWarnings(text, true, false)
+	if !strings.HasPrefix(got, "\nWarnings:\n") {
+		t.Fatalf("missing warnings header: %q", got)
+	}
+	if !strings.Contains(got, "Suspicious year 2204: Copyright 2204 Acme Corp.\n") {
+		t.Errorf("future year not flagged: %q", got)
+	}
+	if !strings.Contains(got, "Suspicious year 1899, "+nextYear+": ") {
+		t.Errorf("old and next year not flagged: %q", got)
+	}
+	if strings.Contains(got, "Example Inc.") {
+		t.Errorf("plausible years flagged: %q", got)
+	}
+
+	// Years are read like the structured entries do, abbreviated range ends included
+	abbreviated := "Copyright 2020-" + nextYear[2:] + " Short Corp."
+	if got := yearWarnings(abbreviated+"\n", true, false); got != "\nWarnings:\n----------------------------------------\n\nSuspicious year "+nextYear+": "+abbreviated+"\n" {
+		t.Errorf("abbreviated range end not flagged: %q", got)
+	}
+	if !reflect.DeepEqual(ValidateCopyrightYears(ParseCopyrightYears(abbreviated)), []string{"future year " + nextYear}) {
+		t.Errorf("ValidateCopyrightYears(%q) disagrees with the warnings section", abbreviated)
+	}
+
+	if got := yearWarnings("Copyright 2020 Acme Corp.\nCopyright Acme Corp.\n", true, false); got != "" {
+		t.Errorf("expected no warnings, got %q", got)
+	}
+}
+
+func TestValidateCopyrightYears(t *testing.T) {
+	currentYear := time.Now().Year()
+
+	tests := []struct {
+		name  string
+		years []int
+		want  []string
+	}{
+		{"plausible", []int{1970, 2019, currentYear}, nil},
+		{"none", nil, nil},
+		{"future", []int{2019, 2205}, []string{"future year 2205"}},
+		{"next year", []int{currentYear, currentYear + 1}, []string{"future year " + strconv.Itoa(currentYear+1)}},
+		{"old range", ParseCopyrightYears("Copyright 1965-1969, 1899"), []string{"year 1899, 1965-1969 before 1970"}},
+		{"both", []int{1899, 2020, 2205}, []string{"future year 2205", "year 1899 before 1970"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateCopyrightYears(tt.years); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateCopyrightYears(%v) = %q, want %q", tt.years, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryWarnings(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.c": "// Copyright 2205 Acme Corp.\n",
+		"b.c": "// Copyright 2019 Example Inc.\n",
+	})
+
+	s := NewScanner()
+	entries, err := s.ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectoryStructured failed: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Warnings != nil || entry.SuspiciousYear {
+			t.Errorf("expected no warnings without ValidateYears, got %q", entry.Warnings)
+		}
+	}
+
+	s.ValidateYears = true
+	entries, err = s.ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectoryStructured failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if !reflect.DeepEqual(entries[0].Warnings, []string{"future year 2205"}) || entries[1].Warnings != nil {
+		t.Errorf("unexpected warnings: %q, %q", entries[0].Warnings, entries[1].Warnings)
+	}
+	if !entries[0].SuspiciousYear || entries[1].SuspiciousYear {
+		t.Errorf("SuspiciousYear = %v, %v, want true, false", entries[0].SuspiciousYear, entries[1].SuspiciousYear)
+	}
+
+	data, err := marshalJSONReport(NewJSONReport("acme", entries), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(data), `"suspiciousYear":true`) != 1 {
+		t.Errorf("expected one suspiciousYear in JSON report: %s", data)
+	}
+}
+
+func TestParseCopyrightYears(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "year_expressions.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		statement, expected, found := strings.Cut(line, "=>")
+		if !found {
+			t.Fatalf("malformed fixture line: %q", line)
+		}
+
+		var want []int
+		for _, field := range strings.Fields(expected) {
+			year, err := strconv.Atoi(field)
+			if err != nil {
+				t.Fatalf("malformed fixture year %q: %v", field, err)
+			}
+			want = append(want, year)
+		}
+
+		got := ParseCopyrightYears(statement)
+		if !reflect.DeepEqual(got, want) && !(len(got) == 0 && len(want) == 0) {
+			t.Errorf("ParseCopyrightYears(%q) = %v, want %v", statement, got, want)
+		}
+	}
+}
+
+func TestMissingYearWarnings(t *testing.T) {
+	text := "Copyright 2020 Acme Corp.\nCopyright Acme Corporation\n(c) Jane Doe\n\nThird-Party Copyrights:\nCopyright 2204 Example Inc.\n"
+
+	got := yearWarnings(text, false, true)
+	want := "\nWarnings:\n----------------------------------------\n\nMissing year: Copyright Acme Corporation\nMissing year: (c) Jane Doe\n"
+	if got != want {
+		t.Errorf("yearWarnings() = %q, want %q", got, want)
+	}
+
+	got = yearWarnings(text, true, true)
+	if !strings.Contains(got, "Missing year: Copyright Acme Corporation\n") || !strings.Contains(got, "Suspicious year 2204: ") {
+		t.Errorf("expected both kinds of warnings, got %q", got)
+	}
+}
+
+func TestEntryMissingYear(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.c": "// Copyright Acme Corp.\n",
+		"b.c": "// Copyright 2019 Example Inc.\n",
+	})
+
+	s := NewScanner()
+	entries, err := s.ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectoryStructured failed: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.MissingYear {
+			t.Errorf("expected no MissingYear without FlagMissingYears: %q", entry.RawText)
+		}
+	}
+
+	s.FlagMissingYears = true
+	entries, err = s.ScanDirectoryStructured(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectoryStructured failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if !entries[0].MissingYear || entries[1].MissingYear {
+		t.Errorf("MissingYear = %v, %v, want true, false", entries[0].MissingYear, entries[1].MissingYear)
+	}
+
+	data, err := marshalJSONReport(NewJSONReport("acme", entries), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(data), `"missingYear":true`) != 1 {
+		t.Errorf("expected one missingYear in JSON report: %s", data)
+	}
+}
+
+func TestGroupByYear(t *testing.T) {
+	text := "Copyright 2021-2023 Acme Corp.\nCopyright 2019 Example Inc.\nCopyright 2018, 2024 Acme Corp.\nCopyright Example Inc.\nCopyright Jane Doe\n"
+
+	want := "\n2018:\n----------------------------------------\n\n" +
+		"Copyright 2021-2023 Acme Corp.\nCopyright 2018, 2024 Acme Corp.\n" +
+		"\n2019:\n----------------------------------------\n\n" +
+		"Copyright 2019 Example Inc.\nCopyright Example Inc.\n" +
+		"\nNo year:\n----------------------------------------\n\n" +
+		"Copyright Jane Doe\n"
+	if got := groupByYear(text); got != want {
+		t.Errorf("groupByYear() = %q, want %q", got, want)
+	}
+
+	if got := groupByYear(""); got != "" {
+		t.Errorf("expected no sections for empty input, got %q", got)
+	}
+}
+
+func TestFormatYearRanges(t *testing.T) {
+	tests := []struct {
+		years []int
+		want  string
+	}{
+		{nil, ""},
+		{[]int{2019}, "2019"},
+		{[]int{2019, 2020}, "2019-2020"},
+		{[]int{2015, 2017, 2018, 2019}, "2015, 2017-2019"},
+		{[]int{2010, 2012, 2014}, "2010, 2012, 2014"},
+	}
+
+	for _, tt := range tests {
+		if got := formatYearRanges(tt.years); got != tt.want {
+			t.Errorf("formatYearRanges(%v) = %q, want %q", tt.years, got, tt.want)
+		}
+	}
+}
+
+func TestMergeYears(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "consecutive years",
+			text: "Copyright 2019 Acme Corp.\nCopyright 2020 Acme Corp.\n",
+			want: "Copyright 2019-2020 Acme Corp.\n",
+		},
+		{
+			name: "lists and ranges",
+			text: "Copyright (c) 2015, 2017 Acme Corp.\nCopyright 2019 Example Inc.\nCopyright (c) 2018-2019 Acme Corp.\n",
+			want: "Copyright (c) 2015, 2017-2019 Acme Corp.\nCopyright 2019 Example Inc.\n",
+		},
+		{
+			name: "yearless statement",
+			text: "Copyright Acme Corp.\nCopyright 2021 Acme Corp.\nCopyright Jane Doe\nCopyright Jane Doe\n",
+			want: "Copyright 2021 Acme Corp.\nCopyright Jane Doe\n",
+		},
+		{
+			name: "empty",
+			text: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeYears(tt.text); got != tt.want {
+				t.Errorf("mergeYears() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanDirectoryMergeYears(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go": "// Copyright 2019 Acme Corp.\n\n// Copyright 2020 Acme Corp.\n",
+		"b.go": "// Copyright 2021 Acme Corp.\n",
+	})
+
+	s := NewScanner()
+	verbatim, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if !strings.Contains(verbatim, "Copyright 2019 Acme Corp.\nCopyright 2021 Acme Corp.\n") {
+		t.Errorf("expected the statements as written:\n%s", verbatim)
+	}
+
+	s.MergeYears = true
+	merged, err := s.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if !strings.Contains(merged, "Copyright 2019-2021 Acme Corp.\n") || strings.Contains(merged, "Copyright 2019 Acme") {
+		t.Errorf("expected the years merged into a range:\n%s", merged)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+// Package version reports the version of the Nemesis binaries
+package version
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Build information, set at link time with
+//
+//	go build -ldflags "-X github.com/li-clement/Nemesis/internal/version.Version=v1.2.0 \
+//	  -X github.com/li-clement/Nemesis/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/li-clement/Nemesis/internal/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Unset values are taken from the build info embedded by the Go toolchain
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Info describes the build of a binary
+type Info struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+// Get returns the build information, preferring the link-time variables
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info = info.withBuildInfo(build)
+	}
+	if info.Version == "" {
+		info.Version = "devel"
+	}
+	return info
+}
+
+// withBuildInfo fills the fields left empty from the build info, which holds
+// the module version after "go install" and the VCS state after "go build"
+func (i Info) withBuildInfo(build *debug.BuildInfo) Info {
+	if i.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		i.Version = build.Main.Version
+	}
+	settings := make(map[string]string)
+	for _, setting := range build.Settings {
+		settings[setting.Key] = setting.Value
+	}
+	if i.Commit == "" && settings["vcs.revision"] != "" {
+		i.Commit = settings["vcs.revision"]
+		if settings["vcs.modified"] == "true" {
+			i.Commit += "-dirty"
+		}
+	}
+	if i.Date == "" {
+		i.Date = settings["vcs.time"]
+	}
+	return i
+}
+
+// Describe formats the information for the -version flag of the binary name
+func (i Info) Describe(name string) string {
+	text := fmt.Sprintf("%s %s", name, i.Version)
+	if i.Commit != "" {
+		text += fmt.Sprintf(", commit %s", i.Commit)
+	}
+	if i.Date != "" {
+		text += fmt.Sprintf(", built %s", i.Date)
+	}
+	return text
+}
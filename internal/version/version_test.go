@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2025 Clement Li. All rights reserved.
+ */
+
+package version
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestWithBuildInfo(t *testing.T) {
+	build := &debug.BuildInfo{
+		Main: debug.Module{Version: "v1.2.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "2025-03-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		info Info
+		want Info
+	}{
+		{"from build info", Info{}, Info{Version: "v1.2.0", Commit: "abc123-dirty", Date: "2025-03-01T10:00:00Z"}},
+		{"ldflags take precedence", Info{Version: "v2.0.0", Commit: "def456", Date: "2025-04-01"}, Info{Version: "v2.0.0", Commit: "def456", Date: "2025-04-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.withBuildInfo(build); got != tt.want {
+				t.Errorf("withBuildInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	devel := &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}
+	if got := (Info{}).withBuildInfo(devel); got.Version != "" {
+		t.Errorf("withBuildInfo() took the (devel) version: %+v", got)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		info Info
+		want string
+	}{
+		{Info{Version: "v1.2.0", Commit: "abc123", Date: "2025-03-01"}, "scanner v1.2.0, commit abc123, built 2025-03-01"},
+		{Info{Version: "devel"}, "scanner devel"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.info.Describe("scanner"); got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}